@@ -1,5 +1,7 @@
 package rcs
 
+import "fmt"
+
 // CopyAccessList copies the access list from the source file to the receiver file.
 func (f *File) CopyAccessList(from *File) {
 	f.Access = from.Access
@@ -34,3 +36,48 @@ func (f *File) AppendAccessList(from *File) {
 		}
 	}
 }
+
+// AddAccessUsers adds users to the access list, skipping ones already
+// present. Like GNU RCS, a list changed by AddAccessUsers, RemoveAccessUsers
+// or ClearAccessUsers is written one login per line.
+func (f *File) AddAccessUsers(users ...string) {
+	f.Access = true
+	for _, user := range users {
+		if user == "" || containsString(f.AccessUsers, user) {
+			continue
+		}
+		f.AccessUsers = append(f.AccessUsers, user)
+	}
+	f.AccessOnNewLines = true
+}
+
+// RemoveAccessUsers removes users from the access list. Users not on the list
+// are ignored.
+func (f *File) RemoveAccessUsers(users ...string) {
+	f.Access = true
+	kept := f.AccessUsers[:0:0]
+	for _, user := range f.AccessUsers {
+		if !containsString(users, user) {
+			kept = append(kept, user)
+		}
+	}
+	f.AccessUsers = kept
+	f.AccessOnNewLines = true
+}
+
+// ClearAccessUsers empties the access list, allowing everyone again.
+func (f *File) ClearAccessUsers() {
+	f.Access = true
+	f.AccessUsers = nil
+	f.AccessOnNewLines = true
+}
+
+// CheckAccess reports whether user may modify the file. An empty access list
+// allows everyone; otherwise user must be on it or ErrAccessDenied is
+// returned.
+func (f *File) CheckAccess(user string) error {
+	if len(f.AccessUsers) == 0 || containsString(f.AccessUsers, user) {
+		return nil
+	}
+	return fmt.Errorf("%w: user %s is not on the access list", ErrAccessDenied, user)
+}
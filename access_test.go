@@ -0,0 +1,88 @@
+package rcs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAddRemoveAccessUsers(t *testing.T) {
+	f := NewFile()
+	f.Head = "1.1"
+	f.AddAccessUsers("jules", "martha", "jules")
+	if diff := cmp.Diff([]string{"jules", "martha"}, f.AccessUsers); diff != "" {
+		t.Fatalf("AccessUsers mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(f.String(), "access\n\tjules\n\tmartha;\n") {
+		t.Errorf("String() access list not written one login per line:\n%s", f.String())
+	}
+	f.RemoveAccessUsers("jules", "nobody")
+	if diff := cmp.Diff([]string{"martha"}, f.AccessUsers); diff != "" {
+		t.Fatalf("AccessUsers mismatch (-want +got):\n%s", diff)
+	}
+	f.ClearAccessUsers()
+	if len(f.AccessUsers) != 0 {
+		t.Fatalf("AccessUsers = %v, want empty", f.AccessUsers)
+	}
+	if !strings.Contains(f.String(), "access;\n") {
+		t.Errorf("String() missing empty access list:\n%s", f.String())
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	f := NewFile()
+	if err := f.CheckAccess("anyone"); err != nil {
+		t.Fatalf("CheckAccess() with empty list error = %v", err)
+	}
+	f.AddAccessUsers("jules")
+	if err := f.CheckAccess("jules"); err != nil {
+		t.Fatalf("CheckAccess(jules) error = %v", err)
+	}
+	if err := f.CheckAccess("martha"); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("CheckAccess(martha) error = %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestAccessUsersLayout(t *testing.T) {
+	const gnu = "head\t1.1;\naccess\n\tjules\n\tmartha;\nsymbols;\nlocks; strict;\ncomment\t@# @;\n\n\n1.1\ndate\t2021.03.03.12.00.00;\tauthor jules;\tstate Exp;\nbranches;\nnext\t;\n\n\ndesc\n@@\n\n\n1.1\nlog\n@Initial\n@\ntext\n@hello\n@\n"
+	inline := strings.Replace(gnu, "access\n\tjules\n\tmartha;", "access jules martha;", 1)
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"one login per line", gnu, true},
+		{"inline", inline, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFile(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+			if f.AccessOnNewLines != tt.want {
+				t.Errorf("AccessOnNewLines = %v, want %v", f.AccessOnNewLines, tt.want)
+			}
+			if got := f.String(); got != tt.input {
+				t.Errorf("String() = %q, want %q", got, tt.input)
+			}
+			for _, mutate := range []func(){
+				func() { f.AddAccessUsers("tom") },
+				func() { f.RemoveAccessUsers("tom") },
+				func() { f.ClearAccessUsers() },
+			} {
+				f.AccessOnNewLines = false
+				mutate()
+				if !f.AccessOnNewLines {
+					t.Errorf("AccessOnNewLines = false after changing the list, want true")
+				}
+			}
+			f.AddAccessUsers("jules", "martha")
+			if got := f.String(); got != gnu {
+				t.Errorf("String() after changes = %q, want %q", got, gnu)
+			}
+		})
+	}
+}
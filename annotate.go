@@ -0,0 +1,83 @@
+package rcs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arran4/golang-rcs/diff"
+)
+
+// AnnotatedLine is a line of a revision together with the revision that
+// introduced it.
+type AnnotatedLine struct {
+	Revision string
+	Author   string
+	Date     DateTime
+	Text     string
+	// NoNewline is set on the last line of a revision that does not end
+	// with a newline.
+	NoNewline bool `json:",omitempty"`
+}
+
+// Annotate attributes every line of a revision to the revision that last
+// changed it, like cvs annotate. sel is resolved with ResolveRevision. The
+// history walked is the line of development leading to the revision, so on
+// a branch the trunk revisions before the branch point are included. Lines
+// are compared with their newlines, so a revision that only adds or drops
+// the final newline is credited with the last line.
+func (f *File) Annotate(sel string) ([]AnnotatedLine, error) {
+	rev, err := f.ResolveRevision(sel)
+	if err != nil {
+		return nil, err
+	}
+	heads := make(map[string]*RevisionHead, len(f.RevisionHeads))
+	for _, rh := range f.RevisionHeads {
+		heads[rh.Revision.String()] = rh
+	}
+
+	parents := f.RevisionParents()
+	var chain []string
+	seen := map[string]bool{}
+	for r := rev; r != ""; r = parents[r] {
+		if seen[r] {
+			return nil, fmt.Errorf("loop detected in history of %q", rev)
+		}
+		seen[r] = true
+		chain = append(chain, r)
+	}
+
+	var lines []AnnotatedLine
+	var prev []string
+	for i := len(chain) - 1; i >= 0; i-- {
+		r := chain[i]
+		rh, ok := heads[r]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrRevisionNotFound, r)
+		}
+		content, err := f.resolveRevisionContent(r)
+		if err != nil {
+			return nil, fmt.Errorf("revision %s: %w", r, err)
+		}
+		cur := diff.SplitText(content)
+		ed, err := diff.Generate(prev, cur)
+		if err != nil {
+			return nil, fmt.Errorf("revision %s: %w", r, err)
+		}
+		next := make([]AnnotatedLine, 0, len(cur))
+		old := 0
+		for _, op := range ed.Ops(prev) {
+			switch op.Kind {
+			case diff.OpEqual:
+				next = append(next, lines[old])
+				old++
+			case diff.OpDelete:
+				old++
+			case diff.OpInsert:
+				text, nl := strings.CutSuffix(op.Text, "\n")
+				next = append(next, AnnotatedLine{Revision: r, Author: rh.Author.String(), Date: rh.Date, Text: text, NoNewline: !nl})
+			}
+		}
+		lines, prev = next, cur
+	}
+	return lines, nil
+}
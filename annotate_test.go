@@ -0,0 +1,156 @@
+package rcs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const annotateTestMaster = `head	1.2;
+access;
+symbols;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author bob;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@second
+@
+text
+@a
+B
+c
+d
+@
+
+
+1.1
+log
+@first
+@
+text
+@d2 1
+a2 1
+b
+d4 1
+@
+`
+
+func TestAnnotate(t *testing.T) {
+	f, err := ParseFile(strings.NewReader(annotateTestMaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Annotate("")
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	alice := AnnotatedLine{Revision: "1.1", Author: "alice", Date: "2021.03.03.05.06.07"}
+	bob := AnnotatedLine{Revision: "1.2", Author: "bob", Date: "2021.03.04.05.06.07"}
+	line := func(a AnnotatedLine, text string) AnnotatedLine {
+		a.Text = text
+		return a
+	}
+	want := []AnnotatedLine{line(alice, "a"), line(bob, "B"), line(alice, "c"), line(bob, "d")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Annotate() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = f.Annotate("1.1")
+	if err != nil {
+		t.Fatalf("Annotate(1.1) error = %v", err)
+	}
+	want = []AnnotatedLine{line(alice, "a"), line(alice, "b"), line(alice, "c")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Annotate(1.1) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+const annotateNoNewlineTestMaster = `head	1.2;
+access;
+symbols;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author bob;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@drop the final newline
+@
+text
+@a
+b@
+
+
+1.1
+log
+@first
+@
+text
+@d2 1
+a2 1
+b
+@
+`
+
+func TestAnnotateNoNewline(t *testing.T) {
+	f, err := ParseFile(strings.NewReader(annotateNoNewlineTestMaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Annotate("")
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	want := []AnnotatedLine{
+		{Revision: "1.1", Author: "alice", Date: "2021.03.03.05.06.07", Text: "a"},
+		{Revision: "1.2", Author: "bob", Date: "2021.03.04.05.06.07", Text: "b", NoNewline: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Annotate() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = f.Annotate("1.1")
+	if err != nil {
+		t.Fatalf("Annotate(1.1) error = %v", err)
+	}
+	want = []AnnotatedLine{
+		{Revision: "1.1", Author: "alice", Date: "2021.03.03.05.06.07", Text: "a"},
+		{Revision: "1.1", Author: "alice", Date: "2021.03.03.05.06.07", Text: "b"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Annotate(1.1) mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,603 @@
+// Package archivefs reads RCS masters that are compressed or kept inside
+// archives, without unpacking them first.
+//
+// A single master is named by its path, which may end in .gz or .zst, or by
+// an archive path and the member's path inside it joined with "//":
+//
+//	r, err := archivefs.Open("backup.tar.gz//src/foo.c,v")
+//
+// OpenArchive presents a whole .tar, .tar.gz, .tgz, .tar.zst or .zip
+// archive as a read-only fs.FS, so fs.WalkDir can find the masters in it.
+// Compressed tar archives cannot be seeked, so their members are read by
+// decompressing forward from the start; opening members in the order of
+// Names reads the archive only once.
+package archivefs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Separator joins an archive path and the path of a member inside it.
+const Separator = "//"
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// archiveExts are the file name endings of the archives OpenArchive reads.
+var archiveExts = []string{".tar", ".tar.gz", ".tgz", ".tar.zst", ".tar.zstd", ".tzst", ".zip"}
+
+// compressionExts are the endings of compressed single files.
+var compressionExts = []string{".gz", ".zst", ".zstd"}
+
+// IsArchive reports whether name looks like an archive OpenArchive reads,
+// going by its extension.
+func IsArchive(name string) bool {
+	lower := strings.ToLower(name)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrimCompressionExt returns name without a .gz, .zst or .zstd ending, so
+// "foo.c,v.gz" gives "foo.c,v".
+func TrimCompressionExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range compressionExts {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// SplitPath splits name at the first Separator that follows an archive
+// path, returning the archive and the member path inside it. A trailing
+// Separator gives an empty member, the root of the archive. ok is false when
+// name does not point inside an archive.
+func SplitPath(name string) (archive, member string, ok bool) {
+	for i := 0; ; {
+		j := strings.Index(name[i:], Separator)
+		if j < 0 {
+			return "", "", false
+		}
+		i += j
+		if IsArchive(name[:i]) {
+			return name[:i], strings.Trim(name[i+len(Separator):], "/"), true
+		}
+		i += len(Separator)
+	}
+}
+
+// Decompress returns a reader of the data in r, decompressing it when it
+// starts with a gzip or zstd header and passing it through otherwise.
+// Closing the result closes r when r is an io.Closer. An uncompressed
+// io.ReadSeeker that is also an io.Closer is returned as it is, so it keeps
+// its io.ReaderAt and io.Seeker methods.
+func Decompress(r io.Reader) (io.ReadCloser, error) {
+	if rs, ok := r.(io.ReadSeekCloser); ok {
+		compressed, err := isCompressed(rs)
+		if err != nil {
+			return nil, err
+		}
+		if !compressed {
+			return rs, nil
+		}
+	}
+	br := bufio.NewReader(r)
+	closer := func() error {
+		if c, ok := r.(io.Closer); ok {
+			return c.Close()
+		}
+		return nil
+	}
+	head, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &readCloser{Reader: zr, close: func() error { return errors.Join(zr.Close(), closer()) }}, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &readCloser{Reader: zr, close: func() error { zr.Close(); return closer() }}, nil
+	}
+	return &readCloser{Reader: br, close: closer}, nil
+}
+
+// isCompressed reports whether the data at the current offset of r starts
+// with a gzip or zstd header, leaving the offset where it was.
+func isCompressed(r io.ReadSeeker) (bool, error) {
+	pos, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	head := make([]byte, len(zstdMagic))
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return false, err
+	}
+	head = head[:n]
+	return bytes.HasPrefix(head, gzipMagic) || bytes.HasPrefix(head, zstdMagic), nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *readCloser) Close() error {
+	return r.close()
+}
+
+// Open opens a master for reading. name is a file, compressed or not, or a
+// member of an archive as described by SplitPath. Compressed data, whether
+// the file itself or the archive member, is decompressed. A member of a tar
+// archive is found by reading the archive up to it.
+func Open(name string) (io.ReadCloser, error) {
+	archive, member, ok := SplitPath(name)
+	if !ok {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		r, err := Decompress(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return r, nil
+	}
+	r, err := openMember(archive, member)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return r, nil
+}
+
+// openMember opens member of archive without indexing the whole archive.
+func openMember(archive, member string) (io.ReadCloser, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := sniff(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if kind == kindZip {
+		st, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		zr, err := zip.NewReader(f, st.Size())
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		for _, zf := range zr.File {
+			if cleanName(zf.Name) == member {
+				rc, err := zf.Open()
+				if err != nil {
+					_ = f.Close()
+					return nil, err
+				}
+				return decompressMember(&readCloser{Reader: rc, close: func() error { return errors.Join(rc.Close(), f.Close()) }})
+			}
+		}
+		_ = f.Close()
+		return nil, fs.ErrNotExist
+	}
+	stream, err := Decompress(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	tr := tar.NewReader(stream)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			_ = stream.Close()
+			return nil, fs.ErrNotExist
+		}
+		if err != nil {
+			_ = stream.Close()
+			return nil, err
+		}
+		if cleanName(hdr.Name) == member && isRegular(hdr) {
+			return decompressMember(&readCloser{Reader: tr, close: stream.Close})
+		}
+	}
+}
+
+// decompressMember decompresses a compressed member, closing rc on error.
+func decompressMember(rc io.ReadCloser) (io.ReadCloser, error) {
+	r, err := Decompress(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+type archiveKind int
+
+const (
+	kindTar archiveKind = iota
+	kindCompressedTar
+	kindZip
+)
+
+// sniff reports the kind of archive f holds from its first bytes and
+// rewinds it.
+func sniff(f *os.File) (archiveKind, error) {
+	head := make([]byte, len(zstdMagic))
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, zipMagic):
+		return kindZip, nil
+	case bytes.HasPrefix(head, gzipMagic), bytes.HasPrefix(head, zstdMagic):
+		return kindCompressedTar, nil
+	}
+	return kindTar, nil
+}
+
+func cleanName(name string) string {
+	return path.Clean("/" + name)[1:]
+}
+
+func isRegular(hdr *tar.Header) bool {
+	return hdr.FileInfo().Mode().IsRegular()
+}
+
+// FS is a read-only fs.FS over the files in an archive. Members are
+// returned as stored; use Decompress for members that are themselves
+// compressed. It is safe for concurrent use.
+type FS struct {
+	file    *os.File
+	kind    archiveKind
+	zip     *zip.Reader
+	entries map[string]*entry
+	order   []string
+
+	mu     sync.Mutex
+	stream io.ReadCloser
+	tr     *tar.Reader
+	next   int
+}
+
+type entry struct {
+	name     string
+	size     int64
+	mode     fs.FileMode
+	modTime  time.Time
+	index    int
+	offset   int64
+	zf       *zip.File
+	children []*entry
+}
+
+// OpenArchive indexes the tar or zip archive at name, compressed with gzip
+// or zstd or not, and returns it as an FS. Close releases the archive.
+func OpenArchive(name string) (*FS, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	a := &FS{file: f, entries: map[string]*entry{"": {mode: fs.ModeDir | 0555}}}
+	if err := a.index(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	for _, e := range a.entries {
+		sort.Slice(e.children, func(i, j int) bool { return e.children[i].name < e.children[j].name })
+	}
+	return a, nil
+}
+
+func (a *FS) index() error {
+	kind, err := sniff(a.file)
+	if err != nil {
+		return err
+	}
+	a.kind = kind
+	if kind == kindZip {
+		st, err := a.file.Stat()
+		if err != nil {
+			return err
+		}
+		if a.zip, err = zip.NewReader(a.file, st.Size()); err != nil {
+			return err
+		}
+		for i, zf := range a.zip.File {
+			if strings.HasSuffix(zf.Name, "/") {
+				a.dir(cleanName(zf.Name))
+				continue
+			}
+			a.add(&entry{name: cleanName(zf.Name), size: int64(zf.UncompressedSize64), mode: zf.Mode(), modTime: zf.Modified, index: i, zf: zf})
+		}
+		return nil
+	}
+
+	// Read through a section reader so closing the stream leaves the
+	// file open.
+	cr := &countingReader{r: io.NewSectionReader(a.file, 0, 1<<62)}
+	var r io.Reader = cr
+	if kind == kindCompressedTar {
+		stream, err := Decompress(cr)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = stream.Close()
+		}()
+		r = stream
+	}
+	tr := tar.NewReader(r)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case hdr.Typeflag == tar.TypeDir:
+			a.dir(cleanName(hdr.Name))
+		case isRegular(hdr):
+			a.add(&entry{name: cleanName(hdr.Name), size: hdr.Size, mode: hdr.FileInfo().Mode(), modTime: hdr.ModTime, index: i, offset: cr.n})
+		}
+	}
+	return nil
+}
+
+// add records a file, creating the directories above it.
+func (a *FS) add(e *entry) {
+	if e.name == "" || a.entries[e.name] != nil {
+		return
+	}
+	a.entries[e.name] = e
+	a.order = append(a.order, e.name)
+	parent := a.dir(path.Dir(e.name))
+	parent.children = append(parent.children, e)
+}
+
+// dir returns the directory entry for name, creating it and its parents.
+func (a *FS) dir(name string) *entry {
+	if name == "." {
+		name = ""
+	}
+	if e, ok := a.entries[name]; ok {
+		return e
+	}
+	e := &entry{name: name, mode: fs.ModeDir | 0555}
+	a.entries[name] = e
+	parent := a.dir(path.Dir(name))
+	parent.children = append(parent.children, e)
+	return e
+}
+
+// Names returns the paths of the files in the archive in archive order.
+func (a *FS) Names() []string {
+	return append([]string(nil), a.order...)
+}
+
+// Close closes the archive file.
+func (a *FS) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stream != nil {
+		_ = a.stream.Close()
+		a.stream = nil
+	}
+	return a.file.Close()
+}
+
+func (a *FS) lookup(op, name string) (*entry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		name = ""
+	}
+	e, ok := a.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return e, nil
+}
+
+// Open opens the named file or directory.
+func (a *FS) Open(name string) (fs.File, error) {
+	e, err := a.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if e.mode.IsDir() {
+		return &dirFile{e: e}, nil
+	}
+	var r io.Reader
+	switch {
+	case e.zf != nil:
+		rc, err := e.zf.Open()
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &file{e: e, Reader: rc, close: rc.Close}, nil
+	case a.kind == kindTar:
+		r = io.NewSectionReader(a.file, e.offset, e.size)
+	default:
+		data, err := a.readStream(e)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		r = bytes.NewReader(data)
+	}
+	return &file{e: e, Reader: r, close: func() error { return nil }}, nil
+}
+
+// readStream reads the data of e from a compressed tar, carrying on from
+// the last member read when e comes after it and starting again otherwise.
+func (a *FS) readStream(e *entry) ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stream == nil || a.next > e.index {
+		if a.stream != nil {
+			_ = a.stream.Close()
+			a.stream = nil
+		}
+		sr := io.NewSectionReader(a.file, 0, 1<<62)
+		stream, err := Decompress(sr)
+		if err != nil {
+			return nil, err
+		}
+		a.stream, a.tr, a.next = stream, tar.NewReader(stream), 0
+	}
+	for {
+		if _, err := a.tr.Next(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		i := a.next
+		a.next++
+		if i == e.index {
+			return io.ReadAll(a.tr)
+		}
+	}
+}
+
+// ReadDir reads the named directory, sorted by file name.
+func (a *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := a.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !e.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return dirEntries(e.children), nil
+}
+
+// Stat returns a FileInfo describing the named file.
+func (a *FS) Stat(name string) (fs.FileInfo, error) {
+	e, err := a.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{e}, nil
+}
+
+var (
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+)
+
+func dirEntries(children []*entry) []fs.DirEntry {
+	list := make([]fs.DirEntry, len(children))
+	for i, c := range children {
+		list[i] = fs.FileInfoToDirEntry(fileInfo{c})
+	}
+	return list
+}
+
+type fileInfo struct {
+	e *entry
+}
+
+func (fi fileInfo) Name() string {
+	if fi.e.name == "" {
+		return "."
+	}
+	return path.Base(fi.e.name)
+}
+func (fi fileInfo) Size() int64        { return fi.e.size }
+func (fi fileInfo) Mode() fs.FileMode  { return fi.e.mode }
+func (fi fileInfo) ModTime() time.Time { return fi.e.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.e.mode.IsDir() }
+func (fi fileInfo) Sys() any           { return nil }
+
+type file struct {
+	io.Reader
+	e     *entry
+	close func() error
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return fileInfo{f.e}, nil }
+func (f *file) Close() error               { return f.close() }
+
+type dirFile struct {
+	e   *entry
+	off int
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return fileInfo{d.e}, nil }
+func (d *dirFile) Close() error               { return nil }
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.e.name, Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.e.children[d.off:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	d.off += len(rest)
+	return dirEntries(rest), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
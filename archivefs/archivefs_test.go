@@ -0,0 +1,256 @@
+package archivefs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+)
+
+var testMembers = []struct {
+	name, data string
+}{
+	{"src/foo.c,v", "head\t1.1;\n"},
+	{"src/bar.c,v", "head\t1.2;\n"},
+	{"README", "not a master\n"},
+	{"src/lib/baz.h,v.gz", string(gzipBytes("head\t1.3;\n"))},
+}
+
+func gzipBytes(s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write([]byte(s))
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func zstdBytes(b []byte) []byte {
+	var buf bytes.Buffer
+	w, _ := zstd.NewWriter(&buf)
+	_, _ = w.Write(b)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func tarBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	if err := w.WriteHeader(&tar.Header{Name: "./src/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range testMembers {
+		if err := w.WriteHeader(&tar.Header{Name: "./" + m.name, Mode: 0444, Size: int64(len(m.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(m.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, m := range testMembers {
+		fw, err := w.Create(m.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(m.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeArchives(t *testing.T) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	tb := tarBytes(t)
+	archives := map[string][]byte{
+		"backup.tar":     tb,
+		"backup.tar.gz":  gzipBytes(string(tb)),
+		"backup.tar.zst": zstdBytes(tb),
+		"backup.zip":     zipBytes(t),
+	}
+	paths := map[string]string{}
+	for name, data := range archives {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths[name] = p
+	}
+	return paths
+}
+
+func readAll(t *testing.T, r io.ReadCloser, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		name, archive, member string
+		ok                    bool
+	}{
+		{"backup.tar.gz//src/foo.c,v", "backup.tar.gz", "src/foo.c,v", true},
+		{"/srv//old/backup.ZIP//a,v", "/srv//old/backup.ZIP", "a,v", true},
+		{"backup.tgz//", "backup.tgz", "", true},
+		{"src//foo.c,v", "", "", false},
+		{"foo.c,v.gz", "", "", false},
+	}
+	for _, tt := range tests {
+		archive, member, ok := SplitPath(tt.name)
+		if archive != tt.archive || member != tt.member || ok != tt.ok {
+			t.Errorf("SplitPath(%q) = %q, %q, %v, want %q, %q, %v", tt.name, archive, member, ok, tt.archive, tt.member, tt.ok)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	paths := writeArchives(t)
+	for name, p := range paths {
+		t.Run(name, func(t *testing.T) {
+			r, err := Open(p + "//src/bar.c,v")
+			if got := readAll(t, r, err); got != "head\t1.2;\n" {
+				t.Errorf("Open() = %q", got)
+			}
+			r, err = Open(p + "//src/lib/baz.h,v.gz")
+			if got := readAll(t, r, err); got != "head\t1.3;\n" {
+				t.Errorf("Open() compressed member = %q", got)
+			}
+			if _, err := Open(p + "//src/missing,v"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Open() missing member error = %v", err)
+			}
+		})
+	}
+
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"plain,v":     []byte("head\t1.1;\n"),
+		"foo.c,v.gz":  gzipBytes("head\t1.1;\n"),
+		"foo.c,v.zst": zstdBytes([]byte("head\t1.1;\n")),
+	} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		r, err := Open(p)
+		if got := readAll(t, r, err); got != "head\t1.1;\n" {
+			t.Errorf("Open(%s) = %q", name, got)
+		}
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		data     []byte
+		readerAt bool
+	}{
+		{"plain", []byte("head\t1.1;\n"), true},
+		{"short", []byte("h"), true},
+		{"gzip", gzipBytes("head\t1.1;\n"), false},
+		{"zstd", zstdBytes([]byte("head\t1.1;\n")), false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "foo.c,v")
+			if err := os.WriteFile(p, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := Decompress(f)
+			if err != nil {
+				t.Fatalf("Decompress() error = %v", err)
+			}
+			if _, ok := r.(io.ReaderAt); ok != tt.readerAt {
+				t.Errorf("Decompress() is io.ReaderAt = %v, want %v", ok, tt.readerAt)
+			}
+			want := string(tt.data)
+			if !tt.readerAt {
+				want = "head\t1.1;\n"
+			}
+			if got := readAll(t, r, nil); got != want {
+				t.Errorf("Decompress() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	paths := writeArchives(t)
+	for name, p := range paths {
+		t.Run(name, func(t *testing.T) {
+			a, err := OpenArchive(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = a.Close()
+			}()
+			want := []string{"src/foo.c,v", "src/bar.c,v", "README", "src/lib/baz.h,v.gz"}
+			if diff := cmp.Diff(want, a.Names()); diff != "" {
+				t.Errorf("Names() mismatch (-want +got):\n%s", diff)
+			}
+			if err := fstest.TestFS(a, want...); err != nil {
+				t.Error(err)
+			}
+			// Reading out of archive order starts the stream again.
+			for _, n := range []string{"src/bar.c,v", "src/foo.c,v", "src/bar.c,v"} {
+				b, err := fs.ReadFile(a, n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(b) == 0 {
+					t.Errorf("ReadFile(%s) is empty", n)
+				}
+			}
+			var walked []string
+			err = fs.WalkDir(a, "src", func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					walked = append(walked, path)
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]string{"src/bar.c,v", "src/foo.c,v", "src/lib/baz.h,v.gz"}, walked); diff != "" {
+				t.Errorf("WalkDir mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
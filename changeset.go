@@ -0,0 +1,308 @@
+package rcs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultChangesetFuzz is the default window within which revisions with the
+// same author and log message are considered part of the same changeset.
+const DefaultChangesetFuzz = 300 * time.Second
+
+// TrunkBranchName is the branch label used for trunk revisions in changesets.
+const TrunkBranchName = "HEAD"
+
+// ChangesetMember is a single file revision belonging to a Changeset.
+type ChangesetMember struct {
+	File     string
+	Revision string
+	Parent   string `json:",omitempty"`
+	State    string
+	Date     time.Time
+}
+
+// Dead reports whether the member revision removes the file.
+func (m *ChangesetMember) Dead() bool {
+	return m.State == "dead"
+}
+
+// Changeset is a group of per-file revisions that were most likely committed
+// together.
+type Changeset struct {
+	ID       int
+	Branch   string
+	Date     time.Time
+	EndDate  time.Time
+	Author   string
+	Log      string
+	CommitID string   `json:",omitempty"`
+	Tags     []string `json:",omitempty"`
+	Members  []*ChangesetMember
+}
+
+// ChangesetOptions controls BuildChangesets.
+type ChangesetOptions struct {
+	// Fuzz is the maximum gap between consecutive revisions of a changeset
+	// that are matched by author and log message. Zero uses
+	// DefaultChangesetFuzz.
+	Fuzz time.Duration
+}
+
+// NamedFile is a parsed RCS file together with the name it is reported under.
+type NamedFile struct {
+	Name string
+	File *File
+}
+
+type changesetRevision struct {
+	member   *ChangesetMember
+	branch   string
+	author   string
+	log      string
+	commitID string
+	tags     []string
+	set      *Changeset
+	parent   *changesetRevision
+}
+
+// BuildChangesets clusters the revisions of many RCS files into logical
+// commits the same way cvsps does.
+//
+// Revisions sharing a CommitID always form one changeset. Remaining revisions
+// are grouped when they are on the same branch, have identical author and log
+// message, and are no more than Fuzz apart from the previous revision in the
+// group. A changeset never contains two revisions of the same file.
+//
+// The result is ordered topologically: a changeset always comes after the
+// changesets holding the parent revisions of its members. Ties are broken by
+// date. IDs are assigned in that order starting at 1.
+func BuildChangesets(files []NamedFile, opts ChangesetOptions) ([]*Changeset, error) {
+	fuzz := opts.Fuzz
+	if fuzz <= 0 {
+		fuzz = DefaultChangesetFuzz
+	}
+
+	var revisions []*changesetRevision
+	for _, nf := range files {
+		revs, err := changesetRevisions(nf)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revs...)
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		a, b := revisions[i], revisions[j]
+		if !a.member.Date.Equal(b.member.Date) {
+			return a.member.Date.Before(b.member.Date)
+		}
+		if a.member.File != b.member.File {
+			return a.member.File < b.member.File
+		}
+		return compareRevisions(a.member.Revision, b.member.Revision) < 0
+	})
+
+	var sets []*Changeset
+	byCommitID := map[string]*Changeset{}
+	open := map[string]*Changeset{}
+	for _, r := range revisions {
+		if r.commitID != "" {
+			key := r.branch + "\x00" + r.commitID
+			cs, ok := byCommitID[key]
+			if !ok {
+				cs = newChangeset(r)
+				byCommitID[key] = cs
+				sets = append(sets, cs)
+			}
+			addToChangeset(cs, r)
+			continue
+		}
+		key := r.branch + "\x00" + r.author + "\x00" + r.log
+		cs, ok := open[key]
+		if !ok || r.member.Date.Sub(cs.EndDate) > fuzz || changesetHasFile(cs, r.member.File) {
+			cs = newChangeset(r)
+			open[key] = cs
+			sets = append(sets, cs)
+		}
+		addToChangeset(cs, r)
+	}
+
+	ordered := orderChangesets(sets, revisions)
+	for i, cs := range ordered {
+		cs.ID = i + 1
+		sort.Slice(cs.Members, func(i, j int) bool {
+			return cs.Members[i].File < cs.Members[j].File
+		})
+		sort.Strings(cs.Tags)
+	}
+	return ordered, nil
+}
+
+func changesetRevisions(nf NamedFile) ([]*changesetRevision, error) {
+	f := nf.File
+	if f == nil {
+		return nil, fmt.Errorf("%s: nil file", nf.Name)
+	}
+	parents := f.RevisionParents()
+	branchNames := f.BranchSymbols()
+	tags := map[string][]string{}
+	for _, s := range f.Symbols {
+		if strings.Count(s.Revision, ".")%2 == 1 && NormalizeBranchSymbol(s.Revision) == s.Revision {
+			tags[s.Revision] = append(tags[s.Revision], s.Name)
+		}
+	}
+	logs := map[string]string{}
+	for _, rc := range f.RevisionContents {
+		logs[rc.Revision] = rc.Log
+	}
+
+	byRevision := map[string]*changesetRevision{}
+	var result []*changesetRevision
+	for _, rh := range f.RevisionHeads {
+		rev := rh.Revision.String()
+		date, err := rh.Date.DateTime()
+		if err != nil {
+			return nil, fmt.Errorf("%s: revision %s: %w", nf.Name, rev, err)
+		}
+		branch := TrunkBranchName
+		if bn := BranchNumber(rev); bn != "" {
+			branch = branchNames[bn]
+			if branch == "" {
+				branch = bn
+			}
+		}
+		r := &changesetRevision{
+			member: &ChangesetMember{
+				File:     nf.Name,
+				Revision: rev,
+				Parent:   parents[rev],
+				State:    rh.State.String(),
+				Date:     date,
+			},
+			branch:   branch,
+			author:   rh.Author.String(),
+			log:      logs[rev],
+			commitID: rh.CommitID.String(),
+			tags:     tags[rev],
+		}
+		byRevision[rev] = r
+		result = append(result, r)
+	}
+	for _, r := range result {
+		r.parent = byRevision[r.member.Parent]
+	}
+	return result, nil
+}
+
+func newChangeset(r *changesetRevision) *Changeset {
+	return &Changeset{
+		Branch:   r.branch,
+		Date:     r.member.Date,
+		EndDate:  r.member.Date,
+		Author:   r.author,
+		Log:      r.log,
+		CommitID: r.commitID,
+	}
+}
+
+func addToChangeset(cs *Changeset, r *changesetRevision) {
+	cs.Members = append(cs.Members, r.member)
+	if r.member.Date.Before(cs.Date) {
+		cs.Date = r.member.Date
+	}
+	if r.member.Date.After(cs.EndDate) {
+		cs.EndDate = r.member.Date
+	}
+	for _, t := range r.tags {
+		if !containsString(cs.Tags, t) {
+			cs.Tags = append(cs.Tags, t)
+		}
+	}
+	r.set = cs
+}
+
+func changesetHasFile(cs *Changeset, file string) bool {
+	for _, m := range cs.Members {
+		if m.File == file {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// orderChangesets sorts changesets with Kahn's algorithm using the
+// parent-revision relationship between members. When clustering produced a
+// cycle the earliest remaining changeset is emitted to break it.
+func orderChangesets(sets []*Changeset, revisions []*changesetRevision) []*Changeset {
+	index := make(map[*Changeset]int, len(sets))
+	for i, cs := range sets {
+		index[cs] = i
+	}
+	deps := make([]map[int]bool, len(sets))
+	dependents := make([][]int, len(sets))
+	for i := range sets {
+		deps[i] = map[int]bool{}
+	}
+	for _, r := range revisions {
+		if r.parent == nil || r.parent.set == r.set {
+			continue
+		}
+		from, to := index[r.parent.set], index[r.set]
+		if !deps[to][from] {
+			deps[to][from] = true
+			dependents[from] = append(dependents[from], to)
+		}
+	}
+
+	less := func(a, b int) bool {
+		if !sets[a].Date.Equal(sets[b].Date) {
+			return sets[a].Date.Before(sets[b].Date)
+		}
+		return a < b
+	}
+	done := make([]bool, len(sets))
+	var ready []int
+	for i := range sets {
+		if len(deps[i]) == 0 {
+			ready = append(ready, i)
+		}
+	}
+	ordered := make([]*Changeset, 0, len(sets))
+	for len(ordered) < len(sets) {
+		if len(ready) == 0 {
+			earliest := -1
+			for i := range sets {
+				if !done[i] && (earliest < 0 || less(i, earliest)) {
+					earliest = i
+				}
+			}
+			ready = append(ready, earliest)
+		}
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		n := ready[0]
+		ready = ready[1:]
+		if done[n] {
+			continue
+		}
+		done[n] = true
+		ordered = append(ordered, sets[n])
+		for _, d := range dependents[n] {
+			delete(deps[d], n)
+			if len(deps[d]) == 0 && !done[d] {
+				ready = append(ready, d)
+			}
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,135 @@
+package rcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func changesetTestFile(symbols []*Symbol, heads []*RevisionHead, logs map[string]string) *File {
+	f := NewFile()
+	f.Head = heads[0].Revision.String()
+	f.Symbols = symbols
+	f.RevisionHeads = heads
+	for _, rh := range heads {
+		f.RevisionContents = append(f.RevisionContents, &RevisionContent{
+			Revision: rh.Revision.String(),
+			Log:      logs[rh.Revision.String()],
+		})
+	}
+	return f
+}
+
+func TestBuildChangesets(t *testing.T) {
+	a := changesetTestFile(
+		[]*Symbol{{Name: "REL_1", Revision: "1.2"}, {Name: "fix", Revision: "1.2.0.2"}},
+		[]*RevisionHead{
+			{Revision: "1.3", Date: "2020.01.03.10.00.00", Author: "bob", State: "Exp", NextRevision: "1.2"},
+			{Revision: "1.2", Date: "2020.01.02.10.00.00", Author: "alice", State: "Exp", Branches: []Num{"1.2.2.1"}, NextRevision: "1.1"},
+			{Revision: "1.1", Date: "2020.01.01.10.00.00", Author: "alice", State: "Exp"},
+			{Revision: "1.2.2.1", Date: "2020.01.04.10.00.00", Author: "carol", State: "Exp", CommitID: "abc"},
+		},
+		map[string]string{"1.1": "initial\n", "1.2": "second\n", "1.3": "third\n", "1.2.2.1": "branch fix\n"},
+	)
+	b := changesetTestFile(
+		[]*Symbol{{Name: "REL_1", Revision: "1.1"}, {Name: "fix", Revision: "1.1.0.2"}},
+		[]*RevisionHead{
+			{Revision: "1.2", Date: "2020.01.02.10.03.00", Author: "alice", State: "Exp", NextRevision: "1.1"},
+			{Revision: "1.1", Date: "2020.01.01.10.01.00", Author: "alice", State: "Exp", Branches: []Num{"1.1.2.1"}},
+			{Revision: "1.1.2.1", Date: "2020.01.04.12.00.00", Author: "carol", State: "dead", CommitID: "abc"},
+		},
+		map[string]string{"1.1": "initial\n", "1.2": "second\n", "1.1.2.1": "branch fix\n"},
+	)
+
+	got, err := BuildChangesets([]NamedFile{{Name: "a.txt", File: a}, {Name: "b.txt", File: b}}, ChangesetOptions{})
+	if err != nil {
+		t.Fatalf("BuildChangesets() error = %v", err)
+	}
+
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	want := []*Changeset{
+		{
+			ID: 1, Branch: "HEAD", Date: date("2020-01-01 10:00"), EndDate: date("2020-01-01 10:01"),
+			Author: "alice", Log: "initial\n", Tags: []string{"REL_1"},
+			Members: []*ChangesetMember{
+				{File: "a.txt", Revision: "1.1", State: "Exp", Date: date("2020-01-01 10:00")},
+				{File: "b.txt", Revision: "1.1", State: "Exp", Date: date("2020-01-01 10:01")},
+			},
+		},
+		{
+			ID: 2, Branch: "HEAD", Date: date("2020-01-02 10:00"), EndDate: date("2020-01-02 10:03"),
+			Author: "alice", Log: "second\n", Tags: []string{"REL_1"},
+			Members: []*ChangesetMember{
+				{File: "a.txt", Revision: "1.2", Parent: "1.1", State: "Exp", Date: date("2020-01-02 10:00")},
+				{File: "b.txt", Revision: "1.2", Parent: "1.1", State: "Exp", Date: date("2020-01-02 10:03")},
+			},
+		},
+		{
+			ID: 3, Branch: "HEAD", Date: date("2020-01-03 10:00"), EndDate: date("2020-01-03 10:00"),
+			Author: "bob", Log: "third\n",
+			Members: []*ChangesetMember{
+				{File: "a.txt", Revision: "1.3", Parent: "1.2", State: "Exp", Date: date("2020-01-03 10:00")},
+			},
+		},
+		{
+			ID: 4, Branch: "fix", Date: date("2020-01-04 10:00"), EndDate: date("2020-01-04 12:00"),
+			Author: "carol", Log: "branch fix\n", CommitID: "abc",
+			Members: []*ChangesetMember{
+				{File: "a.txt", Revision: "1.2.2.1", Parent: "1.2", State: "Exp", Date: date("2020-01-04 10:00")},
+				{File: "b.txt", Revision: "1.1.2.1", Parent: "1.1", State: "dead", Date: date("2020-01-04 12:00")},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BuildChangesets() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildChangesetsFuzz(t *testing.T) {
+	a := changesetTestFile(nil, []*RevisionHead{
+		{Revision: "1.1", Date: "2020.01.01.10.00.00", Author: "alice", State: "Exp"},
+	}, map[string]string{"1.1": "same\n"})
+	b := changesetTestFile(nil, []*RevisionHead{
+		{Revision: "1.1", Date: "2020.01.01.10.20.00", Author: "alice", State: "Exp"},
+	}, map[string]string{"1.1": "same\n"})
+	files := []NamedFile{{Name: "a", File: a}, {Name: "b", File: b}}
+
+	got, err := BuildChangesets(files, ChangesetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("default fuzz: got %d changesets, want 2", len(got))
+	}
+
+	got, err = BuildChangesets(files, ChangesetOptions{Fuzz: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("hour fuzz: got %d changesets, want 1", len(got))
+	}
+}
+
+func TestRevisionParents(t *testing.T) {
+	f := changesetTestFile(nil, []*RevisionHead{
+		{Revision: "1.2", NextRevision: "1.1"},
+		{Revision: "1.1", Branches: []Num{"1.1.1.1"}},
+		{Revision: "1.1.1.1", NextRevision: "1.1.1.2"},
+		{Revision: "1.1.1.2"},
+	}, nil)
+	want := map[string]string{"1.2": "1.1", "1.1": "", "1.1.1.1": "1.1", "1.1.1.2": "1.1.1.1"}
+	if diff := cmp.Diff(want, f.RevisionParents()); diff != "" {
+		t.Errorf("RevisionParents() mismatch (-want +got):\n%s", diff)
+	}
+	if got := NormalizeBranchSymbol("1.2.0.4"); got != "1.2.4" {
+		t.Errorf("NormalizeBranchSymbol() = %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package rcs
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charsets TranscodeToUTF8 accepts. Masters from before UTF-8 was common
+// often carry author names and log messages in one of these.
+const (
+	CharsetLatin1 = "latin1"
+	CharsetCP1252 = "cp1252"
+)
+
+// cp1252High maps the CP1252 bytes 0x80 to 0x9F. Bytes CP1252 leaves
+// undefined keep their Latin-1 meaning.
+var cp1252High = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+func normalizeCharset(charset string) (string, error) {
+	switch strings.ToLower(strings.ReplaceAll(charset, "_", "-")) {
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		return CharsetLatin1, nil
+	case "cp1252", "windows-1252":
+		return CharsetCP1252, nil
+	}
+	return "", fmt.Errorf("%w %q: want %s or %s", ErrUnknownCharset, charset, CharsetLatin1, CharsetCP1252)
+}
+
+// TranscodeToUTF8 converts s from charset to UTF-8. A string that is
+// already valid UTF-8 is returned unchanged, so masters that mix encodings
+// only have their legacy values converted.
+func TranscodeToUTF8(s, charset string) (string, error) {
+	cs, err := normalizeCharset(charset)
+	if err != nil {
+		return "", err
+	}
+	if utf8.ValidString(s) {
+		return s, nil
+	}
+	var sb strings.Builder
+	sb.Grow(len(s) + len(s)/4)
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch {
+		case b < 0x80:
+			sb.WriteByte(b)
+		case cs == CharsetCP1252 && b < 0xA0:
+			sb.WriteRune(cp1252High[b-0x80])
+		default:
+			sb.WriteRune(rune(b))
+		}
+	}
+	return sb.String(), nil
+}
+
+// TranscodeToUTF8 converts the log messages and authors of f from charset
+// to UTF-8 for export, leaving values that are already UTF-8 alone.
+// Revision texts are not touched as that would change their deltas.
+func (f *File) TranscodeToUTF8(charset string) error {
+	if _, err := normalizeCharset(charset); err != nil {
+		return err
+	}
+	for _, rh := range f.RevisionHeads {
+		author, err := TranscodeToUTF8(rh.Author.String(), charset)
+		if err != nil {
+			return err
+		}
+		rh.Author = ID(author)
+	}
+	for _, rc := range f.RevisionContents {
+		log, err := TranscodeToUTF8(rc.Log, charset)
+		if err != nil {
+			return err
+		}
+		rc.Log = log
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package rcs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTranscodeToUTF8(t *testing.T) {
+	tests := []struct {
+		in, charset, want string
+	}{
+		{"jos\xe9", "latin1", "josé"},
+		{"jos\xe9", "ISO-8859-1", "josé"},
+		{"\x93quoted\x94 \x80", "cp1252", "“quoted” €"},
+		{"\x93quoted\x94", "latin1", "\u0093quoted\u0094"},
+		{"\x81", "windows-1252", "\u0081"},
+		{"josé “already”", "cp1252", "josé “already”"},
+		{"plain", "latin1", "plain"},
+	}
+	for _, tt := range tests {
+		got, err := TranscodeToUTF8(tt.in, tt.charset)
+		if err != nil {
+			t.Errorf("TranscodeToUTF8(%q, %q) error = %v", tt.in, tt.charset, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("TranscodeToUTF8(%q, %q) = %q, want %q", tt.in, tt.charset, got, tt.want)
+		}
+	}
+	if _, err := TranscodeToUTF8("x", "ebcdic"); !errors.Is(err, ErrUnknownCharset) {
+		t.Errorf("TranscodeToUTF8 with unknown charset error = %v, want ErrUnknownCharset", err)
+	}
+}
+
+func TestFile_TranscodeToUTF8(t *testing.T) {
+	f, err := ParseFile(strings.NewReader(nonUTF8TestMaster))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	text := f.RevisionContents[0].Text
+	if err := f.TranscodeToUTF8("cp1252"); err != nil {
+		t.Fatalf("TranscodeToUTF8() error = %v", err)
+	}
+	if got := f.RevisionHeads[0].Author.String(); got != "josé" {
+		t.Errorf("Author = %q, want %q", got, "josé")
+	}
+	if got := f.RevisionContents[0].Log; got != "résumé “quoted”\n" {
+		t.Errorf("Log = %q", got)
+	}
+	if f.RevisionContents[0].Text != text {
+		t.Errorf("Text changed to %q", f.RevisionContents[0].Text)
+	}
+}
+
+func TestSwitchLineEnding_Binary(t *testing.T) {
+	f, err := ParseFile(strings.NewReader(nonUTF8TestMaster))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	text := f.RevisionContents[0].Text
+	f.SwitchLineEnding("\r\n")
+	if f.RevisionContents[0].Text != text {
+		t.Errorf("Text with a NUL byte changed to %q", f.RevisionContents[0].Text)
+	}
+	if got := f.RevisionContents[1].Text; got != "d1 1\r\na1 1\r\n\xff\xfe\r\n" {
+		t.Errorf("Text = %q", got)
+	}
+
+	f, err = ParseFile(strings.NewReader(nonUTF8TestMaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Expand = "b"
+	f.SwitchLineEnding("\r\n")
+	if got := f.RevisionContents[1].Text; got != "d1 1\na1 1\n\xff\xfe\n" {
+		t.Errorf("Text of a binary master changed to %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+// Generated by github.com/arran4/go-subcommand/cmd/gosubc
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"errors"
+	"github.com/arran4/golang-rcs/cmd"
+	"github.com/arran4/golang-rcs/internal/cli"
+)
+
+var _ Cmd = (*AccessListLs)(nil)
+
+type AccessListLs struct {
+	*AccessList
+	Flags         *flag.FlagSet
+	files         []string
+	SubCommands   map[string]Cmd
+	CommandAction func(c *AccessListLs) error
+}
+
+type UsageDataAccessListLs struct {
+	*AccessListLs
+	Recursive bool
+}
+
+func (c *AccessListLs) Usage() {
+	err := executeUsage(os.Stderr, "access_list_ls_usage.txt", UsageDataAccessListLs{c, false})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *AccessListLs) UsageRecursive() {
+	err := executeUsage(os.Stderr, "access_list_ls_usage.txt", UsageDataAccessListLs{c, true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *AccessListLs) Execute(args []string) error {
+	if len(args) > 0 {
+		if cmd, ok := c.SubCommands[args[0]]; ok {
+			return cmd.Execute(args[1:])
+		}
+	}
+	var remainingArgs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			remainingArgs = append(remainingArgs, args[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			name := arg
+			trimmedName := strings.TrimLeft(name, "-")
+			switch trimmedName {
+			case "help", "h":
+				c.Usage()
+				return nil
+			default:
+				return fmt.Errorf("unknown flag: %s", name)
+			}
+		} else {
+			remainingArgs = append(remainingArgs, arg)
+		}
+	}
+	// Handle vararg files
+	{
+		varArgStart := 0
+		if varArgStart > len(remainingArgs) {
+			varArgStart = len(remainingArgs)
+		}
+		varArgs := remainingArgs[varArgStart:]
+		c.files = varArgs
+	}
+
+	if c.CommandAction != nil {
+		if err := c.CommandAction(c); err != nil {
+			return fmt.Errorf("ls failed: %w", err)
+		}
+	} else {
+		c.Usage()
+	}
+
+	return nil
+}
+
+func (c *AccessList) NewAccessListLs() *AccessListLs {
+	set := flag.NewFlagSet("ls", flag.ContinueOnError)
+	v := &AccessListLs{
+		AccessList:  c,
+		Flags:       set,
+		SubCommands: make(map[string]Cmd),
+	}
+	set.Usage = v.Usage
+
+	v.CommandAction = func(c *AccessListLs) error {
+
+		err := cli.AccessListLs(c.files...)
+		if err != nil {
+			if errors.Is(err, cmd.ErrPrintHelp) {
+				c.Usage()
+				return nil
+			}
+			if errors.Is(err, cmd.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "Use '%s help' for more information.\n", os.Args[0])
+				return nil
+			}
+			if e, ok := err.(*cmd.ErrExitCode); ok {
+				return e
+			}
+			return fmt.Errorf("ls failed: %w", err)
+		}
+		return nil
+	}
+
+	v.SubCommands["help"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	v.SubCommands["usage"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	return v
+}
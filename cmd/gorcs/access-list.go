@@ -75,10 +75,18 @@ func (c *RootCmd) NewAccessList() *AccessList {
 	}
 	set.Usage = v.Usage
 
+	v.SubCommands["add"] = v.NewAccessListAdd()
+
 	v.SubCommands["append"] = v.NewAppend()
 
+	v.SubCommands["clear"] = v.NewAccessListClear()
+
 	v.SubCommands["copy"] = v.NewCopy()
 
+	v.SubCommands["ls"] = v.NewAccessListLs()
+
+	v.SubCommands["remove"] = v.NewAccessListRemove()
+
 	v.SubCommands["help"] = &InternalCommand{
 		Exec: func(args []string) error {
 			for _, arg := range args {
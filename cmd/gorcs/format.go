@@ -23,6 +23,8 @@ type Format struct {
 	force              bool
 	keepTruncatedYears bool
 	useMmap            bool
+	tolerant           bool
+	errorFormat        string
 	files              []string
 	SubCommands        map[string]Cmd
 	CommandAction      func(c *Format) error
@@ -116,6 +118,28 @@ func (c *Format) Execute(args []string) error {
 				} else {
 					c.useMmap = true
 				}
+
+			case "tolerant":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.tolerant = b
+				} else {
+					c.tolerant = true
+				}
+
+			case "errorFormat", "error-format":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.errorFormat = value
 			case "help", "h":
 				c.Usage()
 				return nil
@@ -164,11 +188,15 @@ func (c *RootCmd) NewFormat() *Format {
 	set.BoolVar(&v.keepTruncatedYears, "keep-truncated-years", false, "TODO: Add usage text")
 
 	set.BoolVar(&v.useMmap, "use-mmap", false, "TODO: Add usage text")
+
+	set.BoolVar(&v.tolerant, "tolerant", false, "Recover what can be parsed from damaged files, reporting what was dropped")
+
+	set.StringVar(&v.errorFormat, "error-format", "", "Report parse errors as text (compiler style) or json and go on with the other files")
 	set.Usage = v.Usage
 
 	v.CommandAction = func(c *Format) error {
 
-		err := cli.Format(c.output, c.force, c.keepTruncatedYears, c.useMmap, c.files...)
+		err := cli.Format(c.output, c.force, c.keepTruncatedYears, c.useMmap, c.tolerant, c.errorFormat, c.files...)
 		if err != nil {
 			if errors.Is(err, cmd.ErrPrintHelp) {
 				c.Usage()
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os"
+
+	"github.com/arran4/golang-rcs/internal/cli"
+)
+
+// init turns on the journal and takes the global flags, such as --dry-run,
+// off the command line before the generated root command parses it. It
+// lives outside the generated files so regenerating them keeps it. Under
+// go test it finds no global flags, and the journal stays off unless
+// GORCS_JOURNAL_DIR is set.
+func init() {
+	os.Args = cli.ApplyGlobalFlags(os.Args)
+}
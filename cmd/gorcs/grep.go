@@ -0,0 +1,263 @@
+// Generated by github.com/arran4/go-subcommand/cmd/gosubc
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"errors"
+	"github.com/arran4/golang-rcs/cmd"
+	"github.com/arran4/golang-rcs/internal/cli"
+)
+
+var _ Cmd = (*Grep)(nil)
+
+type Grep struct {
+	*RootCmd
+	Flags         *flag.FlagSet
+	pattern       string
+	filterStr     string
+	revisions     string
+	pickaxe       bool
+	ignoreCase    bool
+	output        string
+	force         bool
+	files         []string
+	SubCommands   map[string]Cmd
+	CommandAction func(c *Grep) error
+}
+
+type UsageDataGrep struct {
+	*Grep
+	Recursive bool
+}
+
+func (c *Grep) Usage() {
+	err := executeUsage(os.Stderr, "grep_usage.txt", UsageDataGrep{c, false})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *Grep) UsageRecursive() {
+	err := executeUsage(os.Stderr, "grep_usage.txt", UsageDataGrep{c, true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *Grep) Execute(args []string) error {
+	if len(args) > 0 {
+		if cmd, ok := c.SubCommands[args[0]]; ok {
+			return cmd.Execute(args[1:])
+		}
+	}
+	var remainingArgs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			remainingArgs = append(remainingArgs, args[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			name := arg
+			value := ""
+			hasValue := false
+			if strings.Contains(arg, "=") {
+				parts := strings.SplitN(arg, "=", 2)
+				name = parts[0]
+				value = parts[1]
+				hasValue = true
+			}
+			trimmedName := strings.TrimLeft(name, "-")
+			switch trimmedName {
+
+			case "pattern", "regexp", "e":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.pattern = value
+
+			case "filterStr", "filter", "F":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.filterStr = value
+
+			case "revisions", "r":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.revisions = value
+
+			case "pickaxe", "S":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.pickaxe = b
+				} else {
+					c.pickaxe = true
+				}
+
+			case "ignoreCase", "ignore-case", "i":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.ignoreCase = b
+				} else {
+					c.ignoreCase = true
+				}
+
+			case "output", "o":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.output = value
+
+			case "force", "f":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.force = b
+				} else {
+					c.force = true
+				}
+			case "help", "h":
+				c.Usage()
+				return nil
+			default:
+				return fmt.Errorf("unknown flag: %s", name)
+			}
+		} else {
+			remainingArgs = append(remainingArgs, arg)
+		}
+	}
+	// Handle vararg files
+	{
+		varArgStart := 0
+		if varArgStart > len(remainingArgs) {
+			varArgStart = len(remainingArgs)
+		}
+		varArgs := remainingArgs[varArgStart:]
+		c.files = varArgs
+	}
+
+	if c.CommandAction != nil {
+		if err := c.CommandAction(c); err != nil {
+			return fmt.Errorf("grep failed: %w", err)
+		}
+	} else {
+		c.Usage()
+	}
+
+	return nil
+}
+
+func (c *RootCmd) NewGrep() *Grep {
+	set := flag.NewFlagSet("grep", flag.ContinueOnError)
+	v := &Grep{
+		RootCmd:     c,
+		Flags:       set,
+		SubCommands: make(map[string]Cmd),
+	}
+
+	set.StringVar(&v.pattern, "regexp", "", "Regular expression to search for (default: the first argument)")
+	set.StringVar(&v.pattern, "e", "", "Regular expression to search for (default: the first argument)")
+
+	set.StringVar(&v.filterStr, "filter", "", "Only search revisions matching this filter (see gorcs log filter-reference)")
+	set.StringVar(&v.filterStr, "F", "", "Only search revisions matching this filter (see gorcs log filter-reference)")
+
+	set.StringVar(&v.revisions, "revisions", "", "Only search revisions in this range: rev, from:to, from: or :to")
+	set.StringVar(&v.revisions, "r", "", "Only search revisions in this range: rev, from:to, from: or :to")
+
+	set.BoolVar(&v.pickaxe, "pickaxe", false, "Report revisions where the number of matches changed instead of matching lines")
+	set.BoolVar(&v.pickaxe, "S", false, "Report revisions where the number of matches changed instead of matching lines")
+
+	set.BoolVar(&v.ignoreCase, "ignore-case", false, "Match case insensitively")
+	set.BoolVar(&v.ignoreCase, "i", false, "Match case insensitively")
+
+	set.StringVar(&v.output, "output", "", "Output file path")
+	set.StringVar(&v.output, "o", "", "Output file path")
+
+	set.BoolVar(&v.force, "force", false, "Force overwrite output")
+	set.BoolVar(&v.force, "f", false, "Force overwrite output")
+	set.Usage = v.Usage
+
+	v.CommandAction = func(c *Grep) error {
+
+		err := cli.Grep(c.pattern, c.filterStr, c.revisions, c.pickaxe, c.ignoreCase, c.output, c.force, c.files...)
+		if err != nil {
+			if errors.Is(err, cmd.ErrPrintHelp) {
+				c.Usage()
+				return nil
+			}
+			if errors.Is(err, cmd.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "Use '%s help' for more information.\n", os.Args[0])
+				return nil
+			}
+			if e, ok := err.(*cmd.ErrExitCode); ok {
+				return e
+			}
+			return fmt.Errorf("grep failed: %w", err)
+		}
+		return nil
+	}
+
+	v.SubCommands["help"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	v.SubCommands["usage"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	return v
+}
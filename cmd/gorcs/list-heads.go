@@ -20,6 +20,7 @@ type ListHeads struct {
 	*RootCmd
 	Flags         *flag.FlagSet
 	useMmap       bool
+	tolerant      bool
 	files         []string
 	SubCommands   map[string]Cmd
 	CommandAction func(c *ListHeads) error
@@ -80,6 +81,17 @@ func (c *ListHeads) Execute(args []string) error {
 				} else {
 					c.useMmap = true
 				}
+
+			case "tolerant":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.tolerant = b
+				} else {
+					c.tolerant = true
+				}
 			case "help", "h":
 				c.Usage()
 				return nil
@@ -120,11 +132,13 @@ func (c *RootCmd) NewListHeads() *ListHeads {
 	}
 
 	set.BoolVar(&v.useMmap, "use-mmap", false, "TODO: Add usage text")
+
+	set.BoolVar(&v.tolerant, "tolerant", false, "List what can be recovered from damaged files and keep going")
 	set.Usage = v.Usage
 
 	v.CommandAction = func(c *ListHeads) error {
 
-		err := cli.ListHeads(c.useMmap, c.files...)
+		err := cli.ListHeads(c.useMmap, c.tolerant, c.files...)
 		if err != nil {
 			if errors.Is(err, cmd.ErrPrintHelp) {
 				c.Usage()
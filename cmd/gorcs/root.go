@@ -72,14 +72,23 @@ func (c *RootCmd) UsageRecursive() {
 	c.PrintDefaults()
 	fmt.Fprintln(os.Stderr, "  Commands:")
 	fmt.Fprintf(os.Stderr, "    %s\n", "access-list")
+	fmt.Fprintf(os.Stderr, "    %s\n", "access-list add")
 	fmt.Fprintf(os.Stderr, "    %s\n", "access-list append")
+	fmt.Fprintf(os.Stderr, "    %s\n", "access-list clear")
 	fmt.Fprintf(os.Stderr, "    %s\n", "access-list copy")
+	fmt.Fprintf(os.Stderr, "    %s\n", "access-list ls")
+	fmt.Fprintf(os.Stderr, "    %s\n", "access-list remove")
 	fmt.Fprintf(os.Stderr, "    %s\n", "branches")
 	fmt.Fprintf(os.Stderr, "    %s\n", "branches default")
 	fmt.Fprintf(os.Stderr, "    %s\n", "branches default set")
+	fmt.Fprintf(os.Stderr, "    %s\n", "changesets")
+	fmt.Fprintf(os.Stderr, "    %s\n", "export")
+	fmt.Fprintf(os.Stderr, "    %s\n", "export svn-dump")
 	fmt.Fprintf(os.Stderr, "    %s\n", "format")
 	fmt.Fprintf(os.Stderr, "    %s\n", "from-json")
 	fmt.Fprintf(os.Stderr, "    %s\n", "from-markdown")
+	fmt.Fprintf(os.Stderr, "    %s\n", "graph")
+	fmt.Fprintf(os.Stderr, "    %s\n", "grep")
 	fmt.Fprintf(os.Stderr, "    %s\n", "init")
 	fmt.Fprintf(os.Stderr, "    %s\n", "list-heads")
 	fmt.Fprintf(os.Stderr, "    %s\n", "locks")
@@ -89,12 +98,21 @@ func (c *RootCmd) UsageRecursive() {
 	fmt.Fprintf(os.Stderr, "    %s\n", "log message list")
 	fmt.Fprintf(os.Stderr, "    %s\n", "log message print")
 	fmt.Fprintf(os.Stderr, "    %s\n", "normalize-revisions")
+	fmt.Fprintf(os.Stderr, "    %s\n", "serve")
 	fmt.Fprintf(os.Stderr, "    %s\n", "state")
 	fmt.Fprintf(os.Stderr, "    %s\n", "state alter")
 	fmt.Fprintf(os.Stderr, "    %s\n", "state get")
 	fmt.Fprintf(os.Stderr, "    %s\n", "state ls")
+	fmt.Fprintf(os.Stderr, "    %s\n", "stats")
+	fmt.Fprintf(os.Stderr, "    %s\n", "symbols")
+	fmt.Fprintf(os.Stderr, "    %s\n", "symbols add")
+	fmt.Fprintf(os.Stderr, "    %s\n", "symbols delete")
+	fmt.Fprintf(os.Stderr, "    %s\n", "symbols list")
+	fmt.Fprintf(os.Stderr, "    %s\n", "symbols move")
+	fmt.Fprintf(os.Stderr, "    %s\n", "to-html")
 	fmt.Fprintf(os.Stderr, "    %s\n", "to-json")
 	fmt.Fprintf(os.Stderr, "    %s\n", "to-markdown")
+	fmt.Fprintf(os.Stderr, "    %s\n", "undo")
 	fmt.Fprintf(os.Stderr, "    %s\n", "validate")
 }
 
@@ -110,17 +128,26 @@ func NewRoot(name, version, commit, date string) (*RootCmd, error) {
 
 	c.Commands["access-list"] = c.NewAccessList()
 	c.Commands["branches"] = c.NewBranches()
+	c.Commands["changesets"] = c.NewChangesets()
+	c.Commands["export"] = c.NewExport()
 	c.Commands["format"] = c.NewFormat()
 	c.Commands["from-json"] = c.NewFromJson()
 	c.Commands["from-markdown"] = c.NewFromMarkdown()
+	c.Commands["graph"] = c.NewGraph()
+	c.Commands["grep"] = c.NewGrep()
 	c.Commands["init"] = c.NewInit()
 	c.Commands["list-heads"] = c.NewListHeads()
 	c.Commands["locks"] = c.NewLocks()
 	c.Commands["log"] = c.NewLog()
 	c.Commands["normalize-revisions"] = c.NewNormalizeRevisions()
+	c.Commands["serve"] = c.NewServe()
 	c.Commands["state"] = c.NewState()
+	c.Commands["stats"] = c.NewStats()
+	c.Commands["symbols"] = c.NewSymbols()
+	c.Commands["to-html"] = c.NewToHtml()
 	c.Commands["to-json"] = c.NewToJson()
 	c.Commands["to-markdown"] = c.NewToMarkdown()
+	c.Commands["undo"] = c.NewUndo()
 	c.Commands["validate"] = c.NewValidate()
 	c.Commands["help"] = &InternalCommand{
 		Exec: func(args []string) error {
@@ -0,0 +1,231 @@
+// Generated by github.com/arran4/go-subcommand/cmd/gosubc
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"errors"
+	"github.com/arran4/golang-rcs/cmd"
+	"github.com/arran4/golang-rcs/internal/cli"
+)
+
+var _ Cmd = (*SymbolsAdd)(nil)
+
+type SymbolsAdd struct {
+	*Symbols
+	Flags         *flag.FlagSet
+	name          string
+	revision      string
+	date          string
+	zone          string
+	force         bool
+	files         []string
+	SubCommands   map[string]Cmd
+	CommandAction func(c *SymbolsAdd) error
+}
+
+type UsageDataSymbolsAdd struct {
+	*SymbolsAdd
+	Recursive bool
+}
+
+func (c *SymbolsAdd) Usage() {
+	err := executeUsage(os.Stderr, "symbols_add_usage.txt", UsageDataSymbolsAdd{c, false})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *SymbolsAdd) UsageRecursive() {
+	err := executeUsage(os.Stderr, "symbols_add_usage.txt", UsageDataSymbolsAdd{c, true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *SymbolsAdd) Execute(args []string) error {
+	if len(args) > 0 {
+		if cmd, ok := c.SubCommands[args[0]]; ok {
+			return cmd.Execute(args[1:])
+		}
+	}
+	var remainingArgs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			remainingArgs = append(remainingArgs, args[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			name := arg
+			value := ""
+			hasValue := false
+			if strings.Contains(arg, "=") {
+				parts := strings.SplitN(arg, "=", 2)
+				name = parts[0]
+				value = parts[1]
+				hasValue = true
+			}
+			trimmedName := strings.TrimLeft(name, "-")
+			switch trimmedName {
+
+			case "name":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.name = value
+
+			case "revision", "rev":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.revision = value
+
+			case "date", "d":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.date = value
+
+			case "zone", "z":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.zone = value
+
+			case "force", "f":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.force = b
+				} else {
+					c.force = true
+				}
+			case "help", "h":
+				c.Usage()
+				return nil
+			default:
+				return fmt.Errorf("unknown flag: %s", name)
+			}
+		} else {
+			remainingArgs = append(remainingArgs, arg)
+		}
+	}
+	// Handle vararg files
+	{
+		varArgStart := 0
+		if varArgStart > len(remainingArgs) {
+			varArgStart = len(remainingArgs)
+		}
+		varArgs := remainingArgs[varArgStart:]
+		c.files = varArgs
+	}
+
+	if c.CommandAction != nil {
+		if err := c.CommandAction(c); err != nil {
+			return fmt.Errorf("add failed: %w", err)
+		}
+	} else {
+		c.Usage()
+	}
+
+	return nil
+}
+
+func (c *Symbols) NewSymbolsAdd() *SymbolsAdd {
+	set := flag.NewFlagSet("add", flag.ContinueOnError)
+	v := &SymbolsAdd{
+		Symbols:     c,
+		Flags:       set,
+		SubCommands: make(map[string]Cmd),
+	}
+
+	set.StringVar(&v.name, "name", "", "Symbolic name to assign")
+
+	set.StringVar(&v.revision, "rev", "", "Revision or branch to tag (defaults to head)")
+
+	set.StringVar(&v.date, "date", "", "Tag the revision current at this date")
+	set.StringVar(&v.date, "d", "", "Tag the revision current at this date")
+
+	set.StringVar(&v.zone, "zone", "", "Zone for date parsing (e.g. LT, UTC, -0700)")
+	set.StringVar(&v.zone, "z", "", "Zone for date parsing (e.g. LT, UTC, -0700)")
+
+	set.BoolVar(&v.force, "force", false, "Move the symbol if it is already bound to another revision")
+	set.BoolVar(&v.force, "f", false, "Move the symbol if it is already bound to another revision")
+	set.Usage = v.Usage
+
+	v.CommandAction = func(c *SymbolsAdd) error {
+
+		err := cli.SymbolsAdd(c.name, c.revision, c.date, c.zone, c.force, c.files...)
+		if err != nil {
+			if errors.Is(err, cmd.ErrPrintHelp) {
+				c.Usage()
+				return nil
+			}
+			if errors.Is(err, cmd.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "Use '%s help' for more information.\n", os.Args[0])
+				return nil
+			}
+			if e, ok := err.(*cmd.ErrExitCode); ok {
+				return e
+			}
+			return fmt.Errorf("add failed: %w", err)
+		}
+		return nil
+	}
+
+	v.SubCommands["help"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	v.SubCommands["usage"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	return v
+}
@@ -0,0 +1,29 @@
+// Generated by github.com/arran4/go-subcommand/cmd/gosubc
+
+package main
+
+import (
+	"testing"
+)
+
+func TestSymbolsMove_Execute(t *testing.T) {
+
+	parent := &Symbols{}
+	cmd := parent.NewSymbolsMove()
+
+	called := false
+	cmd.CommandAction = func(c *SymbolsMove) error {
+		called = true
+		return nil
+	}
+
+	args := []string{}
+
+	err := cmd.Execute(args)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("CommandAction was not called")
+	}
+}
@@ -0,0 +1,113 @@
+// Generated by github.com/arran4/go-subcommand/cmd/gosubc
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var _ Cmd = (*Symbols)(nil)
+
+type Symbols struct {
+	*RootCmd
+	Flags         *flag.FlagSet
+	SubCommands   map[string]Cmd
+	CommandAction func(c *Symbols) error
+}
+
+type UsageDataSymbols struct {
+	*Symbols
+	Recursive bool
+}
+
+func (c *Symbols) Usage() {
+	err := executeUsage(os.Stderr, "symbols_usage.txt", UsageDataSymbols{c, false})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *Symbols) UsageRecursive() {
+	err := executeUsage(os.Stderr, "symbols_usage.txt", UsageDataSymbols{c, true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating usage: %s\n", err)
+	}
+}
+
+func (c *Symbols) Execute(args []string) error {
+	if len(args) > 0 {
+		if cmd, ok := c.SubCommands[args[0]]; ok {
+			return cmd.Execute(args[1:])
+		}
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			name := arg
+			trimmedName := strings.TrimLeft(name, "-")
+			switch trimmedName {
+			case "help", "h":
+				c.Usage()
+				return nil
+			default:
+				return fmt.Errorf("unknown flag: %s", name)
+			}
+		}
+	}
+
+	c.Usage()
+
+	return nil
+}
+
+func (c *RootCmd) NewSymbols() *Symbols {
+	set := flag.NewFlagSet("symbols", flag.ContinueOnError)
+	v := &Symbols{
+		RootCmd:     c,
+		Flags:       set,
+		SubCommands: make(map[string]Cmd),
+	}
+	set.Usage = v.Usage
+
+	v.SubCommands["add"] = v.NewSymbolsAdd()
+
+	v.SubCommands["delete"] = v.NewSymbolsDelete()
+
+	v.SubCommands["list"] = v.NewSymbolsList()
+
+	v.SubCommands["move"] = v.NewSymbolsMove()
+
+	v.SubCommands["help"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	v.SubCommands["usage"] = &InternalCommand{
+		Exec: func(args []string) error {
+			for _, arg := range args {
+				if arg == "-deep" {
+					v.UsageRecursive()
+					return nil
+				}
+			}
+			v.Usage()
+			return nil
+		},
+		UsageFunc: v.Usage,
+	}
+	return v
+}
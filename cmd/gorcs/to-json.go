@@ -23,6 +23,11 @@ type ToJson struct {
 	force         bool
 	indent        bool
 	useMmap       bool
+	text          bool
+	deltas        bool
+	links         bool
+	stats         bool
+	transcode     string
 	files         []string
 	SubCommands   map[string]Cmd
 	CommandAction func(c *ToJson) error
@@ -116,6 +121,61 @@ func (c *ToJson) Execute(args []string) error {
 				} else {
 					c.useMmap = true
 				}
+
+			case "text":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.text = b
+				} else {
+					c.text = true
+				}
+
+			case "deltas":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.deltas = b
+				} else {
+					c.deltas = true
+				}
+
+			case "links":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.links = b
+				} else {
+					c.links = true
+				}
+
+			case "stats":
+				if hasValue {
+					b, err := strconv.ParseBool(value)
+					if err != nil {
+						return fmt.Errorf("invalid boolean value for flag %s: %s", name, value)
+					}
+					c.stats = b
+				} else {
+					c.stats = true
+				}
+
+			case "transcode":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.transcode = value
 			case "help", "h":
 				c.Usage()
 				return nil
@@ -165,11 +225,21 @@ func (c *RootCmd) NewToJson() *ToJson {
 	set.BoolVar(&v.indent, "I", false, "Indent JSON output")
 
 	set.BoolVar(&v.useMmap, "use-mmap", false, "TODO: Add usage text")
+
+	set.BoolVar(&v.text, "text", false, "Include the full text of every revision")
+
+	set.BoolVar(&v.deltas, "deltas", false, "Include every stored delta parsed into add and delete commands")
+
+	set.BoolVar(&v.links, "links", false, "Include the parent and children of every revision")
+
+	set.BoolVar(&v.stats, "stats", false, "Include line counts and lines added and deleted per revision")
+
+	set.StringVar(&v.transcode, "transcode", "", "Convert authors and log messages from this charset (latin1 or cp1252) to UTF-8")
 	set.Usage = v.Usage
 
 	v.CommandAction = func(c *ToJson) error {
 
-		err := cli.ToJson(c.output, c.force, c.indent, c.useMmap, c.files...)
+		err := cli.ToJson(c.output, c.force, c.indent, c.useMmap, c.text, c.deltas, c.links, c.stats, c.transcode, c.files...)
 		if err != nil {
 			if errors.Is(err, cmd.ErrPrintHelp) {
 				c.Usage()
@@ -21,6 +21,9 @@ type ToMarkdown struct {
 	Flags         *flag.FlagSet
 	output        string
 	force         bool
+	variant       string
+	templateFile  string
+	transcode     string
 	files         []string
 	SubCommands   map[string]Cmd
 	CommandAction func(c *ToMarkdown) error
@@ -92,6 +95,38 @@ func (c *ToMarkdown) Execute(args []string) error {
 				} else {
 					c.force = true
 				}
+
+			case "variant":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.variant = value
+
+			case "template", "t":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.templateFile = value
+			case "transcode":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.transcode = value
 			case "help", "h":
 				c.Usage()
 				return nil
@@ -136,11 +171,18 @@ func (c *RootCmd) NewToMarkdown() *ToMarkdown {
 
 	set.BoolVar(&v.force, "force", false, "Force overwrite output")
 	set.BoolVar(&v.force, "f", false, "Force overwrite output")
+
+	set.StringVar(&v.variant, "variant", "", "Built-in layout: default, text (full text of each revision) or diff (unified diff against the parent)")
+
+	set.StringVar(&v.templateFile, "template", "", "Template file parsed over the chosen layout")
+	set.StringVar(&v.templateFile, "t", "", "Template file parsed over the chosen layout")
+
+	set.StringVar(&v.transcode, "transcode", "", "Convert authors and log messages from this charset (latin1 or cp1252) to UTF-8")
 	set.Usage = v.Usage
 
 	v.CommandAction = func(c *ToMarkdown) error {
 
-		err := cli.ToMarkdown(c.output, c.force, c.files...)
+		err := cli.ToMarkdown(c.output, c.force, c.variant, c.templateFile, c.transcode, c.files...)
 		if err != nil {
 			if errors.Is(err, cmd.ErrPrintHelp) {
 				c.Usage()
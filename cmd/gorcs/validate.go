@@ -22,6 +22,7 @@ type Validate struct {
 	output        string
 	force         bool
 	useMmap       bool
+	errorFormat   string
 	files         []string
 	SubCommands   map[string]Cmd
 	CommandAction func(c *Validate) error
@@ -104,6 +105,17 @@ func (c *Validate) Execute(args []string) error {
 				} else {
 					c.useMmap = true
 				}
+
+			case "errorFormat", "error-format":
+				if !hasValue {
+					if i+1 < len(args) {
+						value = args[i+1]
+						i++
+					} else {
+						return fmt.Errorf("flag %s requires a value", name)
+					}
+				}
+				c.errorFormat = value
 			case "help", "h":
 				c.Usage()
 				return nil
@@ -150,11 +162,13 @@ func (c *RootCmd) NewValidate() *Validate {
 	set.BoolVar(&v.force, "f", false, "Force overwrite output")
 
 	set.BoolVar(&v.useMmap, "use-mmap", false, "TODO: Add usage text")
+
+	set.StringVar(&v.errorFormat, "error-format", "", "Report parse errors as text (compiler style) or json and go on with the other files")
 	set.Usage = v.Usage
 
 	v.CommandAction = func(c *Validate) error {
 
-		err := cli.Validate(c.output, c.force, c.useMmap, c.files...)
+		err := cli.Validate(c.output, c.force, c.useMmap, c.errorFormat, c.files...)
 		if err != nil {
 			if errors.Is(err, cmd.ErrPrintHelp) {
 				c.Usage()
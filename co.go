@@ -2,7 +2,6 @@ package rcs
 
 import (
 	"fmt"
-	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -72,11 +71,7 @@ func (file *File) Checkout(user string, ops ...any) (*COVerdict, error) {
 	}
 
 	if !targetDate.IsZero() {
-		defaultZone := time.UTC
-		if targetLocation != nil {
-			defaultZone = targetLocation
-		}
-		resolved, err := file.resolveRevisionByDate(revision, targetDate, defaultZone)
+		resolved, err := file.ResolveRevisionAtDate(revision, targetDate, targetLocation)
 		if err != nil {
 			return nil, err
 		}
@@ -115,64 +110,6 @@ func (file *File) Checkout(user string, ops ...any) (*COVerdict, error) {
 	return v, nil
 }
 
-func (file *File) resolveRevisionByDate(startRev string, targetDate time.Time, defaultZone *time.Location) (string, error) {
-	rhByRevision := map[string]*RevisionHead{}
-	for _, rh := range file.RevisionHeads {
-		rhByRevision[rh.Revision.String()] = rh
-	}
-
-	currentRev := startRev
-	// Collect all revisions in the chain starting from currentRev
-	var chain []*RevisionHead
-	visited := map[string]bool{}
-
-	for {
-		if visited[currentRev] {
-			return "", fmt.Errorf("loop detected while resolving date for %q", startRev)
-		}
-		visited[currentRev] = true
-
-		rh, ok := rhByRevision[currentRev]
-		if !ok {
-			return "", fmt.Errorf("revision %q not found", currentRev)
-		}
-		chain = append(chain, rh)
-
-		currentRev = rh.NextRevision.String()
-		if currentRev == "" {
-			break
-		}
-	}
-
-	// Find the most recent revision by timestamp that is <= targetDate.
-	var bestRev string
-	var bestTime time.Time
-
-	for _, rh := range chain {
-		// Parse date (RCS file dates are UTC usually, or whatever is stored)
-		// We use ParseDate which handles formats.
-		t, err := ParseDate(string(rh.Date), time.Time{}, defaultZone)
-		if err != nil {
-			// If date is unparsable, we skip it? Or fail?
-			// Fail is safer.
-			return "", fmt.Errorf("invalid date in revision %q: %w", rh.Revision, err)
-		}
-
-		if t.After(targetDate) {
-			continue
-		}
-		if bestRev == "" || t.After(bestTime) || (t.Equal(bestTime) && compareRevisions(rh.Revision.String(), bestRev) > 0) {
-			bestRev = rh.Revision.String()
-			bestTime = t
-		}
-	}
-
-	if bestRev == "" {
-		return "", fmt.Errorf("no revision found on or before %v starting from %q", targetDate, startRev)
-	}
-	return bestRev, nil
-}
-
 // compareRevisions compares two revision strings (e.g. "1.2" vs "1.10").
 // Returns 1 if a > b, -1 if a < b, 0 if a == b.
 func compareRevisions(a, b string) int {
@@ -220,6 +157,10 @@ func (file *File) resolveRevisionContent(targetRevision string) (string, error)
 		rcByRevision[rc.Revision] = rc
 	}
 
+	if !IsTrunkRevision(targetRevision) {
+		return file.resolveBranchRevisionContent(targetRevision, rhByRevision, rcByRevision)
+	}
+
 	headContent, ok := rcByRevision[head]
 	if !ok {
 		return "", fmt.Errorf("head revision %q content not found", head)
@@ -264,17 +205,63 @@ func (file *File) resolveRevisionContent(targetRevision string) (string, error)
 	}
 }
 
-func applyDelta(from, delta string) (string, error) {
-	ed, err := diff.ParseEdDiff(strings.NewReader(delta))
+// resolveBranchRevisionContent reconstructs a branch revision by resolving
+// its branch point and then applying the forward deltas along the branch.
+func (file *File) resolveBranchRevisionContent(targetRevision string, rhByRevision map[string]*RevisionHead, rcByRevision map[string]*RevisionContent) (string, error) {
+	branchPoint := BranchPoint(targetRevision)
+	branch := BranchNumber(targetRevision)
+	bp, ok := rhByRevision[branchPoint]
+	if !ok {
+		return "", fmt.Errorf("branch point %q of %q not found", branchPoint, targetRevision)
+	}
+	current := ""
+	for _, b := range bp.Branches {
+		if BranchNumber(b.String()) == branch {
+			current = b.String()
+			break
+		}
+	}
+	if current == "" {
+		return "", fmt.Errorf("branch %q not found at %q", branch, branchPoint)
+	}
+
+	content, err := file.resolveRevisionContent(branchPoint)
 	if err != nil {
 		return "", err
 	}
-	r := &lineReader{lines: splitLines(from)}
-	w := &lineWriter{}
-	if err := ed.Apply(r, w); err != nil {
-		return "", err
+	visited := map[string]bool{}
+	for {
+		if visited[current] {
+			return "", fmt.Errorf("loop detected while resolving revision %q", targetRevision)
+		}
+		visited[current] = true
+		rc, ok := rcByRevision[current]
+		if !ok {
+			return "", fmt.Errorf("revision content %q not found", current)
+		}
+		content, err = applyDelta(content, rc.Text)
+		if err != nil {
+			return "", fmt.Errorf("apply delta for %q: %w", current, err)
+		}
+		if current == targetRevision {
+			return content, nil
+		}
+		rh, ok := rhByRevision[current]
+		if !ok {
+			return "", fmt.Errorf("revision header %q not found", current)
+		}
+		current = rh.NextRevision.String()
+		if current == "" {
+			return "", fmt.Errorf("revision %q not reachable on branch %q", targetRevision, branch)
+		}
 	}
-	return strings.Join(w.lines, "\n") + trailingNewline(from), nil
+}
+
+// applyDelta applies an RCS delta to from byte for byte. The result ends
+// with a newline only when the delta leaves one there, which is how RCS
+// stores revisions whose last line has none and binary (expand b) files.
+func applyDelta(from, delta string) (string, error) {
+	return diff.ApplyDelta(from, delta)
 }
 
 func splitLines(s string) []string {
@@ -288,36 +275,6 @@ func splitLines(s string) []string {
 	return lines
 }
 
-func trailingNewline(s string) string {
-	if strings.HasSuffix(s, "\n") {
-		return "\n"
-	}
-	return ""
-}
-
-type lineReader struct {
-	lines []string
-	idx   int
-}
-
-func (r *lineReader) ReadLine() (string, error) {
-	if r.idx >= len(r.lines) {
-		return "", io.EOF
-	}
-	line := r.lines[r.idx]
-	r.idx++
-	return line, nil
-}
-
-type lineWriter struct {
-	lines []string
-}
-
-func (w *lineWriter) WriteLine(line string) error {
-	w.lines = append(w.lines, line)
-	return nil
-}
-
 func (file *File) SetLock(user, revision string) bool {
 	for _, l := range file.Locks {
 		if l.User == user {
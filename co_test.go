@@ -3,10 +3,15 @@ package rcs
 import (
 	"bytes"
 	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"io/fs"
+	"path"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/arran4/golang-rcs/diff"
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/tools/txtar"
 )
@@ -14,6 +19,9 @@ import (
 //go:embed testdata/co/co-api-checkout-lock.txtar
 var coTests embed.FS
 
+//go:embed testdata/co/gnu-*.txtar
+var gnuMasters embed.FS
+
 func TestCheckout(t *testing.T) {
 	b, err := coTests.ReadFile("testdata/co/co-api-checkout-lock.txtar")
 	if err != nil {
@@ -159,9 +167,192 @@ func TestCheckout_WithDatePrefersMostRecentTimestampOverRevisionNumber(t *testin
 	}
 }
 
+func TestCheckout_WithDateOnBranch(t *testing.T) {
+	f := revisionTreeTestFile()
+	f.RevisionContents = []*RevisionContent{
+		{Revision: "1.3", Text: "C\n"},
+		{Revision: "1.2", Text: "d1 1\na1 1\nB\n"},
+		{Revision: "1.1", Text: "d1 1\na1 1\nA\n"},
+		{Revision: "1.2.2.1", Text: "d1 1\na1 1\nB1\n"},
+		{Revision: "1.2.2.2", Text: "d1 1\na1 1\nB2\n"},
+	}
+	for _, tt := range []struct {
+		date        time.Time
+		wantRev     string
+		wantContent string
+	}{
+		{time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC), "1.2.2.1", "B1\n"},
+		{time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), "1.2", "B\n"},
+	} {
+		verdict, err := f.Checkout("user", WithRevision("BR"), WithDate(tt.date))
+		if err != nil {
+			t.Fatalf("Checkout(BR, %v) error = %v", tt.date, err)
+		}
+		if verdict.Revision != tt.wantRev || verdict.Content != tt.wantContent {
+			t.Errorf("Checkout(BR, %v) = %s %q, want %s %q", tt.date, verdict.Revision, verdict.Content, tt.wantRev, tt.wantContent)
+		}
+	}
+}
+
 func TestCheckout_UnknownOption(t *testing.T) {
 	_, err := NewFile().Checkout("tester", struct{}{})
 	if err == nil {
 		t.Fatal("Checkout() error = nil, want error")
 	}
 }
+
+func TestCheckout_BranchRevision(t *testing.T) {
+	f := &File{
+		Head: "1.2",
+		RevisionHeads: []*RevisionHead{
+			{Revision: "1.2", Date: "2020.01.02.00.00.00", NextRevision: "1.1"},
+			{Revision: "1.1", Date: "2020.01.01.00.00.00", Branches: []Num{"1.1.1.1"}},
+			{Revision: "1.1.1.1", Date: "2020.01.03.00.00.00", NextRevision: "1.1.1.2"},
+			{Revision: "1.1.1.2", Date: "2020.01.04.00.00.00"},
+		},
+		RevisionContents: []*RevisionContent{
+			{Revision: "1.2", Text: "a\nB\n"},
+			{Revision: "1.1", Text: "d2 1\na2 1\nb\n"},
+			{Revision: "1.1.1.1", Text: "a2 1\nc\n"},
+			{Revision: "1.1.1.2", Text: "d1 1\na1 1\nA\n"},
+		},
+	}
+	for rev, want := range map[string]string{
+		"1.1":     "a\nb\n",
+		"1.1.1.1": "a\nb\nc\n",
+		"1.1.1.2": "A\nb\nc\n",
+	} {
+		verdict, err := f.Checkout("user", WithRevision(rev))
+		if err != nil {
+			t.Fatalf("Checkout(%s) error = %v", rev, err)
+		}
+		if verdict.Content != want {
+			t.Errorf("Checkout(%s) content = %q, want %q", rev, verdict.Content, want)
+		}
+	}
+	if _, err := f.Checkout("user", WithRevision("1.1.2.1")); err == nil {
+		t.Error("Checkout(1.1.2.1) error = nil, want error")
+	}
+}
+
+func TestCheckout_ExactBytes(t *testing.T) {
+	texts := map[string]string{
+		"1.3":     "one\ntwo",
+		"1.2":     "one\ntwo\n",
+		"1.1":     "\x00\r\n\xff\xfe",
+		"1.2.1.1": "one\ntwo\nthree",
+	}
+	delta := func(from, to string) string {
+		d, err := diff.GenerateDelta(texts[from], texts[to])
+		if err != nil {
+			t.Fatalf("GenerateDelta(%s, %s) error = %v", from, to, err)
+		}
+		return d
+	}
+	f := NewFile()
+	f.Head = "1.3"
+	f.Expand = "b"
+	f.RevisionHeads = []*RevisionHead{
+		{Revision: "1.3", Date: "2021.03.05.05.06.07", NextRevision: "1.2"},
+		{Revision: "1.2", Date: "2021.03.04.05.06.07", NextRevision: "1.1", Branches: []Num{"1.2.1.1"}},
+		{Revision: "1.1", Date: "2021.03.03.05.06.07"},
+		{Revision: "1.2.1.1", Date: "2021.03.06.05.06.07"},
+	}
+	f.RevisionContents = []*RevisionContent{
+		{Revision: "1.3", Text: texts["1.3"]},
+		{Revision: "1.2", Text: delta("1.3", "1.2")},
+		{Revision: "1.1", Text: delta("1.2", "1.1")},
+		{Revision: "1.2.1.1", Text: delta("1.2", "1.2.1.1")},
+	}
+	parsed, err := ParseFile(strings.NewReader(f.String()))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	for rev, want := range texts {
+		v, err := parsed.Checkout("", WithRevision(rev))
+		if err != nil {
+			t.Fatalf("Checkout(%s) error = %v", rev, err)
+		}
+		if v.Content != want {
+			t.Errorf("Checkout(%s) = %q, want %q", rev, v.Content, want)
+		}
+	}
+}
+
+// TestCheckout_GNUMasters checks Checkout and GenerateDelta against masters
+// written by GNU ci rather than by this package.
+func TestCheckout_GNUMasters(t *testing.T) {
+	names, err := fs.Glob(gnuMasters, "testdata/co/gnu-*.txtar")
+	if err != nil || len(names) == 0 {
+		t.Fatalf("no GNU masters: %v", err)
+	}
+	for _, name := range names {
+		t.Run(path.Base(name), func(t *testing.T) {
+			b, err := gnuMasters.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts := map[string][]byte{}
+			for _, f := range txtar.Parse(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))).Files {
+				parts[f.Name] = f.Data
+			}
+			master, ok := parts["input.txt,v"]
+			if enc, encoded := parts["input.txt,v.b64"]; encoded {
+				if master, err = base64.StdEncoding.DecodeString(string(enc)); err != nil {
+					t.Fatalf("decode master: %v", err)
+				}
+			} else if !ok {
+				t.Fatal("missing input.txt,v")
+			}
+			var want map[string]string
+			if err := json.Unmarshal(parts["expected.json"], &want); err != nil {
+				t.Fatalf("expected.json: %v", err)
+			}
+
+			f, err := ParseFile(bytes.NewReader(master))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+			for rev, text := range want {
+				v, err := f.Checkout("", WithRevision(rev))
+				if err != nil {
+					t.Fatalf("Checkout(%s) error = %v", rev, err)
+				}
+				if v.Content != text {
+					t.Errorf("Checkout(%s) = %q, want %q", rev, v.Content, text)
+				}
+			}
+
+			// The deltas we generate are the ones GNU ci stored, and they
+			// apply to the texts GNU ci stored.
+			stored := map[string]string{}
+			for _, rc := range f.RevisionContents {
+				stored[rc.Revision] = rc.Text
+			}
+			for _, rh := range f.RevisionHeads {
+				newer, older := rh.Revision.String(), rh.NextRevision.String()
+				if older == "" {
+					continue
+				}
+				d, err := diff.GenerateDelta(want[newer], want[older])
+				if err != nil {
+					t.Fatalf("GenerateDelta(%s, %s) error = %v", newer, older, err)
+				}
+				if d != stored[older] {
+					t.Errorf("GenerateDelta(%s, %s) = %q, GNU stored %q", newer, older, d, stored[older])
+				}
+				base := stored[newer]
+				if newer != f.Head {
+					base = want[newer]
+				}
+				got, err := diff.ApplyDelta(base, d)
+				if err != nil {
+					t.Fatalf("ApplyDelta(%s) error = %v", older, err)
+				}
+				if got != want[older] {
+					t.Errorf("ApplyDelta(%s) = %q, want %q", older, got, want[older])
+				}
+			}
+		})
+	}
+}
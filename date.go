@@ -2,17 +2,16 @@ package rcs
 
 import (
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
 const DateFormatTruncated = "06.01.02.15.04.05"
 
-// ParseDate parses a date string according to RCS date formats.
-// It accepts a reference time 'now' to fill in missing fields.
+// ParseDate parses a date string using the free-form getdate grammar GNU RCS
+// accepts for co -d and rlog -d (see getdate.go), which includes the RCS
+// file format itself. It accepts a reference time 'now' to fill in missing
+// fields and to resolve relative items such as "2 days ago".
 // If 'now' is zero, it uses time.Now().
 // If 'defaultZone' is nil, it uses UTC.
 func ParseDate(input string, now time.Time, defaultZone *time.Location) (time.Time, error) {
@@ -24,47 +23,53 @@ func ParseDate(input string, now time.Time, defaultZone *time.Location) (time.Ti
 	}
 
 	input = strings.TrimSpace(input)
+	items, err := parseDateItems(input)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s: %v", ErrDateParse, input, err)
+	}
 
-	// Handle LT (Local Time)
 	// RCS doc says "The special value ‘LT’ stands for the “local time zone”."
 	// We'll interpret this as forcing the use of time.Local (or the system's local zone).
-	forceLocal := false
-	if strings.HasSuffix(strings.ToLower(input), " lt") {
-		forceLocal = true
-		input = input[:len(input)-3]
-		input = strings.TrimSpace(input)
-	} else if strings.HasSuffix(strings.ToLower(input), "lt") {
-		// handle case where no space? Documentation examples always have space or comma.
-		// "8:00 pm lt"
-		// "Thu Jan 11 20:00:00 1990 LT"
-		forceLocal = true
-		input = input[:len(input)-2]
-		input = strings.TrimSpace(input)
-	}
-
 	targetZone := defaultZone
-	if forceLocal {
+	if items.local {
 		targetZone = time.Local
 	}
-
-	// Try specific regex formats first (YEAR-DOY, YEAR-wWEEK-DOW)
-	if t, ok := parseYearDOY(input, targetZone); ok {
-		return t, nil
-	}
-	if t, ok := parseYearWeekDow(input, targetZone); ok {
-		return t, nil
+	zone := targetZone
+	if items.zone != nil {
+		zone = items.zone
 	}
 
-	// Try standard layouts
-	for _, layout := range dateLayouts {
-		// Try parsing in targetZone
-		t, err := time.ParseInLocation(layout.layout, input, targetZone)
-		if err == nil {
-			return applyDefaults(t, now, layout.fields, targetZone), nil
+	var t time.Time
+	switch {
+	case items.fields&^FieldZone != 0:
+		t = time.Date(items.year, time.Month(max(items.month, 1)), max(items.day, 1), items.hour, items.minute, items.second, 0, zone)
+		t = applyDefaults(t, now, items.fields, targetZone)
+		// time.Date normalises February 30 into March; getdate rejects it.
+		if items.fields&FieldMonth != 0 && int(t.Month()) != items.month || items.fields&FieldDay != 0 && t.Day() != items.day {
+			return time.Time{}, fmt.Errorf("%w: %s: day out of range for month", ErrDateParse, input)
+		}
+		t = t.Add(time.Duration(items.nsec))
+	case items.days > 0:
+		// A day of the week on its own means midnight.
+		y, m, d := now.In(zone).Date()
+		t = time.Date(y, m, d, 0, 0, 0, 0, zone)
+	default:
+		t = now.In(zone)
+	}
+
+	if items.days > 0 && items.dates == 0 {
+		wd, target := int(t.Weekday()), int(items.weekday)
+		ordinal := items.dayOrdinal
+		if ordinal > 0 && wd != target {
+			ordinal--
 		}
+		t = t.AddDate(0, 0, (target-wd+7)%7+7*ordinal)
 	}
-
-	return time.Time{}, fmt.Errorf("%w: %s", ErrDateParse, input)
+	if items.rels {
+		t = t.AddDate(items.relYear, items.relMonth, items.relDay)
+		t = t.Add(time.Duration(items.relHour)*time.Hour + time.Duration(items.relMinute)*time.Minute + time.Duration(items.relSecond)*time.Second)
+	}
+	return t, nil
 }
 
 const (
@@ -77,62 +82,6 @@ const (
 	FieldZone
 )
 
-type dateLayout struct {
-	layout string
-	fields int
-}
-
-var dateLayouts = []dateLayout{
-	// ISO 8601 variations
-	{"2006-01-02 15:04:05-07", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-	{"2006-01-02 15:04:05-0700", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-	{"2006-01-02 15:04:05", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond},
-
-	// Traditional RCS format
-	{"2006/01/02 15:04:05", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond},
-
-	// RFC 1123 / RFC 822
-	{time.RFC1123Z, FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-	{time.RFC1123, FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-	{time.RFC822Z, FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-	{time.RFC822, FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-	{"Mon, 2 Jan 2006 15:04:05 -0700", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-
-	// ctime style
-	{time.ANSIC, FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond}, // "Mon Jan _2 15:04:05 2006"
-
-	// "Thu Jan 11 20:00:00 PST 1990" - Unix date command
-	{time.UnixDate, FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone}, // "Mon Jan _2 15:04:05 MST 2006"
-
-	// Flexible formats
-	{"3:04 pm", FieldHour | FieldMinute},
-	{"3:04 PM", FieldHour | FieldMinute},
-	{"15:04", FieldHour | FieldMinute},
-	{"3:04 AM, Jan. 2, 2006", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute},
-	{"3:04 AM, Jan. 02, 2006", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute},
-
-	// "20, 10:30"
-	{"02, 15:04", FieldDay | FieldHour | FieldMinute},
-	{"2, 15:04", FieldDay | FieldHour | FieldMinute},
-
-	// "12-January-1990, 04:00"
-	{"02-January-2006, 15:04", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute},
-	{"2-January-2006, 15:04", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute},
-
-	// With Zone (MST) - explicit layouts because time.Parse with MST requires it to be known or handled?
-	// Actually ParseInLocation handles known abbreviations for that location, but arbitrary ones like WET might fail if not known.
-	// We will trust time.Parse to do its best.
-	{"02-January-2006, 15:04 MST", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldZone},
-	{"2-January-2006, 15:04 MST", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldZone},
-
-	// date(1) variations
-	{"Fri Jan 02 15:04:05 MST 2006", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-	{"Fri Jan 2 15:04:05 MST 2006", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond | FieldZone},
-
-	{"2006.01.02.15.04.05", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond},
-	{"06.01.02.15.04.05", FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond},
-}
-
 func applyDefaults(t time.Time, now time.Time, fields int, targetZone *time.Location) time.Time {
 	// Identify highest provided field
 	highest := 0
@@ -197,74 +146,6 @@ func applyDefaults(t time.Time, now time.Time, fields int, targetZone *time.Loca
 	return time.Date(year, month, day, hour, min, sec, 0, finalZone)
 }
 
-var (
-	reYearDOY     *regexp.Regexp
-	reYearWeekDow *regexp.Regexp
-	reOnce        sync.Once
-)
-
-func initRegex() {
-	reOnce.Do(func() {
-		reYearDOY = regexp.MustCompile(`^(\d{4})-(\d{1,3})$`)
-		reYearWeekDow = regexp.MustCompile(`^(\d{4})-w(\d{1,2})-(\d)$`)
-	})
-}
-
-// parseYearDOY handles format YEAR-DOY (e.g., 2018-110)
-func parseYearDOY(input string, loc *time.Location) (time.Time, bool) {
-	initRegex()
-	matches := reYearDOY.FindStringSubmatch(input)
-	if matches == nil {
-		return time.Time{}, false
-	}
-	year, _ := strconv.Atoi(matches[1])
-	doy, _ := strconv.Atoi(matches[2])
-
-	if doy < 1 || doy > 366 {
-		return time.Time{}, false
-	}
-
-	t := time.Date(year, 1, 1, 0, 0, 0, 0, loc).AddDate(0, 0, doy-1)
-	return t, true
-}
-
-// parseYearWeekDow handles format YEAR-wWEEK-DOW (e.g., 2018-w16-5)
-func parseYearWeekDow(input string, loc *time.Location) (time.Time, bool) {
-	initRegex()
-	matches := reYearWeekDow.FindStringSubmatch(input)
-	if matches == nil {
-		return time.Time{}, false
-	}
-	year, _ := strconv.Atoi(matches[1])
-	week, _ := strconv.Atoi(matches[2])
-	dow, _ := strconv.Atoi(matches[3]) // 1=Monday, 7=Sunday
-
-	if week < 0 || week > 53 || dow < 1 || dow > 7 {
-		return time.Time{}, false
-	}
-
-	// ISO Week Date calculation
-	// Start of the year
-	t := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
-	// Find the first Thursday of the year
-	// ISO weeks start on Monday. The first week of the year is the one that contains the first Thursday.
-	for t.Weekday() != time.Thursday {
-		t = t.AddDate(0, 0, 1)
-	}
-	// t is now the first Thursday.
-	// The ISO week 1 starts on the Monday before this Thursday.
-	week1Start := t.AddDate(0, 0, -3) // Monday of week 1
-
-	// Target week start
-	targetWeekStart := week1Start.AddDate(0, 0, (week-1)*7)
-
-	// Target day
-	// dow: 1=Monday -> offset 0
-	targetDay := targetWeekStart.AddDate(0, 0, dow-1)
-
-	return targetDay, true
-}
-
 // ParseZone parses a time zone string.
 // It accepts "LT" (Local Time), numeric offsets (e.g. "-0800", "+05:30"),
 // and named locations (e.g. "UTC", "PST", "America/New_York").
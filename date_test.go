@@ -166,6 +166,67 @@ func TestParseDate_Errors(t *testing.T) {
 	}
 }
 
+func TestParseDate_Getdate(t *testing.T) {
+	// Wednesday 2023-10-25 12:00:00 UTC
+	now := time.Date(2023, 10, 25, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"now", now},
+		{"today", now},
+		{"2 days ago", time.Date(2023, 10, 23, 12, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Date(2023, 10, 24, 12, 0, 0, 0, time.UTC)},
+		{"tomorrow 08:00", time.Date(2023, 10, 26, 8, 0, 0, 0, time.UTC)},
+		{"+1 week", time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)},
+		{"1 week 2 hours ago", time.Date(2023, 10, 18, 10, 0, 0, 0, time.UTC)},
+		{"last year", time.Date(2022, 10, 25, 12, 0, 0, 0, time.UTC)},
+		{"next month", time.Date(2023, 11, 25, 12, 0, 0, 0, time.UTC)},
+		{"fortnight ago", time.Date(2023, 10, 11, 12, 0, 0, 0, time.UTC)},
+		{"monday", time.Date(2023, 10, 30, 0, 0, 0, 0, time.UTC)},
+		{"last monday", time.Date(2023, 10, 23, 0, 0, 0, 0, time.UTC)},
+		{"next friday", time.Date(2023, 10, 27, 0, 0, 0, 0, time.UTC)},
+		{"next wednesday", time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"this wednesday 10:00", time.Date(2023, 10, 25, 10, 0, 0, 0, time.UTC)},
+		{"third sunday", time.Date(2023, 11, 12, 0, 0, 0, 0, time.UTC)},
+		{"20:00 1990-01-12", time.Date(1990, 1, 12, 20, 0, 0, 0, time.UTC)},
+		{"12 Jan 1990 -1 day", time.Date(1990, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"1990-01-12 04:00 EDT", time.Date(1990, 1, 12, 8, 0, 0, 0, time.UTC)},
+		{"Jan 12 1990 04:00 WET", time.Date(1990, 1, 12, 4, 0, 0, 0, time.UTC)},
+		{"04:00 CET DST", time.Date(2023, 10, 25, 2, 0, 0, 0, time.UTC)},
+		{"12:00 UTC+2", time.Date(2023, 10, 25, 10, 0, 0, 0, time.UTC)},
+		{"1990-01-12T04:00:00Z", time.Date(1990, 1, 12, 4, 0, 0, 0, time.UTC)},
+		{"1990-01-12 04:00 A", time.Date(1990, 1, 12, 3, 0, 0, 0, time.UTC)},
+		{"1990-01-12 04:00 +05:30", time.Date(1990, 1, 11, 22, 30, 0, 0, time.UTC)},
+		{"8:00 p.m.", time.Date(2023, 10, 25, 20, 0, 0, 0, time.UTC)},
+		{"8pm", time.Date(2023, 10, 25, 20, 0, 0, 0, time.UTC)},
+		{"1/12/1990", time.Date(1990, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"19900112", time.Date(1990, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"Feb 29 2024", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"1990-01-12 04:00:00.5", time.Date(1990, 1, 12, 4, 0, 0, 500000000, time.UTC)},
+		{"2022.03.23.03.04.05", time.Date(2022, 3, 23, 3, 4, 5, 0, time.UTC)},
+		{"Thursday (payday), 11 Jan 1990", time.Date(1990, 1, 11, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input, now, nil)
+			if err != nil {
+				t.Fatalf("ParseDate() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"ago", "next", "Feb 29 2023", "2023-02-30", "13 pm", "1990-01-12 1990-01-13", "EST PST", "1990-01-12 (unclosed"} {
+		if _, err := ParseDate(input, now, nil); !errors.Is(err, ErrDateParse) {
+			t.Errorf("ParseDate(%q) error = %v, want ErrDateParse", input, err)
+		}
+	}
+}
+
 func TestParseZone(t *testing.T) {
 	tests := []struct {
 		name       string
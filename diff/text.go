@@ -0,0 +1,180 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SplitText splits s into lines that keep their "\n" terminators, so
+// joining them gives back s byte for byte. Only the last line can lack a
+// terminator, when s does not end with a newline. Carriage returns and any
+// other bytes are part of the line, which is how RCS treats binary files.
+func SplitText(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// GenerateDelta returns the RCS delta that turns from into to. Lines are
+// compared with their terminators, so a last line without a newline differs
+// from the same line with one, and the delta itself then ends without a
+// newline, as diff -n writes it for ci and co reads it back. Deletions come
+// before the insertion that replaces them.
+func GenerateDelta(from, to string) (string, error) {
+	ed, err := Generate(SplitText(from), SplitText(to))
+	if err != nil {
+		return "", err
+	}
+	return formatDelta(canonicalDelta(ed)), nil
+}
+
+// canonicalDelta moves an insertion that is followed by the deletion of the
+// lines after it to the end of that deletion, merging insertions that end up
+// at the same place. An insertion holding a last line without a newline
+// then always ends the delta.
+func canonicalDelta(ed EdDiff) EdDiff {
+	ed = append(EdDiff(nil), ed...)
+	out := make(EdDiff, 0, len(ed))
+	for i := 0; i < len(ed); i++ {
+		add, ok := ed[i].(Add)
+		if !ok {
+			out = append(out, ed[i])
+			continue
+		}
+		if i+1 < len(ed) {
+			if del, ok := ed[i+1].(Delete); ok && del[0] == add.LineStart+1 {
+				out = append(out, del)
+				ed[i+1] = Add{Lines: add.Lines, LineStart: del[0] + del[1] - 1}
+				continue
+			}
+		}
+		if n := len(out); n > 0 {
+			if prev, ok := out[n-1].(Add); ok && prev.LineStart == add.LineStart {
+				prev.Lines = append(append([]string(nil), prev.Lines...), add.Lines...)
+				out[n-1] = prev
+				continue
+			}
+		}
+		out = append(out, add)
+	}
+	return out
+}
+
+// formatDelta writes ed, whose added lines keep their terminators, as an
+// RCS delta.
+func formatDelta(ed EdDiff) string {
+	var sb strings.Builder
+	for _, cmd := range ed {
+		switch c := cmd.(type) {
+		case Add:
+			fmt.Fprintf(&sb, "a%d %d\n", c.LineStart, len(c.Lines))
+			for _, l := range c.Lines {
+				sb.WriteString(l)
+			}
+		default:
+			sb.WriteString(c.String())
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// ParseDelta parses an RCS delta byte for byte. Unlike ParseEdDiff the
+// lines of each Add keep their "\n" terminators, and carriage returns, long
+// lines and a last line without a newline are kept as they are.
+func ParseDelta(delta string) (EdDiff, error) {
+	lines := SplitText(delta)
+	var commands EdDiff
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\n")
+		if line == "" {
+			continue
+		}
+		var cmdType rune
+		var start, count int
+		n, err := fmt.Sscanf(line, "%c%d %d", &cmdType, &start, &count)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command line %q: %v", line, err)
+		}
+		if n < 3 {
+			return nil, fmt.Errorf("invalid command line %q: expected 3 items", line)
+		}
+		switch cmdType {
+		case 'd':
+			commands = append(commands, Delete{start, count})
+		case 'a':
+			if count < 0 || count > len(lines)-i-1 {
+				return nil, fmt.Errorf("unexpected EOF reading add lines for command %s", line)
+			}
+			commands = append(commands, Add{Lines: lines[i+1 : i+1+count], LineStart: start})
+			i += count
+		default:
+			return nil, fmt.Errorf("unknown command type: %c", cmdType)
+		}
+	}
+	return commands, nil
+}
+
+// ApplyDelta applies the RCS delta to from and returns the result byte for
+// byte, including whether it ends with a newline.
+func ApplyDelta(from, delta string) (string, error) {
+	ed, err := ParseDelta(delta)
+	if err != nil {
+		return "", err
+	}
+	r := &textReader{lines: SplitText(from)}
+	var sb strings.Builder
+	if err := ed.Apply(r, textWriter{&sb}); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+type textReader struct {
+	lines []string
+	idx   int
+}
+
+func (r *textReader) ReadLine() (string, error) {
+	if r.idx >= len(r.lines) {
+		return "", io.EOF
+	}
+	line := r.lines[r.idx]
+	r.idx++
+	return line, nil
+}
+
+type textWriter struct {
+	sb *strings.Builder
+}
+
+func (w textWriter) WriteLine(line string) error {
+	w.sb.WriteString(line)
+	return nil
+}
+
+// IsBinary reports whether s looks like binary data rather than text,
+// that is whether it holds a NUL byte, the test diff itself uses.
+func IsBinary(s string) bool {
+	return strings.IndexByte(s, 0) >= 0
+}
+
+// UnifiedText is Unified over two whole texts. A last line without a
+// newline is marked "\ No newline at end of file", and when either text is
+// binary and they differ only "Binary files ... differ" is reported, as
+// diff -u does.
+func UnifiedText(fromName, toName, from, to string, context int) (string, error) {
+	if from == to {
+		return "", nil
+	}
+	if IsBinary(from) || IsBinary(to) {
+		return fmt.Sprintf("Binary files %s and %s differ\n", fromName, toName), nil
+	}
+	return unified(fromName, toName, SplitText(from), SplitText(to), context, true)
+}
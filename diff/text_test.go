@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateDelta(t *testing.T) {
+	// Each want is what GNU diff -an, the diff ci runs, prints for from and
+	// to, so the deltas are the ones GNU RCS stores.
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"same", "a\nb\n", "a\nb\n", ""},
+		{"drop final newline", "a\nb\n", "a\nb", "d2 1\na2 1\nb"},
+		{"add final newline", "a\nb", "a\nb\n", "d2 1\na2 1\nb\n"},
+		{"change without final newline", "a\nb\nc\n", "a\nx", "d2 2\na3 1\nx"},
+		{"append to no final newline", "a", "a\nb", "d1 1\na1 2\na\nb"},
+		{"from empty", "", "a\nb", "a0 2\na\nb"},
+		{"to empty", "a\nb", "", "d1 2\n"},
+		{"carriage returns", "a\r\nb\r\n", "a\r\nc\r\n", "d2 1\na2 1\nc\r\n"},
+		{"binary", "\x00\xff\n\x01", "\x00\xff\n\x02", "d2 1\na2 1\n\x02"},
+		{"two hunks", "a\nb\nc\nd\ne\nf\n", "a\nB\nc\nd\nf\ng\n", "d2 1\na2 1\nB\nd5 1\na6 1\ng\n"},
+		{"replace first line", "x\ny\nz\n", "w\ny\nz\n", "d1 1\na1 1\nw\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateDelta(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GenerateDelta() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateDelta() = %q, want %q", got, tt.want)
+			}
+			applied, err := ApplyDelta(tt.from, got)
+			if err != nil {
+				t.Fatalf("ApplyDelta() error = %v", err)
+			}
+			if applied != tt.to {
+				t.Errorf("ApplyDelta() = %q, want %q", applied, tt.to)
+			}
+		})
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	tests := []struct {
+		name         string
+		from, delta  string
+		want         string
+		wantErrMatch string
+	}{
+		{"keeps missing final newline", "a\nb", "a0 1\nx\n", "x\na\nb", ""},
+		{"long line", strings.Repeat("x", 100000) + "\n", "a1 1\ny\n", strings.Repeat("x", 100000) + "\ny\n", ""},
+		{"short add", "a\n", "a1 2\nb\n", "", "unexpected EOF"},
+		{"bad command", "a\n", "x1 1\n", "", "unknown command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyDelta(tt.from, tt.delta)
+			if tt.wantErrMatch != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMatch) {
+					t.Fatalf("ApplyDelta() error = %v, want %q", err, tt.wantErrMatch)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyDelta() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyDelta() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedText(t *testing.T) {
+	got, err := UnifiedText("a", "b", "x\ny\n", "x\ny", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--- a\n+++ b\n@@ -1,2 +1,2 @@\n x\n-y\n+y\n\\ No newline at end of file\n"
+	if got != want {
+		t.Errorf("UnifiedText() = %q, want %q", got, want)
+	}
+
+	got, err = UnifiedText("a", "b", "x\x00\n", "y\x00\n", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Binary files a and b differ\n"; got != want {
+		t.Errorf("UnifiedText() binary = %q, want %q", got, want)
+	}
+}
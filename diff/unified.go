@@ -0,0 +1,168 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpKind classifies a line of an edit script.
+type OpKind int
+
+const (
+	OpEqual OpKind = iota
+	OpDelete
+	OpInsert
+)
+
+// LineOp is one line of an edit script: a line of the old text that is kept
+// or deleted, or a line inserted from the new text.
+type LineOp struct {
+	Kind OpKind
+	Text string
+}
+
+// Ops expands ed, a diff against from, into a line by line edit script.
+// Deletions come before insertions at the same place.
+func (ed EdDiff) Ops(from []string) []LineOp {
+	adds := map[int][]string{}
+	dels := map[int]int{}
+	for _, cmd := range ed {
+		switch c := cmd.(type) {
+		case Add:
+			adds[c.LineStart] = append(adds[c.LineStart], c.Lines...)
+		case Delete:
+			dels[c[0]] = c[1]
+		}
+	}
+	var ops []LineOp
+	insert := func(at int) {
+		for _, l := range adds[at] {
+			ops = append(ops, LineOp{Kind: OpInsert, Text: l})
+		}
+	}
+	for i := 0; ; {
+		if n := dels[i+1]; n > 0 && i < len(from) {
+			at := i
+			for k := 0; k < n && i < len(from); k++ {
+				ops = append(ops, LineOp{Kind: OpDelete, Text: from[i]})
+				i++
+			}
+			insert(at)
+			continue
+		}
+		insert(i)
+		if i >= len(from) {
+			break
+		}
+		ops = append(ops, LineOp{Kind: OpEqual, Text: from[i]})
+		i++
+	}
+	return ops
+}
+
+// Unified renders the changes between from and to as a unified diff, like
+// diff -u, with context lines of context around each change. Identical
+// inputs give an empty string.
+func Unified(fromName, toName string, from, to []string, context int) (string, error) {
+	return unified(fromName, toName, from, to, context, false)
+}
+
+// unified renders the diff. With terminated set the lines keep their "\n"
+// terminators, as from SplitText, and a line without one is followed by a
+// "\ No newline at end of file" marker.
+func unified(fromName, toName string, from, to []string, context int, terminated bool) (string, error) {
+	ed, err := Generate(from, to)
+	if err != nil {
+		return "", err
+	}
+	ops := ed.Ops(from)
+	if context < 0 {
+		context = 0
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == OpEqual {
+			i++
+			continue
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		// Extend the hunk while the next change is within 2*context lines.
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != OpEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == OpEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+		writeHunk(&sb, ops, start, end, terminated)
+		i = end
+	}
+	return sb.String(), nil
+}
+
+func writeHunk(sb *strings.Builder, ops []LineOp, start, end int, terminated bool) {
+	fromLine, toLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.Kind != OpInsert {
+			fromLine++
+		}
+		if op.Kind != OpDelete {
+			toLine++
+		}
+	}
+	fromCount, toCount := 0, 0
+	for _, op := range ops[start:end] {
+		if op.Kind != OpInsert {
+			fromCount++
+		}
+		if op.Kind != OpDelete {
+			toCount++
+		}
+	}
+	if fromCount == 0 {
+		fromLine--
+	}
+	if toCount == 0 {
+		toLine--
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount))
+	for _, op := range ops[start:end] {
+		switch op.Kind {
+		case OpEqual:
+			sb.WriteByte(' ')
+		case OpDelete:
+			sb.WriteByte('-')
+		case OpInsert:
+			sb.WriteByte('+')
+		}
+		sb.WriteString(op.Text)
+		if !terminated {
+			sb.WriteByte('\n')
+		} else if !strings.HasSuffix(op.Text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(line, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
@@ -0,0 +1,69 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/arran4/golang-rcs/diff"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    []string
+		to      []string
+		context int
+		want    string
+	}{
+		{
+			name: "Identical",
+			from: []string{"A", "B"},
+			to:   []string{"A", "B"},
+			want: "",
+		},
+		{
+			name:    "Change in the middle",
+			from:    []string{"1", "2", "3", "4", "5", "6", "7"},
+			to:      []string{"1", "2", "3", "X", "5", "6", "7"},
+			context: 1,
+			want: "--- a\n+++ b\n" +
+				"@@ -3,3 +3,3 @@\n" +
+				" 3\n" +
+				"-4\n" +
+				"+X\n" +
+				" 5\n",
+		},
+		{
+			name:    "Separate hunks",
+			from:    []string{"A", "1", "2", "3", "4", "B"},
+			to:      []string{"1", "2", "3", "4"},
+			context: 1,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,2 +1 @@\n" +
+				"-A\n" +
+				" 1\n" +
+				"@@ -5,2 +4 @@\n" +
+				" 4\n" +
+				"-B\n",
+		},
+		{
+			name: "From empty",
+			from: nil,
+			to:   []string{"A"},
+			want: "--- a\n+++ b\n" +
+				"@@ -0,0 +1 @@\n" +
+				"+A\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diff.Unified("a", "b", tt.from, tt.to, tt.context)
+			if err != nil {
+				t.Fatalf("Unified() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unified() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
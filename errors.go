@@ -1,6 +1,13 @@
 package rcs
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
 
 var (
 	ErrEmptyId         = errors.New("empty id")
@@ -8,6 +15,16 @@ var (
 	ErrDateParse       = errors.New("unable to parse date")
 	ErrUnknownToken    = errors.New("unknown token")
 	ErrTooManyNewLines = errors.New("too many new lines")
+
+	ErrInvalidSymbolName = errors.New("invalid symbol name")
+	ErrSymbolExists      = errors.New("symbol already exists")
+	ErrSymbolNotFound    = errors.New("symbol not found")
+	ErrRevisionNotFound  = errors.New("revision not found")
+	ErrAccessDenied      = errors.New("access denied")
+
+	// ErrUnknownCharset is returned for a charset TranscodeToUTF8 does not
+	// know.
+	ErrUnknownCharset = errors.New("unknown charset")
 )
 
 type ErrParseProperty struct {
@@ -22,3 +39,107 @@ func (e ErrParseProperty) Error() string {
 func (e ErrParseProperty) Unwrap() error {
 	return e.Err
 }
+
+// ParseError is returned by ParseFile when a master cannot be parsed. Line
+// and Column are 1-based, Column and Offset count bytes. Section is the part
+// of the file being read ("admin", "delta", "desc" or "deltatext"),
+// Revision the delta it belongs to and Field the keyword being read, when
+// known. Source is the offending line without its line ending. Filename is
+// left for callers that know it.
+type ParseError struct {
+	Filename string
+	Line     int
+	Column   int
+	Offset   int64
+	Section  string
+	Revision string
+	Field    string
+	Source   string
+	Err      error
+}
+
+// Error keeps the historical "parsing line:offset: ..." form, where the
+// offset is 0-based.
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("parsing %d:%d: %v", e.Line, e.Column-1, e.Err)
+	if e.Filename != "" {
+		msg = e.Filename + ": " + msg
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Phrase describes what was being parsed, such as `delta 1.2 "date"`.
+func (e *ParseError) Phrase() string {
+	var parts []string
+	if e.Section != "" {
+		parts = append(parts, e.Section)
+	}
+	if e.Revision != "" {
+		parts = append(parts, e.Revision)
+	}
+	if e.Field != "" {
+		parts = append(parts, strconv.Quote(e.Field))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Diagnostic formats the error compiler style: "file:line:col: phrase:
+// message", then the source line and a caret under the column.
+func (e *ParseError) Diagnostic() string {
+	var sb strings.Builder
+	if e.Filename != "" {
+		sb.WriteString(e.Filename)
+		sb.WriteString(":")
+	}
+	fmt.Fprintf(&sb, "%d:%d: ", e.Line, e.Column)
+	if phrase := e.Phrase(); phrase != "" {
+		sb.WriteString(phrase)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(fmt.Sprint(e.Err))
+	sb.WriteString("\n")
+	if e.Source != "" || e.Column > 1 {
+		sb.WriteString("\t")
+		sb.WriteString(e.Source)
+		sb.WriteString("\n\t")
+		sb.WriteString(caretPad(e.Source, e.Column-1))
+		sb.WriteString("^\n")
+	}
+	return sb.String()
+}
+
+// caretPad returns the whitespace that lines a caret up under byte column
+// col of line, keeping tabs so the alignment survives tab expansion.
+func caretPad(line string, col int) string {
+	if col > len(line) {
+		return strings.Repeat(" ", utf8.RuneCountInString(line)+col-len(line))
+	}
+	var sb strings.Builder
+	for _, r := range line[:col] {
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	return sb.String()
+}
+
+// MarshalJSON writes the error with its message, for editor integration.
+func (e *ParseError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Filename string `json:",omitempty"`
+		Line     int
+		Column   int
+		Offset   int64
+		Section  string `json:",omitempty"`
+		Revision string `json:",omitempty"`
+		Field    string `json:",omitempty"`
+		Source   string
+		Message  string
+	}{e.Filename, e.Line, e.Column, e.Offset, e.Section, e.Revision, e.Field, e.Source, fmt.Sprint(e.Err)})
+}
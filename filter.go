@@ -68,6 +68,32 @@ func (f *InFilter) Match(r *RevisionHead) bool {
 	return false
 }
 
+// RevisionRangeFilter matches the revisions of one branch from From to To
+// inclusive, like rlog -rFrom:To. An empty From or To leaves that end open;
+// the branch is taken from whichever end is set.
+type RevisionRangeFilter struct {
+	From string
+	To   string
+}
+
+func (f *RevisionRangeFilter) Match(r *RevisionHead) bool {
+	rev := r.Revision.String()
+	ref := f.From
+	if ref == "" {
+		ref = f.To
+	}
+	if ref != "" && BranchNumber(rev) != BranchNumber(ref) {
+		return false
+	}
+	if f.From != "" && compareRevisions(rev, f.From) < 0 {
+		return false
+	}
+	if f.To != "" && compareRevisions(rev, f.To) > 0 {
+		return false
+	}
+	return true
+}
+
 // ParseFilter parses a filter string into a Filter object.
 // Supported syntax:
 // - state=<value> or s=<value>
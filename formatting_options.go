@@ -17,6 +17,7 @@ type WithFileDescriptionNewLineOffset int
 type WithFileSymbolTerminatorPrefix string
 type WithFileHeadSeparatorSpaces int
 type WithFileAccessSeparatorSpaces int
+type WithFileAccessOnNewLines bool
 type WithFileSymbolsSeparatorSpaces int
 type WithFileLocksSeparatorSpaces int
 type WithFileCommentSeparatorSpaces int
@@ -58,6 +59,8 @@ func (f *File) SetFormattingOption(ops ...any) {
 			f.HeadSeparatorSpaces = int(v)
 		case WithFileAccessSeparatorSpaces:
 			f.AccessSeparatorSpaces = int(v)
+		case WithFileAccessOnNewLines:
+			f.AccessOnNewLines = bool(v)
 		case WithFileSymbolsSeparatorSpaces:
 			f.SymbolsSeparatorSpaces = int(v)
 		case WithFileLocksSeparatorSpaces:
@@ -0,0 +1,747 @@
+package rcs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file implements the free-form date grammar of getdate, as used by
+// GNU RCS for co -d and rlog -d. A date is a sequence of items in any order:
+//
+//   - calendar dates: 1990-01-12, 1990/01/12, 1/12/1990, 12 January 1990,
+//     Jan. 12, 1990, 12-Jan-1990, 2018-110 (day of year), 2018-w16-5 (ISO
+//     week) and the RCS form 1990.01.12.04.00.00
+//   - times of day: 20:00, 20:00:00.5, 8pm, 8:00 p.m.
+//   - zones: numeric offsets (-0800, +05:30, -08), names (UTC, EST, WET,
+//     CET DST), military letters (Z, A-I, K-Y) and LT for the local zone
+//   - days of the week: Thu, last monday, next friday, third sunday
+//   - relative items: 2 days ago, +1 week, last year, yesterday, now
+//
+// Bare numbers are a day of the month on their own, a year after a date
+// without one, YYYYMMDD, or HHMM.
+
+type dateTokenKind int
+
+const (
+	dateNumber dateTokenKind = iota
+	dateWord
+	datePunct
+)
+
+type dateToken struct {
+	kind   dateTokenKind
+	text   string
+	value  int
+	digits int
+}
+
+func tokenizeDate(input string) ([]dateToken, error) {
+	var toks []dateToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			// Parenthesised comments are ignored.
+			depth := 0
+			j := i
+			for ; j < len(input); j++ {
+				if input[j] == '(' {
+					depth++
+				} else if input[j] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if depth != 0 {
+				return nil, errors.New("unbalanced parenthesis")
+			}
+			i = j + 1
+		case isDateDigit(c):
+			j := i
+			for j < len(input) && isDateDigit(input[j]) {
+				j++
+			}
+			if j-i > 9 {
+				return nil, fmt.Errorf("number %s too long", input[i:j])
+			}
+			v, _ := strconv.Atoi(input[i:j])
+			toks = append(toks, dateToken{kind: dateNumber, text: input[i:j], value: v, digits: j - i})
+			i = j
+		case isDateLetter(c):
+			j := i
+			for j < len(input) && isDateLetter(input[j]) {
+				j++
+			}
+			word := strings.ToLower(input[i:j])
+			// The ISO 8601 separator between a date and a time.
+			if word == "t" && i > 0 && isDateDigit(input[i-1]) && j < len(input) && isDateDigit(input[j]) {
+				i = j
+				continue
+			}
+			// Abbreviations may end in a dot: "Jan.", "a.m.".
+			if j < len(input) && input[j] == '.' {
+				if (word == "a" || word == "p") && len(input) >= j+3 && strings.EqualFold(input[j:j+3], ".m.") {
+					word += "m"
+					j += 2
+				}
+				j++
+			}
+			toks = append(toks, dateToken{kind: dateWord, text: word})
+			i = j
+		case strings.IndexByte("+-:,/.", c) >= 0:
+			toks = append(toks, dateToken{kind: datePunct, text: input[i : i+1]})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return toks, nil
+}
+
+func isDateDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isDateLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+var dateMonths = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var dateWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday, "wednes": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// dateOrdinals are the ordinal words getdate accepts. "second" is missing
+// because it is a unit.
+var dateOrdinals = map[string]int{
+	"last": -1, "this": 0, "next": 1, "first": 1, "third": 3, "fourth": 4,
+	"fifth": 5, "sixth": 6, "seventh": 7, "eighth": 8, "ninth": 9,
+	"tenth": 10, "eleventh": 11, "twelfth": 12,
+}
+
+type dateUnit int
+
+const (
+	unitYear dateUnit = iota
+	unitMonth
+	unitDay
+	unitHour
+	unitMinute
+	unitSecond
+)
+
+type dateUnitSize struct {
+	unit dateUnit
+	n    int
+}
+
+var dateUnits = map[string]dateUnitSize{
+	"year": {unitYear, 1}, "years": {unitYear, 1},
+	"month": {unitMonth, 1}, "months": {unitMonth, 1},
+	"fortnight": {unitDay, 14}, "fortnights": {unitDay, 14},
+	"week": {unitDay, 7}, "weeks": {unitDay, 7},
+	"day": {unitDay, 1}, "days": {unitDay, 1},
+	"hour": {unitHour, 1}, "hours": {unitHour, 1},
+	"minute": {unitMinute, 1}, "minutes": {unitMinute, 1}, "min": {unitMinute, 1}, "mins": {unitMinute, 1},
+	"second": {unitSecond, 1}, "seconds": {unitSecond, 1}, "sec": {unitSecond, 1}, "secs": {unitSecond, 1},
+}
+
+// dateZones are the zone names getdate knows, as offsets east of UTC in
+// seconds. A following "DST" adds an hour.
+var dateZones = map[string]int{
+	"gmt": 0, "ut": 0, "utc": 0, "wet": 0, "west": 1 * 3600, "bst": 1 * 3600,
+	"art": -3 * 3600, "brt": -3 * 3600, "brst": -2 * 3600,
+	"nst": -(3*3600 + 1800), "ndt": -(2*3600 + 1800),
+	"ast": -4 * 3600, "adt": -3 * 3600, "clt": -4 * 3600, "clst": -3 * 3600,
+	"est": -5 * 3600, "edt": -4 * 3600,
+	"cst": -6 * 3600, "cdt": -5 * 3600,
+	"mst": -7 * 3600, "mdt": -6 * 3600,
+	"pst": -8 * 3600, "pdt": -7 * 3600,
+	"akst": -9 * 3600, "akdt": -8 * 3600,
+	"hst": -10 * 3600, "hast": -10 * 3600, "hadt": -9 * 3600,
+	"sst": -11 * 3600,
+	"wat": 1 * 3600, "cet": 1 * 3600, "cest": 2 * 3600, "met": 1 * 3600,
+	"mez": 1 * 3600, "mest": 2 * 3600, "mesz": 2 * 3600,
+	"eet": 2 * 3600, "eest": 3 * 3600, "cat": 2 * 3600, "sast": 2 * 3600,
+	"eat": 3 * 3600, "msk": 3 * 3600, "msd": 4 * 3600,
+	"ist": 5*3600 + 1800, "sgt": 8 * 3600, "kst": 9 * 3600, "jst": 9 * 3600,
+	"gst": 10 * 3600, "nzst": 12 * 3600, "nzdt": 13 * 3600,
+}
+
+// militaryZone returns the offset of a single letter military zone. J is
+// not a zone.
+func militaryZone(w string) (int, bool) {
+	if len(w) != 1 {
+		return 0, false
+	}
+	switch c := w[0]; {
+	case c >= 'a' && c <= 'i':
+		return int(c-'a'+1) * 3600, true
+	case c >= 'k' && c <= 'm':
+		return int(c-'k'+10) * 3600, true
+	case c >= 'n' && c <= 'y':
+		return -int(c-'n'+1) * 3600, true
+	case c == 'z':
+		return 0, true
+	}
+	return 0, false
+}
+
+// dateItems is what the date grammar collected from an input. fields holds
+// the Field* bits of the calendar and clock values given.
+type dateItems struct {
+	fields                        int
+	year, month, day              int
+	hour, minute, second, nsec    int
+	zone                          *time.Location
+	local                         bool
+	dates, times, zones           int
+	days, dayOrdinal              int
+	weekday                       time.Weekday
+	rels                          bool
+	relYear, relMonth, relDay     int
+	relHour, relMinute, relSecond int
+}
+
+type dateParser struct {
+	toks []dateToken
+	pos  int
+	dateItems
+}
+
+func parseDateItems(input string) (*dateItems, error) {
+	toks, err := tokenizeDate(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, errors.New("empty date")
+	}
+	p := &dateParser{toks: toks}
+	for p.pos < len(p.toks) {
+		if err := p.item(); err != nil {
+			return nil, err
+		}
+	}
+	return &p.dateItems, nil
+}
+
+func (p *dateParser) at(i int) *dateToken {
+	if p.pos+i < len(p.toks) {
+		return &p.toks[p.pos+i]
+	}
+	return nil
+}
+
+func (p *dateParser) numberAt(i int) bool {
+	t := p.at(i)
+	return t != nil && t.kind == dateNumber
+}
+
+func (p *dateParser) punctAt(i int, s string) bool {
+	t := p.at(i)
+	return t != nil && t.kind == datePunct && t.text == s
+}
+
+func (p *dateParser) wordAt(i int) string {
+	if t := p.at(i); t != nil && t.kind == dateWord {
+		return t.text
+	}
+	return ""
+}
+
+func (p *dateParser) unitAt(i int) (dateUnitSize, bool) {
+	u, ok := dateUnits[p.wordAt(i)]
+	return u, ok
+}
+
+func (p *dateParser) monthAt(i int) (time.Month, bool) {
+	m, ok := dateMonths[p.wordAt(i)]
+	return m, ok
+}
+
+// meridianAt returns 1 for "am", 2 for "pm" and 0 otherwise.
+func (p *dateParser) meridianAt(i int) int {
+	switch p.wordAt(i) {
+	case "am":
+		return 1
+	case "pm":
+		return 2
+	}
+	return 0
+}
+
+func (p *dateParser) item() error {
+	t := p.toks[p.pos]
+	switch t.kind {
+	case dateNumber:
+		return p.number()
+	case dateWord:
+		return p.word()
+	}
+	switch t.text {
+	case ",":
+		p.pos++
+		return nil
+	case "+", "-":
+		return p.signed()
+	}
+	return fmt.Errorf("unexpected %q", t.text)
+}
+
+// signed handles "+1 week" and numeric zones such as "-0800".
+func (p *dateParser) signed() error {
+	sign := 1
+	if p.toks[p.pos].text == "-" {
+		sign = -1
+	}
+	if !p.numberAt(1) {
+		return fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	n := *p.at(1)
+	if u, ok := p.unitAt(2); ok {
+		p.pos += 3
+		p.relative(sign*n.value, u)
+		return nil
+	}
+	p.pos += 2
+	offset, err := p.numericZone(sign, n)
+	if err != nil {
+		return err
+	}
+	return p.setZone(time.FixedZone("", offset))
+}
+
+// numericZone reads an offset of hh, hhmm or hh:mm whose first number has
+// already been consumed.
+func (p *dateParser) numericZone(sign int, n dateToken) (int, error) {
+	var h, m int
+	switch {
+	case n.digits <= 2:
+		h = n.value
+		if p.punctAt(0, ":") && p.numberAt(1) {
+			m = p.at(1).value
+			p.pos += 2
+		}
+	case n.digits == 4:
+		h, m = n.value/100, n.value%100
+	default:
+		return 0, fmt.Errorf("invalid zone offset %s", n.text)
+	}
+	if h > 24 || m > 59 {
+		return 0, fmt.Errorf("zone offset %s out of range", n.text)
+	}
+	return sign * (h*3600 + m*60), nil
+}
+
+func (p *dateParser) number() error {
+	n := p.toks[p.pos]
+	switch {
+	case p.punctAt(1, ":"):
+		return p.clock()
+	case p.meridianAt(1) != 0:
+		p.pos++
+		return p.setClock(n.value, 0, 0, 0, FieldHour)
+	case p.punctAt(1, "."):
+		return p.dotted()
+	case p.punctAt(1, "/"):
+		return p.slashed()
+	case p.punctAt(1, "-"):
+		if ok, err := p.dashed(); ok || err != nil {
+			return err
+		}
+	}
+	if m, ok := p.monthAt(1); ok {
+		// 12 January [1990]
+		p.pos += 2
+		return p.setDate(p.optionalYear(), int(m), n.value)
+	}
+	if u, ok := p.unitAt(1); ok {
+		p.pos += 2
+		p.relative(n.value, u)
+		return nil
+	}
+	p.pos++
+	return p.bareNumber(n)
+}
+
+// clock reads h:mm[:ss[.frac]] [am|pm].
+func (p *dateParser) clock() error {
+	h := p.toks[p.pos].value
+	if !p.numberAt(2) {
+		return errors.New("expected minutes after ':'")
+	}
+	m := p.at(2).value
+	p.pos += 3
+	s, nsec, fields := 0, 0, FieldHour|FieldMinute
+	if p.punctAt(0, ":") && p.numberAt(1) {
+		s = p.at(1).value
+		fields |= FieldSecond
+		p.pos += 2
+		if p.punctAt(0, ".") && p.numberAt(1) {
+			frac := p.at(1).text
+			frac = (frac + "000000000")[:9]
+			nsec, _ = strconv.Atoi(frac)
+			p.pos += 2
+		}
+	}
+	return p.setClock(h, m, s, nsec, fields)
+}
+
+// setClock records a time of day, applying a following am or pm.
+func (p *dateParser) setClock(h, m, s, nsec, fields int) error {
+	if mer := p.meridianAt(0); mer != 0 {
+		p.pos++
+		if h < 1 || h > 12 {
+			return fmt.Errorf("hour %d out of range for am/pm", h)
+		}
+		h %= 12
+		if mer == 2 {
+			h += 12
+		}
+	}
+	if h > 23 || m > 59 || s > 59 {
+		return fmt.Errorf("time %02d:%02d:%02d out of range", h, m, s)
+	}
+	p.times++
+	if p.times > 1 {
+		return errors.New("more than one time of day")
+	}
+	p.hour, p.minute, p.second, p.nsec = h, m, s, nsec
+	p.fields |= fields
+	return nil
+}
+
+// dotted reads the RCS form y.m.d.h.m.s or a plain y.m.d.
+func (p *dateParser) dotted() error {
+	var parts []dateToken
+	for {
+		parts = append(parts, p.toks[p.pos])
+		p.pos++
+		if !p.punctAt(0, ".") || !p.numberAt(1) {
+			break
+		}
+		p.pos++
+	}
+	if len(parts) != 3 && len(parts) != 6 {
+		return errors.New("dotted date needs 3 or 6 fields")
+	}
+	if err := p.setDate(normalizeYear(parts[0]), parts[1].value, parts[2].value); err != nil {
+		return err
+	}
+	if len(parts) == 6 {
+		return p.setClock(parts[3].value, parts[4].value, parts[5].value, 0, FieldHour|FieldMinute|FieldSecond)
+	}
+	return nil
+}
+
+// slashed reads m/d, m/d/y or y/m/d.
+func (p *dateParser) slashed() error {
+	first := p.toks[p.pos]
+	if !p.numberAt(2) {
+		return errors.New("expected number after '/'")
+	}
+	second := *p.at(2)
+	p.pos += 3
+	if !p.punctAt(0, "/") || !p.numberAt(1) {
+		if first.digits > 2 {
+			return fmt.Errorf("invalid date %s/%s", first.text, second.text)
+		}
+		return p.setDate(0, first.value, second.value)
+	}
+	third := *p.at(1)
+	p.pos += 2
+	if first.digits > 2 {
+		return p.setDate(normalizeYear(first), second.value, third.value)
+	}
+	return p.setDate(normalizeYear(third), first.value, second.value)
+}
+
+// dashed reads y-m-d, d-month-y, yyyy-ddd and yyyy-wWW-D. It reports false
+// when the dash is not part of a date.
+func (p *dateParser) dashed() (bool, error) {
+	first := p.toks[p.pos]
+	switch {
+	case p.numberAt(2) && p.punctAt(3, "-") && p.numberAt(4):
+		month, day := p.at(2).value, p.at(4).value
+		p.pos += 5
+		return true, p.setDate(normalizeYear(first), month, day)
+	case p.wordAt(2) == "w" && p.numberAt(3) && p.punctAt(4, "-") && p.numberAt(5):
+		week, dow := p.at(3).value, p.at(5).value
+		p.pos += 6
+		if first.digits != 4 || week < 1 || week > 53 || dow < 1 || dow > 7 {
+			return true, fmt.Errorf("invalid ISO week date %s-w%d-%d", first.text, week, dow)
+		}
+		d := isoWeekDate(first.value, week, dow)
+		return true, p.setDate(d.Year(), int(d.Month()), d.Day())
+	case p.punctAt(3, "-") && p.numberAt(4):
+		m, ok := p.monthAt(2)
+		if !ok {
+			return false, nil
+		}
+		year := normalizeYear(*p.at(4))
+		p.pos += 5
+		return true, p.setDate(year, int(m), first.value)
+	case p.numberAt(2) && first.digits == 4 && p.at(2).digits == 3:
+		doy := p.at(2).value
+		p.pos += 3
+		if doy < 1 || doy > 366 {
+			return true, fmt.Errorf("day of year %d out of range", doy)
+		}
+		d := time.Date(first.value, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, doy-1)
+		if d.Year() != first.value {
+			return true, fmt.Errorf("day of year %d out of range", doy)
+		}
+		return true, p.setDate(d.Year(), int(d.Month()), d.Day())
+	}
+	return false, nil
+}
+
+// bareNumber places a number that is not part of a larger item.
+func (p *dateParser) bareNumber(n dateToken) error {
+	switch {
+	case p.dates > 0 && p.fields&FieldYear == 0:
+		p.year = normalizeYear(n)
+		p.fields |= FieldYear
+		return nil
+	case p.dates == 0 && n.digits <= 2:
+		return p.setDate(0, 0, n.value)
+	case p.dates == 0 && n.digits == 8:
+		return p.setDate(n.value/10000, n.value/100%100, n.value%100)
+	case p.times == 0 && (n.digits == 3 || n.digits == 4):
+		return p.setClock(n.value/100, n.value%100, 0, 0, FieldHour|FieldMinute)
+	case p.times == 0 && n.digits == 6:
+		return p.setClock(n.value/10000, n.value/100%100, n.value%100, 0, FieldHour|FieldMinute|FieldSecond)
+	}
+	return fmt.Errorf("unexpected number %s", n.text)
+}
+
+// optionalYear consumes a year, possibly after a comma, following a month
+// and day. It returns 0 when there is none.
+func (p *dateParser) optionalYear() int {
+	i := 0
+	if p.punctAt(0, ",") {
+		i = 1
+	}
+	if !p.numberAt(i) || p.punctAt(i+1, ":") || p.meridianAt(i+1) != 0 {
+		return 0
+	}
+	if _, ok := p.unitAt(i + 1); ok {
+		return 0
+	}
+	y := normalizeYear(*p.at(i))
+	p.pos += i + 1
+	return y
+}
+
+// setDate records a calendar date; zero values were not given.
+func (p *dateParser) setDate(year, month, day int) error {
+	if month != 0 && (month < 1 || month > 12) {
+		return fmt.Errorf("month %d out of range", month)
+	}
+	if day != 0 && (day < 1 || day > 31) {
+		return fmt.Errorf("day %d out of range", day)
+	}
+	p.dates++
+	if p.dates > 1 {
+		return errors.New("more than one date")
+	}
+	if year != 0 {
+		p.year = year
+		p.fields |= FieldYear
+	}
+	if month != 0 {
+		p.month = month
+		p.fields |= FieldMonth
+	}
+	if day != 0 {
+		p.day = day
+		p.fields |= FieldDay
+	}
+	return nil
+}
+
+func (p *dateParser) setZone(loc *time.Location) error {
+	p.zones++
+	if p.zones > 1 {
+		return errors.New("more than one time zone")
+	}
+	p.zone = loc
+	p.fields |= FieldZone
+	return nil
+}
+
+func (p *dateParser) setWeekday(ordinal int, wd time.Weekday) error {
+	p.days++
+	if p.days > 1 {
+		return errors.New("more than one day of the week")
+	}
+	p.dayOrdinal, p.weekday = ordinal, wd
+	return nil
+}
+
+func (p *dateParser) relative(n int, u dateUnitSize) {
+	n *= u.n
+	switch u.unit {
+	case unitYear:
+		p.relYear += n
+	case unitMonth:
+		p.relMonth += n
+	case unitDay:
+		p.relDay += n
+	case unitHour:
+		p.relHour += n
+	case unitMinute:
+		p.relMinute += n
+	case unitSecond:
+		p.relSecond += n
+	}
+	p.rels = true
+}
+
+func (p *dateParser) word() error {
+	w := p.toks[p.pos].text
+	if m, ok := dateMonths[w]; ok {
+		// January 1990, Jan. 12, Jan 12 1990
+		p.pos++
+		if p.numberAt(0) && !p.punctAt(1, ":") && p.meridianAt(1) == 0 {
+			if n := *p.at(0); n.digits > 2 {
+				p.pos++
+				return p.setDate(normalizeYear(n), int(m), 0)
+			}
+			day := p.at(0).value
+			p.pos++
+			return p.setDate(p.optionalYear(), int(m), day)
+		}
+		return p.setDate(0, int(m), 0)
+	}
+	if wd, ok := dateWeekdays[w]; ok {
+		p.pos++
+		return p.setWeekday(0, wd)
+	}
+	if o, ok := dateOrdinals[w]; ok {
+		if wd, ok := dateWeekdays[p.wordAt(1)]; ok {
+			p.pos += 2
+			return p.setWeekday(o, wd)
+		}
+		if u, ok := p.unitAt(1); ok {
+			p.pos += 2
+			p.relative(o, u)
+			return nil
+		}
+		return fmt.Errorf("expected a day or unit after %q", w)
+	}
+	if u, ok := dateUnits[w]; ok {
+		p.pos++
+		p.relative(1, u)
+		return nil
+	}
+	p.pos++
+	switch w {
+	case "ago":
+		if !p.rels {
+			return errors.New("\"ago\" without a relative item")
+		}
+		p.relYear, p.relMonth, p.relDay = -p.relYear, -p.relMonth, -p.relDay
+		p.relHour, p.relMinute, p.relSecond = -p.relHour, -p.relMinute, -p.relSecond
+		return nil
+	case "now", "today":
+		p.rels = true
+		return nil
+	case "yesterday":
+		p.relDay--
+		p.rels = true
+		return nil
+	case "tomorrow":
+		p.relDay++
+		p.rels = true
+		return nil
+	case "lt":
+		p.zones++
+		if p.zones > 1 {
+			return errors.New("more than one time zone")
+		}
+		p.local = true
+		return nil
+	}
+	offset, ok := dateZones[w]
+	if !ok {
+		offset, ok = militaryZone(w)
+	}
+	if !ok {
+		return fmt.Errorf("unknown word %q", w)
+	}
+	name := strings.ToUpper(w)
+	if p.wordAt(0) == "dst" {
+		offset += 3600
+		name += " DST"
+		p.pos++
+	}
+	// GMT+2, UTC-05:00
+	if (p.punctAt(0, "+") || p.punctAt(0, "-")) && p.numberAt(1) {
+		if _, ok := p.unitAt(2); !ok {
+			sign := 1
+			if p.punctAt(0, "-") {
+				sign = -1
+			}
+			n := *p.at(1)
+			p.pos += 2
+			extra, err := p.numericZone(sign, n)
+			if err != nil {
+				return err
+			}
+			offset += extra
+			name = ""
+		}
+	}
+	return p.setZone(time.FixedZone(name, offset))
+}
+
+// normalizeYear maps two digit years to 1969-2068, as getdate does.
+func normalizeYear(n dateToken) int {
+	if n.digits <= 2 {
+		if n.value < 69 {
+			return n.value + 2000
+		}
+		return n.value + 1900
+	}
+	return n.value
+}
+
+// isoWeekDate returns the date of day dow (1 is Monday) of ISO week week of
+// year.
+func isoWeekDate(year, week, dow int) time.Time {
+	// Week 1 is the week with the year's first Thursday in it.
+	t := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	for t.Weekday() != time.Thursday {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t.AddDate(0, 0, -3+(week-1)*7+dow-1)
+}
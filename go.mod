@@ -4,6 +4,7 @@ go 1.22
 
 require (
 	github.com/google/go-cmp v0.6.0
+	github.com/klauspost/compress v1.17.11
 	golang.org/x/exp v0.0.0-20230522175609-2e198f4a06a1
 	golang.org/x/tools v0.22.0
 )
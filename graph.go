@@ -0,0 +1,219 @@
+package rcs
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// GraphEdgeKind distinguishes the edges of a revision graph.
+type GraphEdgeKind int
+
+const (
+	// GraphEdgeNext links a revision to the next one on the same line of
+	// development.
+	GraphEdgeNext GraphEdgeKind = iota
+	// GraphEdgeBranch links a branch point to the first revision of a branch.
+	GraphEdgeBranch
+	// GraphEdgeMerge links a CVS-NT mergepoint to the revision it was merged
+	// into.
+	GraphEdgeMerge
+)
+
+// GraphNode is a revision in a revision graph.
+type GraphNode struct {
+	Revision string
+	Date     DateTime
+	Author   string
+	State    string
+	Symbols  []string
+	Lockers  []string
+}
+
+// GraphEdge runs from the older revision to the newer one. Label carries the
+// branch name for branch edges.
+type GraphEdge struct {
+	From  string
+	To    string
+	Kind  GraphEdgeKind
+	Label string
+}
+
+// Graph is the revision tree of a file, oldest revisions first.
+type Graph struct {
+	Nodes []*GraphNode
+	Edges []GraphEdge
+}
+
+// Graph builds the revision tree of f. When filter is non-nil only matching
+// revisions become nodes; each kept revision is linked to its nearest kept
+// ancestor so the shape of the tree survives.
+func (f *File) Graph(filter Filter) *Graph {
+	parents := f.RevisionParents()
+	kept := map[string]bool{}
+	for _, rh := range f.RevisionHeads {
+		if filter == nil || filter.Match(rh) {
+			kept[rh.Revision.String()] = true
+		}
+	}
+	symbols := map[string][]string{}
+	for _, s := range f.Symbols {
+		symbols[s.Revision] = append(symbols[s.Revision], s.Name)
+	}
+	lockers := map[string][]string{}
+	for _, l := range f.Locks {
+		lockers[l.Revision] = append(lockers[l.Revision], l.User)
+	}
+	branchNames := f.BranchSymbols()
+
+	g := &Graph{}
+	for _, rh := range f.RevisionHeads {
+		rev := rh.Revision.String()
+		if !kept[rev] {
+			continue
+		}
+		g.Nodes = append(g.Nodes, &GraphNode{
+			Revision: rev,
+			Date:     rh.Date,
+			Author:   rh.Author.String(),
+			State:    rh.State.String(),
+			Symbols:  symbols[rev],
+			Lockers:  lockers[rev],
+		})
+
+		from := parents[rev]
+		seen := map[string]bool{}
+		for from != "" && !kept[from] && !seen[from] {
+			seen[from] = true
+			from = parents[from]
+		}
+		if from != "" && kept[from] {
+			e := GraphEdge{From: from, To: rev}
+			if branch := BranchNumber(rev); branch != BranchNumber(from) {
+				e.Kind = GraphEdgeBranch
+				e.Label = branchNames[branch]
+			}
+			g.Edges = append(g.Edges, e)
+		}
+		for _, mp := range rh.Mergepoint {
+			if m := mp.Raw(); kept[m] {
+				g.Edges = append(g.Edges, GraphEdge{From: m, To: rev, Kind: GraphEdgeMerge})
+			}
+		}
+	}
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		return compareRevisions(g.Nodes[i].Revision, g.Nodes[j].Revision) < 0
+	})
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if c := compareRevisions(g.Edges[i].To, g.Edges[j].To); c != 0 {
+			return c < 0
+		}
+		return g.Edges[i].Kind < g.Edges[j].Kind
+	})
+	return g
+}
+
+func (n *GraphNode) labelLines() []string {
+	lines := []string{n.Revision, n.Author + " " + n.State}
+	if d, err := n.Date.DateTime(); err == nil {
+		lines = append(lines, d.UTC().Format("2006-01-02"))
+	}
+	if len(n.Symbols) > 0 {
+		lines = append(lines, strings.Join(n.Symbols, ", "))
+	}
+	if len(n.Lockers) > 0 {
+		lines = append(lines, "locked by "+strings.Join(n.Lockers, ", "))
+	}
+	return lines
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (g *Graph) WriteDOT(w io.Writer, name string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(name))
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=box];\n")
+	for _, n := range g.Nodes {
+		lines := n.labelLines()
+		for i, l := range lines {
+			lines[i] = dotEscape(l)
+		}
+		fmt.Fprintf(&sb, "\t%s [label=\"%s\"", dotQuote(n.Revision), strings.Join(lines, `\n`))
+		if len(n.Lockers) > 0 {
+			sb.WriteString(", color=red")
+		}
+		if n.State == "dead" {
+			sb.WriteString(", style=dashed, fontcolor=gray")
+		}
+		sb.WriteString("];\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s", dotQuote(e.From), dotQuote(e.To))
+		var attrs []string
+		if e.Label != "" {
+			attrs = append(attrs, "label="+dotQuote(e.Label))
+		}
+		switch e.Kind {
+		case GraphEdgeBranch:
+			attrs = append(attrs, "color=blue")
+		case GraphEdgeMerge:
+			attrs = append(attrs, "style=dashed", `label="merge"`)
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(attrs, ", "))
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	for _, n := range g.Nodes {
+		lines := n.labelLines()
+		for i, l := range lines {
+			lines[i] = mermaidEscape(l)
+		}
+		fmt.Fprintf(&sb, "\t%s[\"%s\"]\n", mermaidID(n.Revision), strings.Join(lines, "<br/>"))
+		if len(n.Lockers) > 0 {
+			fmt.Fprintf(&sb, "\tstyle %s stroke:red\n", mermaidID(n.Revision))
+		}
+		if n.State == "dead" {
+			fmt.Fprintf(&sb, "\tstyle %s stroke-dasharray:4,color:gray\n", mermaidID(n.Revision))
+		}
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		label := e.Label
+		if e.Kind == GraphEdgeMerge {
+			arrow, label = "-.->", "merge"
+		}
+		if label != "" {
+			arrow += "|\"" + mermaidEscape(label) + "\"|"
+		}
+		fmt.Fprintf(&sb, "\t%s %s %s\n", mermaidID(e.From), arrow, mermaidID(e.To))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func dotQuote(s string) string {
+	return `"` + dotEscape(s) + `"`
+}
+
+func mermaidID(rev string) string {
+	return "r" + strings.ReplaceAll(rev, ".", "_")
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
@@ -0,0 +1,103 @@
+package rcs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func graphTestFile() *File {
+	f := NewFile()
+	f.Head = "1.3"
+	f.Symbols = []*Symbol{
+		{Name: "REL", Revision: "1.2"},
+		{Name: "BR", Revision: "1.2.0.2"},
+	}
+	f.Locks = []*Lock{{User: "bob", Revision: "1.3"}}
+	f.RevisionHeads = []*RevisionHead{
+		{Revision: "1.3", Date: "2020.01.05.00.00.00", Author: "bob", State: "Exp", NextRevision: "1.2", Mergepoint: PhraseValues{SimpleString("1.2.2.2")}},
+		{Revision: "1.2", Date: "2020.01.02.00.00.00", Author: "alice", State: "Rel", NextRevision: "1.1", Branches: []Num{"1.2.2.1"}},
+		{Revision: "1.1", Date: "2020.01.01.00.00.00", Author: "alice", State: "Exp"},
+		{Revision: "1.2.2.1", Date: "2020.01.03.00.00.00", Author: "carol", State: "Exp", NextRevision: "1.2.2.2"},
+		{Revision: "1.2.2.2", Date: "2020.01.04.00.00.00", Author: "carol", State: "dead"},
+	}
+	return f
+}
+
+func TestGraph(t *testing.T) {
+	g := graphTestFile().Graph(nil)
+	want := []GraphEdge{
+		{From: "1.1", To: "1.2"},
+		{From: "1.2", To: "1.2.2.1", Kind: GraphEdgeBranch, Label: "BR"},
+		{From: "1.2.2.1", To: "1.2.2.2"},
+		{From: "1.2", To: "1.3"},
+		{From: "1.2.2.2", To: "1.3", Kind: GraphEdgeMerge},
+	}
+	if diff := cmp.Diff(want, g.Edges); diff != "" {
+		t.Errorf("Edges mismatch (-want +got):\n%s", diff)
+	}
+
+	var dot strings.Builder
+	if err := g.WriteDOT(&dot, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	wantDOT := `digraph "a.txt" {
+	rankdir=LR;
+	node [shape=box];
+	"1.1" [label="1.1\nalice Exp\n2020-01-01"];
+	"1.2" [label="1.2\nalice Rel\n2020-01-02\nREL"];
+	"1.2.2.1" [label="1.2.2.1\ncarol Exp\n2020-01-03"];
+	"1.2.2.2" [label="1.2.2.2\ncarol dead\n2020-01-04", style=dashed, fontcolor=gray];
+	"1.3" [label="1.3\nbob Exp\n2020-01-05\nlocked by bob", color=red];
+	"1.1" -> "1.2";
+	"1.2" -> "1.2.2.1" [label="BR", color=blue];
+	"1.2.2.1" -> "1.2.2.2";
+	"1.2" -> "1.3";
+	"1.2.2.2" -> "1.3" [style=dashed, label="merge"];
+}
+`
+	if diff := cmp.Diff(wantDOT, dot.String()); diff != "" {
+		t.Errorf("WriteDOT() mismatch (-want +got):\n%s", diff)
+	}
+
+	var mermaid strings.Builder
+	if err := g.WriteMermaid(&mermaid); err != nil {
+		t.Fatal(err)
+	}
+	wantMermaid := `graph LR
+	r1_1["1.1<br/>alice Exp<br/>2020-01-01"]
+	r1_2["1.2<br/>alice Rel<br/>2020-01-02<br/>REL"]
+	r1_2_2_1["1.2.2.1<br/>carol Exp<br/>2020-01-03"]
+	r1_2_2_2["1.2.2.2<br/>carol dead<br/>2020-01-04"]
+	style r1_2_2_2 stroke-dasharray:4,color:gray
+	r1_3["1.3<br/>bob Exp<br/>2020-01-05<br/>locked by bob"]
+	style r1_3 stroke:red
+	r1_1 --> r1_2
+	r1_2 -->|"BR"| r1_2_2_1
+	r1_2_2_1 --> r1_2_2_2
+	r1_2 --> r1_3
+	r1_2_2_2 -.->|"merge"| r1_3
+`
+	if diff := cmp.Diff(wantMermaid, mermaid.String()); diff != "" {
+		t.Errorf("WriteMermaid() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGraphFilter(t *testing.T) {
+	g := graphTestFile().Graph(&StateFilter{State: "Exp"})
+	var revs []string
+	for _, n := range g.Nodes {
+		revs = append(revs, n.Revision)
+	}
+	if diff := cmp.Diff([]string{"1.1", "1.2.2.1", "1.3"}, revs); diff != "" {
+		t.Errorf("Nodes mismatch (-want +got):\n%s", diff)
+	}
+	want := []GraphEdge{
+		{From: "1.1", To: "1.2.2.1", Kind: GraphEdgeBranch, Label: "BR"},
+		{From: "1.1", To: "1.3"},
+	}
+	if diff := cmp.Diff(want, g.Edges); diff != "" {
+		t.Errorf("Edges mismatch (-want +got):\n%s", diff)
+	}
+}
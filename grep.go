@@ -0,0 +1,98 @@
+package rcs
+
+import (
+	"regexp"
+	"sort"
+)
+
+// GrepMatch is a line of a revision that matches a Grep pattern. Line is
+// 1-based.
+type GrepMatch struct {
+	Revision string
+	Line     int
+	Text     string
+}
+
+// PickaxeChange is a revision where the number of matches of a Pickaxe
+// pattern differs from its parent, i.e. where the string was introduced or
+// removed. Parent is empty for the initial revision, which counts as a change
+// from zero matches.
+type PickaxeChange struct {
+	Revision string
+	Parent   string
+	Before   int
+	After    int
+}
+
+// Grep searches the text of every revision of f matching filter (all of them
+// when filter is nil) for lines matching re. Revisions are reconstructed with
+// WalkRevisionTexts rather than checked out one by one. Matches are ordered
+// by revision, then line.
+func (f *File) Grep(re *regexp.Regexp, filter Filter) ([]GrepMatch, error) {
+	keep := f.filterRevisions(filter)
+	var matches []GrepMatch
+	err := f.WalkRevisionTexts(func(rev, text string) error {
+		if !keep(rev) {
+			return nil
+		}
+		for i, line := range splitLines(text) {
+			if re.MatchString(line) {
+				matches = append(matches, GrepMatch{Revision: rev, Line: i + 1, Text: line})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return compareRevisions(matches[i].Revision, matches[j].Revision) < 0
+	})
+	return matches, nil
+}
+
+// Pickaxe counts the matches of re on the lines of every revision and
+// reports the revisions matching filter whose count differs from that of
+// their parent. Changes are ordered by revision.
+func (f *File) Pickaxe(re *regexp.Regexp, filter Filter) ([]PickaxeChange, error) {
+	counts := map[string]int{}
+	err := f.WalkRevisionTexts(func(rev, text string) error {
+		n := 0
+		for _, line := range splitLines(text) {
+			n += len(re.FindAllStringIndex(line, -1))
+		}
+		counts[rev] = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	keep := f.filterRevisions(filter)
+	var changes []PickaxeChange
+	for rev, parent := range f.RevisionParents() {
+		after, ok := counts[rev]
+		if !ok || !keep(rev) {
+			continue
+		}
+		if before := counts[parent]; before != after {
+			changes = append(changes, PickaxeChange{Revision: rev, Parent: parent, Before: before, After: after})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return compareRevisions(changes[i].Revision, changes[j].Revision) < 0
+	})
+	return changes, nil
+}
+
+func (f *File) filterRevisions(filter Filter) func(rev string) bool {
+	if filter == nil {
+		return func(string) bool { return true }
+	}
+	kept := map[string]bool{}
+	for _, rh := range f.RevisionHeads {
+		if filter.Match(rh) {
+			kept[rh.Revision.String()] = true
+		}
+	}
+	return func(rev string) bool { return kept[rev] }
+}
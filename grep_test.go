@@ -0,0 +1,85 @@
+package rcs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGrep(t *testing.T) {
+	f := jsonTestFile()
+	re := regexp.MustCompile(`^[bB]$`)
+
+	got, err := f.Grep(re, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GrepMatch{
+		{Revision: "1.1", Line: 2, Text: "b"},
+		{Revision: "1.1.1.1", Line: 2, Text: "b"},
+		{Revision: "1.2", Line: 2, Text: "B"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Grep() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = f.Grep(re, &RevisionRangeFilter{From: "1.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want[2:], got); diff != "" {
+		t.Errorf("Grep(1.2:) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPickaxe(t *testing.T) {
+	f := jsonTestFile()
+
+	got, err := f.Pickaxe(regexp.MustCompile(`b`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PickaxeChange{
+		{Revision: "1.1", Before: 0, After: 1},
+		{Revision: "1.2", Parent: "1.1", Before: 1, After: 0},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Pickaxe(b) mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = f.Pickaxe(regexp.MustCompile(`d`), &StateFilter{State: "dead"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Pickaxe(d, dead) = %v, want none", got)
+	}
+	got, err = f.Pickaxe(regexp.MustCompile(`d`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []PickaxeChange{{Revision: "1.1.1.1", Parent: "1.1", Before: 0, After: 1}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Pickaxe(d) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRevisionRangeFilter(t *testing.T) {
+	for _, tc := range []struct {
+		filter RevisionRangeFilter
+		rev    Num
+		want   bool
+	}{
+		{RevisionRangeFilter{From: "1.2", To: "1.4"}, "1.3", true},
+		{RevisionRangeFilter{From: "1.2", To: "1.4"}, "1.5", false},
+		{RevisionRangeFilter{From: "1.2", To: "1.4"}, "1.2.1.1", false},
+		{RevisionRangeFilter{To: "1.3"}, "1.1", true},
+		{RevisionRangeFilter{From: "1.2.1.2"}, "1.2.1.3", true},
+		{RevisionRangeFilter{From: "1.2.1.2"}, "1.3", false},
+	} {
+		if got := tc.filter.Match(&RevisionHead{Revision: tc.rev}); got != tc.want {
+			t.Errorf("%+v.Match(%s) = %v, want %v", tc.filter, tc.rev, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// checkAccess refuses user, the caller when empty, if the master has an
+// access list that does not name them. Access list management commands are
+// not checked; who may change the list is left to the master's file
+// permissions.
+func checkAccess(f *rcs.File, master, user string) error {
+	if user == "" {
+		user = currentLoggedInUser()
+	}
+	if err := f.CheckAccess(user); err != nil {
+		return fmt.Errorf("%s: %w", master, err)
+	}
+	return nil
+}
+
+// splitUsers splits a comma separated login list as given to rcs -a and -e.
+func splitUsers(users string) []string {
+	var result []string
+	for _, u := range strings.Split(users, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// AccessListAdd is a subcommand `gorcs access-list add`
+//
+// Flags:
+//
+//	users: -users comma separated logins to add
+//	files: ... List of working files to process
+func AccessListAdd(users string, files ...string) error {
+	logins := splitUsers(users)
+	if len(logins) == 0 {
+		return fmt.Errorf("no users provided")
+	}
+	return updateAccessLists("access-list add", files, func(f *rcs.File) {
+		f.AddAccessUsers(logins...)
+	})
+}
+
+// AccessListRemove is a subcommand `gorcs access-list remove`
+//
+// Flags:
+//
+//	users: -users comma separated logins to remove
+//	files: ... List of working files to process
+func AccessListRemove(users string, files ...string) error {
+	logins := splitUsers(users)
+	if len(logins) == 0 {
+		return fmt.Errorf("no users provided")
+	}
+	return updateAccessLists("access-list remove", files, func(f *rcs.File) {
+		f.RemoveAccessUsers(logins...)
+	})
+}
+
+// AccessListClear is a subcommand `gorcs access-list clear`
+//
+// Flags:
+//
+//	files: ... List of working files to process
+func AccessListClear(files ...string) error {
+	return updateAccessLists("access-list clear", files, func(f *rcs.File) {
+		f.ClearAccessUsers()
+	})
+}
+
+func updateAccessLists(command string, files []string, fn func(f *rcs.File)) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no files provided")
+	}
+	for _, file := range files {
+		rcsFile, workingFile := resolveMaster(file, "")
+		hook := HookPayload{Command: command, File: workingFile, RCSFile: rcsFile, User: currentLoggedInUser()}
+		if err := updateMaster(rcsFile, func(f *rcs.File) error {
+			fn(f)
+			hook.Event, hook.Users = HookPreAccessChange, f.AccessUsers
+			return runPreHooks(hook)
+		}); err != nil {
+			return err
+		}
+		hook.Event = HookPostAccessChange
+		runPostHooks(hook)
+	}
+	return nil
+}
+
+// AccessListLs is a subcommand `gorcs access-list ls`
+//
+// Flags:
+//
+//	files: ... List of working files to process
+func AccessListLs(files ...string) error {
+	for _, file := range files {
+		rcsFile, _ := resolveMaster(file, "")
+
+		f, err := OpenFile(rcsFile, false)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", rcsFile, err)
+		}
+
+		parsedFile, err := rcs.ParseFile(f)
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("close %s: %w", rcsFile, err)
+		}
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", rcsFile, err)
+		}
+
+		if len(files) > 1 {
+			fmt.Printf("File: %s\n", file)
+		}
+		for _, user := range parsedFile.AccessUsers {
+			fmt.Println(user)
+		}
+		if len(files) > 1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 	rcs "github.com/arran4/golang-rcs"
-	"os"
 )
 
 // AccessListCopy is a subcommand `gorcs access-list copy`
@@ -36,7 +35,16 @@ func AccessListCopy(fromFile string, toFiles ...string) error {
 }
 
 func copyAccessListTo(fromRCS *rcs.File, toFile string) error {
-	f, err := os.Open(toFile)
+	toFile, _ = resolveMaster(toFile, "")
+	lock, err := lockMaster(toFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	f, err := repo.Storage.Open(toFile)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", toFile, err)
 	}
@@ -51,11 +59,19 @@ func copyAccessListTo(fromRCS *rcs.File, toFile string) error {
 
 	toRCS.CopyAccessList(fromRCS)
 
+	hook := HookPayload{Event: HookPreAccessChange, Command: "access-list copy", RCSFile: toFile, User: currentLoggedInUser(), Users: toRCS.AccessUsers}
+	if err := runPreHooks(hook); err != nil {
+		return err
+	}
+
 	content := toRCS.String()
 
-	if err := writeOutput(toFile, []byte(content), true); err != nil {
+	if err := lock.Commit([]byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write output to %s: %w", toFile, err)
 	}
+	fmt.Printf("Wrote: %s\n", toFile)
+	hook.Event = HookPostAccessChange
+	runPostHooks(hook)
 
 	return nil
 }
@@ -90,7 +106,16 @@ func AccessListAppend(fromFile string, toFiles ...string) error {
 }
 
 func appendAccessListTo(fromRCS *rcs.File, toFile string) error {
-	f, err := os.Open(toFile)
+	toFile, _ = resolveMaster(toFile, "")
+	lock, err := lockMaster(toFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	f, err := repo.Storage.Open(toFile)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", toFile, err)
 	}
@@ -105,11 +130,19 @@ func appendAccessListTo(fromRCS *rcs.File, toFile string) error {
 
 	toRCS.AppendAccessList(fromRCS)
 
+	hook := HookPayload{Event: HookPreAccessChange, Command: "access-list append", RCSFile: toFile, User: currentLoggedInUser(), Users: toRCS.AccessUsers}
+	if err := runPreHooks(hook); err != nil {
+		return err
+	}
+
 	content := toRCS.String()
 
-	if err := writeOutput(toFile, []byte(content), true); err != nil {
+	if err := lock.Commit([]byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write output to %s: %w", toFile, err)
 	}
+	fmt.Printf("Wrote: %s\n", toFile)
+	hook.Event = HookPostAccessChange
+	runPostHooks(hook)
 
 	return nil
 }
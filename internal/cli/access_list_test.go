@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAccessListCommands(t *testing.T) {
+	t.Setenv("USER", "jules")
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(fn+",v", []byte(changesetsTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AccessListAdd("jules,martha", fn); err != nil {
+		t.Fatalf("AccessListAdd() error = %v", err)
+	}
+	if err := AccessListRemove("martha", fn); err != nil {
+		t.Fatalf("AccessListRemove() error = %v", err)
+	}
+	f, err := parseMaster(fn + ",v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"jules"}, f.AccessUsers); diff != "" {
+		t.Fatalf("AccessUsers mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := StateAlter("Rel", "", fn); err != nil {
+		t.Fatalf("StateAlter() as listed user error = %v", err)
+	}
+	t.Setenv("USER", "martha")
+	if err := StateAlter("Exp", "", fn); !errors.Is(err, rcs.ErrAccessDenied) {
+		t.Fatalf("StateAlter() error = %v, want ErrAccessDenied", err)
+	}
+	if err := SymbolsAdd("REL", "", "", "", false, fn); !errors.Is(err, rcs.ErrAccessDenied) {
+		t.Fatalf("SymbolsAdd() error = %v, want ErrAccessDenied", err)
+	}
+	if err := Locks("lock", "1.2", fn); !errors.Is(err, rcs.ErrAccessDenied) {
+		t.Fatalf("Locks() error = %v, want ErrAccessDenied", err)
+	}
+	if err := Co("", true, false, "", true, "", "", "", fn); !errors.Is(err, rcs.ErrAccessDenied) {
+		t.Fatalf("Co -l error = %v, want ErrAccessDenied", err)
+	}
+
+	if err := AccessListClear(fn); err != nil {
+		t.Fatalf("AccessListClear() error = %v", err)
+	}
+	if err := StateAlter("Exp", "", fn); err != nil {
+		t.Fatalf("StateAlter() after clear error = %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 	rcs "github.com/arran4/golang-rcs"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -28,27 +27,47 @@ func BranchesDefaultSet(name string, files ...string) error {
 	}
 
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
-		}
-		b, err := os.ReadFile(rcsFile)
-		if err != nil {
-			return fmt.Errorf("read %s: %w", rcsFile, err)
-		}
-		parsed, err := rcs.ParseFile(strings.NewReader(string(b)))
-		if err != nil {
-			return fmt.Errorf("parse %s: %w", rcsFile, err)
-		}
-		parsed.Branch = defaultBranch
-		if err := os.WriteFile(rcsFile, []byte(parsed.String()), 0644); err != nil {
-			return fmt.Errorf("write %s: %w", rcsFile, err)
+		rcsFile, _ := resolveMaster(file, "")
+		if err := branchesDefaultSetFile(defaultBranch, rcsFile); err != nil {
+			return err
 		}
 		fmt.Printf("set default branch for %s to %s\n", filepath.Base(file), defaultBranch)
 	}
 	return nil
 }
 
+func branchesDefaultSetFile(defaultBranch, rcsFile string) error {
+	lock, err := lockMaster(rcsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+	b, err := readMaster(rcsFile)
+	if err != nil {
+		return err
+	}
+	parsed, err := rcs.ParseFile(strings.NewReader(string(b)))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", rcsFile, err)
+	}
+	if err := checkAccess(parsed, rcsFile, ""); err != nil {
+		return err
+	}
+	hook := HookPayload{Event: HookPreBranchChange, Command: "branches default set", RCSFile: rcsFile, User: currentLoggedInUser(), Branch: defaultBranch}
+	if err := runPreHooks(hook); err != nil {
+		return err
+	}
+	parsed.Branch = defaultBranch
+	if err := lock.Commit([]byte(parsed.String()), 0644); err != nil {
+		return err
+	}
+	hook.Event = HookPostBranchChange
+	runPostHooks(hook)
+	return nil
+}
+
 func normalizeDefaultBranch(name string) (string, error) {
 	parts := strings.Split(name, ".")
 	if len(parts) < 3 {
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// Changesets is a subcommand `gorcs changesets`
+//
+// Flags:
+//
+//	fuzz: -fuzz Maximum time between revisions of one changeset (e.g. 300s, 5m; default 300s)
+//	format: -format Output format: text or json (default text)
+//	output: -o --output Output file path
+//	force: -f --force Force overwrite output
+//	files: ... RCS files or directories to scan for ,v files
+func Changesets(fuzz, format, output string, force bool, files ...string) error {
+	opts := rcs.ChangesetOptions{}
+	if fuzz != "" {
+		d, err := parseFuzz(fuzz)
+		if err != nil {
+			return err
+		}
+		opts.Fuzz = d
+	}
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	masters, closeArchives, err := collectMasters(files)
+	if err != nil {
+		return err
+	}
+	defer closeArchives()
+	var named []rcs.NamedFile
+	for _, m := range masters {
+		f, err := parseMaster(m)
+		if err != nil {
+			return err
+		}
+		named = append(named, rcs.NamedFile{Name: workingName(m), File: f})
+	}
+	sets, err := rcs.BuildChangesets(named, opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "", "text":
+		writeChangesetsText(&buf, sets)
+	case "json":
+		b, err := json.MarshalIndent(sets, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error serializing changesets: %w", err)
+		}
+		buf.Write(b)
+		buf.WriteString("\n")
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	if output != "" && output != "-" {
+		return writeOutput(output, buf.Bytes(), force)
+	}
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+func parseFuzz(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fuzz %q: %w", s, err)
+	}
+	return d, nil
+}
+
+func parseMaster(fn string) (*rcs.File, error) {
+	return repo.Parse(fn)
+}
+
+// writeChangesetsText writes changesets in the cvsps PatchSet layout.
+func writeChangesetsText(w io.Writer, sets []*rcs.Changeset) {
+	for _, cs := range sets {
+		fmt.Fprintln(w, "---------------------")
+		fmt.Fprintf(w, "PatchSet %d\n", cs.ID)
+		fmt.Fprintf(w, "Date: %s\n", cs.Date.UTC().Format("2006/01/02 15:04:05"))
+		fmt.Fprintf(w, "Author: %s\n", cs.Author)
+		fmt.Fprintf(w, "Branch: %s\n", cs.Branch)
+		tag := "(none)"
+		if len(cs.Tags) > 0 {
+			tag = strings.Join(cs.Tags, " ")
+		}
+		fmt.Fprintf(w, "Tag: %s\n", tag)
+		if cs.CommitID != "" {
+			fmt.Fprintf(w, "Commitid: %s\n", cs.CommitID)
+		}
+		fmt.Fprintln(w, "Log:")
+		fmt.Fprint(w, cs.Log)
+		if !strings.HasSuffix(cs.Log, "\n") {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Members:")
+		for _, m := range cs.Members {
+			from := m.Parent
+			if from == "" {
+				from = "INITIAL"
+			}
+			to := m.Revision
+			if m.Dead() {
+				to += "(DEAD)"
+			}
+			fmt.Fprintf(w, "\t%s:%s->%s\n", m.File, from, to)
+		}
+		fmt.Fprintln(w)
+	}
+}
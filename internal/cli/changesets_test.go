@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const changesetsTestMaster = `head	1.2;
+access;
+symbols;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author alice;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@second
+@
+text
+@b
+@
+
+
+1.1
+log
+@first
+@
+text
+@d1 1
+a1 1
+a
+@
+`
+
+func TestChangesets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "RCS"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range []string{filepath.Join(dir, "RCS", "one.txt,v"), filepath.Join(dir, "two.txt,v")} {
+		if err := os.WriteFile(fn, []byte(changesetsTestMaster), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(t.TempDir(), "out.txt")
+	if err := Changesets("", "text", out, false, dir); err != nil {
+		t.Fatalf("Changesets() error = %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := filepath.Join(dir, "one.txt")
+	two := filepath.Join(dir, "two.txt")
+	want := "---------------------\n" +
+		"PatchSet 1\n" +
+		"Date: 2021/03/03 05:06:07\n" +
+		"Author: alice\n" +
+		"Branch: HEAD\n" +
+		"Tag: (none)\n" +
+		"Log:\n" +
+		"first\n" +
+		"\n" +
+		"Members:\n" +
+		"\t" + one + ":INITIAL->1.1\n" +
+		"\t" + two + ":INITIAL->1.1\n" +
+		"\n" +
+		"---------------------\n" +
+		"PatchSet 2\n" +
+		"Date: 2021/03/04 05:06:07\n" +
+		"Author: alice\n" +
+		"Branch: HEAD\n" +
+		"Tag: (none)\n" +
+		"Log:\n" +
+		"second\n" +
+		"\n" +
+		"Members:\n" +
+		"\t" + one + ":1.1->1.2\n" +
+		"\t" + two + ":1.1->1.2\n" +
+		"\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("Changesets() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWorkingName(t *testing.T) {
+	for in, want := range map[string]string{
+		"a/RCS/b.txt,v":   filepath.Join("a", "b.txt"),
+		"a/Attic/b.txt,v": filepath.Join("a", "b.txt"),
+		"b.txt,v":         "b.txt",
+		"RCS/b.txt,v":     "b.txt",
+	} {
+		if got := workingName(in); got != want {
+			t.Errorf("workingName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
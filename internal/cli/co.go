@@ -8,6 +8,7 @@ import (
 	"time"
 
 	rcs "github.com/arran4/golang-rcs"
+	"github.com/arran4/golang-rcs/repository"
 )
 
 type COVerdict struct {
@@ -31,19 +32,25 @@ type COVerdict struct {
 //	quiet: -q suppress status output
 //	date: -d date to check out
 //	zone: -z zone for date parsing (e.g. "LT", "UTC", "-0700", "America/New_York")
+//	suffixes: -x RCS file suffix list separated by / (default ,v/)
 //	files: ... List of working files to process
-func Co(revision string, lock, unlock bool, user string, quiet bool, checkoutDate, checkoutZone string, files ...string) error {
+func Co(revision string, lock, unlock bool, user string, quiet bool, checkoutDate, checkoutZone, suffixes string, files ...string) error {
 	if lock && unlock {
 		return fmt.Errorf("cannot combine -l and -u")
 	}
 	if user == "" {
 		user = currentLoggedInUser()
 	}
+	defaults := rcsInit()
+	if checkoutZone == "" {
+		checkoutZone = defaults.Zone
+	}
+	quiet = quiet || defaults.Quiet
 	if len(files) == 0 {
 		return fmt.Errorf("no files provided")
 	}
 	for _, file := range files {
-		result, err := coFile(revision, lock, unlock, user, quiet, checkoutDate, checkoutZone, file)
+		result, err := coFile(revision, lock, unlock, user, quiet, checkoutDate, checkoutZone, suffixes, file)
 		if err != nil {
 			return err
 		}
@@ -61,26 +68,34 @@ func Co(revision string, lock, unlock bool, user string, quiet bool, checkoutDat
 	return nil
 }
 
-func coFile(revision string, lock, unlock bool, user string, quiet bool, checkoutDate, checkoutZone string, workingFile string) (COVerdict, error) {
-	rcsFile := workingFile
-	if !strings.HasSuffix(rcsFile, ",v") {
-		rcsFile += ",v"
+func coFile(revision string, lock, unlock bool, user string, quiet bool, checkoutDate, checkoutZone, suffixes string, file string) (COVerdict, error) {
+	rcsFile, workingFile := resolveMaster(file, suffixes)
+	var held repository.Lock
+	if lock || unlock {
+		l, err := lockMaster(rcsFile)
+		if err != nil {
+			return COVerdict{}, err
+		}
+		defer func() {
+			_ = l.Release()
+		}()
+		held = l
 	}
-	f, err := os.Open(rcsFile)
+	parsed, err := repo.Parse(rcsFile)
 	if err != nil {
-		return COVerdict{}, fmt.Errorf("open %s: %w", rcsFile, err)
+		return COVerdict{}, err
 	}
-	defer func() { _ = f.Close() }()
 
-	rcsStat, err := os.Stat(rcsFile)
+	rcsStat, err := statMaster(rcsFile)
 	if err != nil {
 		return COVerdict{}, fmt.Errorf("stat %s: %w", rcsFile, err)
 	}
 	rcsMode := rcsStat.Mode()
 
-	parsed, err := rcs.ParseFile(f)
-	if err != nil {
-		return COVerdict{}, fmt.Errorf("parse %s: %w", rcsFile, err)
+	if held != nil {
+		if err := checkAccess(parsed, rcsFile, user); err != nil {
+			return COVerdict{}, err
+		}
 	}
 
 	ops := make([]any, 0, 3)
@@ -109,6 +124,19 @@ func coFile(revision string, lock, unlock bool, user string, quiet bool, checkou
 		return COVerdict{}, fmt.Errorf("co %s: %w", rcsFile, err)
 	}
 
+	hook := HookPayload{Command: "co", File: workingFile, RCSFile: rcsFile, Revision: verdict.Revision, User: user}
+	var postEvent HookEvent
+	if lock {
+		hook.Event, postEvent = HookPreLock, HookPostLock
+	} else if unlock {
+		hook.Event, postEvent = HookPreUnlock, HookPostUnlock
+	}
+	if hook.Event != "" {
+		if err := runPreHooks(hook); err != nil {
+			return COVerdict{}, err
+		}
+	}
+
 	perm := rcsMode.Perm()
 	if isLockedBy(parsed, user, verdict.Revision) {
 		perm |= 0200
@@ -116,18 +144,26 @@ func coFile(revision string, lock, unlock bool, user string, quiet bool, checkou
 		perm &= ^os.FileMode(0222)
 	}
 
-	if err := os.WriteFile(workingFile, []byte(verdict.Content), perm); err != nil {
-		return COVerdict{}, fmt.Errorf("write %s: %w", workingFile, err)
-	}
-	if err := os.Chmod(workingFile, perm); err != nil {
-		return COVerdict{}, fmt.Errorf("chmod %s: %w", workingFile, err)
+	if dryRun {
+		fmt.Printf("Would write: %s (%d bytes, mode %04o)\n", workingFile, len(verdict.Content), perm)
+	} else {
+		if err := os.WriteFile(workingFile, []byte(verdict.Content), perm); err != nil {
+			return COVerdict{}, fmt.Errorf("write %s: %w", workingFile, err)
+		}
+		if err := os.Chmod(workingFile, perm); err != nil {
+			return COVerdict{}, fmt.Errorf("chmod %s: %w", workingFile, err)
+		}
 	}
 
-	if verdict.FileModified {
-		if err := os.WriteFile(rcsFile, []byte(parsed.String()), rcsMode.Perm()); err != nil {
-			return COVerdict{}, fmt.Errorf("write %s: %w", rcsFile, err)
+	if verdict.FileModified && held != nil {
+		if err := held.Commit([]byte(parsed.String()), rcsMode.Perm()); err != nil {
+			return COVerdict{}, err
 		}
 	}
+	if postEvent != "" {
+		hook.Event = postEvent
+		runPostHooks(hook)
+	}
 	return COVerdict{
 		File:          workingFile,
 		RCSFile:       rcsFile,
@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"io"
+
+	"github.com/arran4/golang-rcs/repository"
+)
+
+// dryRun is set by EnableDryRun. Commands then leave working files alone
+// and skip post- hooks; pre- hooks still run so a rejection shows up.
+var dryRun bool
+
+// EnableDryRun makes the commands that modify masters work on an in-memory
+// copy and print a unified diff of every master they would write to w,
+// without writing anything.
+func EnableDryRun(w io.Writer) {
+	repo = repository.New(repository.DryRun(repo.Storage, w))
+	dryRun = true
+}
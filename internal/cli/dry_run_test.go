@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arran4/golang-rcs/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+// useDryRun enables dry-run mode for the rest of the test.
+func useDryRun(t *testing.T, w *bytes.Buffer) {
+	t.Helper()
+	prev := repo
+	EnableDryRun(w)
+	t.Cleanup(func() {
+		repo = prev
+		dryRun = false
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(fn+",v", []byte(changesetsTestMaster), 0444); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	useDryRun(t, &out)
+
+	if err := StateAlter("Rel", "1.2", fn); err != nil {
+		t.Fatalf("StateAlter() error = %v", err)
+	}
+	if err := Co("", true, false, "alice", true, "", "", "", fn); err != nil {
+		t.Fatalf("Co() error = %v", err)
+	}
+
+	b, err := os.ReadFile(fn + ",v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != changesetsTestMaster {
+		t.Errorf("master was written in a dry run:\n%s", b)
+	}
+	if _, err := os.Stat(fn); !os.IsNotExist(err) {
+		t.Errorf("working file was written in a dry run: %v", err)
+	}
+	if _, err := os.Stat(repository.LockFileName(fn + ",v")); !os.IsNotExist(err) {
+		t.Errorf("lock file was left behind: %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"+++ " + fn + ",v (dry run)\n",
+		"-date\t2021.03.04.05.06.07;\tauthor alice;\tstate Exp;\n",
+		"+date\t2021.03.04.05.06.07;\tauthor alice;\tstate Rel;\n",
+		"-locks; strict;\n",
+		"+\talice:1.2; strict;\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("preview missing %q in:\n%s", want, got)
+		}
+	}
+	// Later commands see the previewed changes.
+	f, err := parseMaster(fn + ",v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.RevisionHeads[0].State != "Rel" || len(f.Locks) != 1 {
+		t.Errorf("previewed master state = %q, locks = %v", f.RevisionHeads[0].State, f.Locks)
+	}
+}
+
+func TestApplyGlobalFlags(t *testing.T) {
+	t.Setenv(JournalDirEnv, t.TempDir())
+	prev := repo
+	t.Cleanup(func() {
+		repo = prev
+		dryRun = false
+	})
+
+	got := ApplyGlobalFlags([]string{"gorcs", "--dry-run=false", "state", "--dry-run"})
+	if diff := cmp.Diff([]string{"gorcs", "state", "--dry-run"}, got); diff != "" || dryRun {
+		t.Errorf("ApplyGlobalFlags(--dry-run=false) mismatch (-want +got):\n%s, dryRun = %v", diff, dryRun)
+	}
+	if _, ok := repo.Storage.(*repository.JournaledStorage); !ok {
+		t.Errorf("storage = %T, want *repository.JournaledStorage", repo.Storage)
+	}
+	repo = prev
+	got = ApplyGlobalFlags([]string{"gorcs", "-dry-run", "state", "alter"})
+	if diff := cmp.Diff([]string{"gorcs", "state", "alter"}, got); diff != "" || !dryRun {
+		t.Errorf("ApplyGlobalFlags(-dry-run) mismatch (-want +got):\n%s, dryRun = %v", diff, dryRun)
+	}
+	// The dry run wraps the journal, so a preview is never journaled.
+	d, ok := repo.Storage.(*repository.DryRunStorage)
+	if !ok {
+		t.Fatalf("storage = %T, want *repository.DryRunStorage", repo.Storage)
+	}
+	if _, ok := d.Base.(*repository.JournaledStorage); !ok {
+		t.Errorf("dry run base = %T, want *repository.JournaledStorage", d.Base)
+	}
+}
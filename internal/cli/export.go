@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/arran4/golang-rcs/svndump"
+)
+
+// ExportSvnDump is a subcommand `gorcs export svn-dump`
+//
+// Flags:
+//
+//	fuzz: -fuzz Maximum time between revisions of one changeset (e.g. 300s, 5m; default 300s)
+//	output: -o --output Output file path
+//	force: -f --force Force overwrite output
+//	files: ... RCS files or directories to scan for ,v files
+func ExportSvnDump(fuzz, output string, force bool, files ...string) error {
+	opts := svndump.Options{}
+	if fuzz != "" {
+		d, err := parseFuzz(fuzz)
+		if err != nil {
+			return err
+		}
+		opts.Changesets.Fuzz = d
+	}
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	named, err := loadNamedMasters(files)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := svndump.Export(&buf, named, opts); err != nil {
+		return err
+	}
+	if output != "" && output != "-" {
+		return writeOutput(output, buf.Bytes(), force)
+	}
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+// loadNamedMasters parses every master found under paths, naming each after
+// its working file relative to the directory it was found in.
+func loadNamedMasters(paths []string) ([]rcs.NamedFile, error) {
+	var named []rcs.NamedFile
+	for _, p := range paths {
+		found, err := loadNamedMastersIn(p)
+		if err != nil {
+			return nil, err
+		}
+		named = append(named, found...)
+	}
+	return named, nil
+}
+
+// loadNamedMastersIn parses the masters found under the single path p.
+func loadNamedMastersIn(p string) ([]rcs.NamedFile, error) {
+	masters, closeArchives, err := collectMasters([]string{p})
+	if err != nil {
+		return nil, err
+	}
+	defer closeArchives()
+	base := p
+	if st, err := statMaster(p); err != nil || !st.IsDir() {
+		base = ""
+	}
+	var named []rcs.NamedFile
+	for _, m := range masters {
+		f, err := parseMaster(m)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(m)
+		if base != "" {
+			if rel, err := filepath.Rel(base, m); err == nil {
+				name = rel
+			}
+		}
+		named = append(named, rcs.NamedFile{Name: workingName(name), File: f})
+	}
+	return named, nil
+}
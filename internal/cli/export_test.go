@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportSvnDump(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub", "RCS"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "RCS", "one.txt,v"), []byte(changesetsTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "out.dump")
+	if err := ExportSvnDump("", out, false, dir); err != nil {
+		t.Fatalf("ExportSvnDump() error = %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"SVN-fs-dump-format-version: 2\n",
+		"Revision-number: 3\n",
+		"Node-path: trunk/sub/one.txt\nNode-kind: file\nNode-action: add\n",
+		"Node-path: trunk/sub/one.txt\nNode-kind: file\nNode-action: change\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dump missing %q", want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	rcs "github.com/arran4/golang-rcs"
 	"io"
@@ -15,16 +17,29 @@ import (
 //	force: -f --force Force overwrite output
 //	keep-truncated-years: --keep-truncated-years Keep truncated years (do not expand to 4 digits)
 //	mmap: -m --mmap Use mmap to read file
+//	tolerant: --tolerant Recover what can be parsed from damaged files, reporting what was dropped
+//	errorFormat: --error-format Report parse errors as text (compiler style) or json and go on with the other files
 //	files: ... List of files to process, or - for stdin
-func Format(output string, force, keepTruncatedYears, useMmap bool, files ...string) error {
+func Format(output string, force, keepTruncatedYears, useMmap, tolerant bool, errorFormat string, files ...string) error {
 	var err error
 	if files, err = ensureFiles(files); err != nil {
 		return err
 	}
-	return runFormat(os.Stdin, os.Stdout, output, force, false, false, keepTruncatedYears, useMmap, files...)
+	return runFormat(os.Stdin, os.Stdout, os.Stderr, output, force, false, false, keepTruncatedYears, useMmap, tolerant, errorFormat, files...)
 }
 
-func runFormat(stdin io.Reader, stdout io.Writer, output string, force, overwrite, stdoutFlag, keepTruncatedYears, useMmap bool, files ...string) error {
+// runFormat parses and rewrites each file. The first parse error stops it,
+// unless errorFormat is given: then parse errors are reported to stderr in
+// that format and the remaining files are still processed. When
+// tolerant is set, what could be recovered from a damaged file is written
+// to -o or stdout and each problem is reported; the file itself is never
+// overwritten, and it still counts as failed.
+func runFormat(stdin io.Reader, stdout, stderr io.Writer, output string, force, overwrite, stdoutFlag, keepTruncatedYears, useMmap, tolerant bool, errorFormat string, files ...string) error {
+	switch errorFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unknown error format %q: want text or json", errorFormat)
+	}
 	if output != "" && output != "-" && len(files) > 1 {
 		return fmt.Errorf("cannot specify output file with multiple input files")
 	}
@@ -38,13 +53,19 @@ func runFormat(stdin io.Reader, stdout io.Writer, output string, force, overwrit
 		return fmt.Errorf("cannot specify both output and stdout")
 	}
 
+	failed := 0
 	for _, fn := range files {
 		var content string
+		var diags []*rcs.ParseError
 		var err error
 
 		if fn == "-" {
-			content, err = processReader(stdin, keepTruncatedYears)
+			content, diags, err = processReader(stdin, keepTruncatedYears, tolerant)
 			if err != nil {
+				if errorFormat != "" && reportParseError(stderr, "<stdin>", errorFormat, err) {
+					failed++
+					continue
+				}
 				return fmt.Errorf("error parsing stdin: %w", err)
 			}
 		} else {
@@ -58,21 +79,46 @@ func runFormat(stdin io.Reader, stdout io.Writer, output string, force, overwrit
 					_ = f.Close()
 				}()
 
-				content, err = processReader(f, keepTruncatedYears)
+				content, diags, err = processReader(f, keepTruncatedYears, tolerant)
 				return err
 			}()
 			if err != nil {
+				if errorFormat != "" && reportParseError(stderr, fn, errorFormat, err) {
+					failed++
+					continue
+				}
 				return fmt.Errorf("error parsing file %s: %w", fn, err)
 			}
 		}
+		name := fn
+		if fn == "-" {
+			name = "<stdin>"
+		}
+		for _, d := range diags {
+			reportParseError(stderr, name, errorFormat, d)
+		}
+		if len(diags) > 0 {
+			// A partial recovery must never replace the damaged original.
+			failed++
+			if output == "" && !stdoutFlag && fn != "-" {
+				_, _ = fmt.Fprintf(stderr, "%s: not overwritten with a partial recovery; use -o to write it elsewhere\n", name)
+				continue
+			}
+		}
 
 		if output == "" && force {
 			if fn == "-" {
 				return fmt.Errorf("cannot overwrite stdin")
 			}
-			if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			hook := HookPayload{Event: HookPreFormat, Command: "format", RCSFile: fn, User: currentLoggedInUser()}
+			if err := runPreHooks(hook); err != nil {
+				return err
+			}
+			if err := writeMaster(fn, []byte(content), 0644); err != nil {
 				return fmt.Errorf("error writing file %s: %w", fn, err)
 			}
+			hook.Event = HookPostFormat
+			runPostHooks(hook)
 		} else if output != "" && output != "-" {
 			if err := writeOutput(output, []byte(content), force); err != nil {
 				return err
@@ -87,13 +133,43 @@ func runFormat(stdin io.Reader, stdout io.Writer, output string, force, overwrit
 			}
 		}
 	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed to parse", failed, len(files))
+	}
 	return nil
 }
 
-func processReader(r io.Reader, keepTruncatedYears bool) (string, error) {
-	parsedFile, err := rcs.ParseFile(r)
+// reportParseError writes err to w when it is an *rcs.ParseError, as a
+// compiler style diagnostic or a line of JSON, and reports whether it did.
+func reportParseError(w io.Writer, name, errorFormat string, err error) bool {
+	var pe *rcs.ParseError
+	if !errors.As(err, &pe) {
+		return false
+	}
+	pe.Filename = name
+	if errorFormat == "json" {
+		b, jsonErr := json.Marshal(pe)
+		if jsonErr != nil {
+			return false
+		}
+		_, _ = fmt.Fprintf(w, "%s\n", b)
+		return true
+	}
+	_, _ = io.WriteString(w, pe.Diagnostic())
+	return true
+}
+
+func processReader(r io.Reader, keepTruncatedYears, tolerant bool) (string, []*rcs.ParseError, error) {
+	var parsedFile *rcs.File
+	var diags []*rcs.ParseError
+	var err error
+	if tolerant {
+		parsedFile, diags, err = rcs.ParseFileTolerant(r, rcs.TolerantOptions{})
+	} else {
+		parsedFile, err = rcs.ParseFile(r)
+	}
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if !keepTruncatedYears {
 		parsedFile.DateYearPrefixTruncated = false
@@ -106,5 +182,5 @@ func processReader(r io.Reader, keepTruncatedYears bool) (string, error) {
 			}
 		}
 	}
-	return parsedFile.String(), nil
+	return parsedFile.String(), diags, nil
 }
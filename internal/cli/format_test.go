@@ -3,8 +3,15 @@ package cli
 import (
 	"bytes"
 	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	rcs "github.com/arran4/golang-rcs"
 )
 
 //go:embed testdata/truncated_date.go,v
@@ -16,8 +23,8 @@ func TestFormat_KeepTruncatedYears(t *testing.T) {
 	var buf bytes.Buffer
 
 	// Run Format with keepTruncatedYears=true, stdout=true
-	// Signature: func runFormat(stdin io.Reader, stdout io.Writer, output string, force, overwrite, stdout, keepTruncatedYears, useMmap bool, files ...string)
-	if err := runFormat(r, &buf, "", false, false, true, true, false, "-"); err != nil {
+	// Signature: func runFormat(stdin io.Reader, stdout, stderr io.Writer, output string, force, overwrite, stdout, keepTruncatedYears, useMmap, tolerant bool, errorFormat string, files ...string)
+	if err := runFormat(r, &buf, io.Discard, "", false, false, true, true, false, false, "", "-"); err != nil {
 		t.Errorf("runFormat failed: %v", err)
 	}
 
@@ -33,7 +40,7 @@ func TestFormat_KeepTruncatedYears(t *testing.T) {
 	buf.Reset()
 
 	// Run Format with keepTruncatedYears=false (default), stdout=true
-	if err := runFormat(r, &buf, "", false, false, true, false, false, "-"); err != nil {
+	if err := runFormat(r, &buf, io.Discard, "", false, false, true, false, false, false, "", "-"); err != nil {
 		t.Errorf("runFormat failed: %v", err)
 	}
 
@@ -44,3 +51,101 @@ func TestFormat_KeepTruncatedYears(t *testing.T) {
 		t.Errorf("Expected 4-digit year in output when keepTruncatedYears=false (default), got:\n%s", output)
 	}
 }
+
+func TestFormat_ParseErrorReport(t *testing.T) {
+	input := "head\t1.1;\naccess;\nsymbols\tfoo:1.1 bar;\nlocks; strict;\n"
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad,v")
+	if err := os.WriteFile(bad, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+	err := runFormat(nil, io.Discard, &stderr, "-", false, false, false, false, false, false, "text", bad)
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 files failed to parse") {
+		t.Fatalf("runFormat() error = %v, want failure summary", err)
+	}
+	want := bad + ":3:20: admin \"symbols\": "
+	if !strings.HasPrefix(stderr.String(), want) || !strings.HasSuffix(stderr.String(), "\tsymbols\tfoo:1.1 bar;\n\t       \t           ^\n") {
+		t.Errorf("text report = %q, want prefix %q and a caret excerpt", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	if err := runFormat(strings.NewReader(input), io.Discard, &stderr, "", false, false, true, false, false, false, "json", "-"); err == nil {
+		t.Fatal("runFormat() error = nil, want failure")
+	}
+	var got struct {
+		Filename, Section, Field, Source, Message string
+		Line, Column                              int
+		Offset                                    int64
+	}
+	if err := json.Unmarshal(stderr.Bytes(), &got); err != nil {
+		t.Fatalf("json report %q: %v", stderr.String(), err)
+	}
+	if got.Filename != "<stdin>" || got.Line != 3 || got.Column != 20 || got.Offset != 37 || got.Field != "symbols" || got.Message == "" {
+		t.Errorf("json report = %+v", got)
+	}
+
+	if err := runFormat(nil, io.Discard, io.Discard, "-", false, false, false, false, false, false, "xml", bad); err == nil {
+		t.Error("runFormat() with unknown error format: error = nil")
+	}
+
+	var out bytes.Buffer
+	stderr.Reset()
+	if err := runFormat(nil, &out, &stderr, "-", false, false, false, false, false, true, "", bad); err == nil {
+		t.Fatal("runFormat() tolerant error = nil, want the damaged file counted as failed")
+	}
+	if !strings.HasPrefix(out.String(), "head\t1.1;\naccess;\nlocks; strict;\n") {
+		t.Errorf("tolerant output = %q, want the recovered admin section", out.String())
+	}
+	if !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("tolerant report = %q, want prefix %q", stderr.String(), want)
+	}
+
+	// In place, the damaged master is left alone.
+	original, err := os.ReadFile(bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if err := runFormat(nil, io.Discard, &stderr, "", true, false, false, false, false, true, "", bad); err == nil {
+		t.Error("runFormat() tolerant in place error = nil, want failure")
+	}
+	if b, err := os.ReadFile(bad); err != nil || !bytes.Equal(b, original) {
+		t.Errorf("damaged master was overwritten: %q, %v", b, err)
+	}
+	if !strings.Contains(stderr.String(), "not overwritten with a partial recovery") {
+		t.Errorf("tolerant in place report = %q", stderr.String())
+	}
+}
+
+func TestFormat_ParseErrorStopsWithoutErrorFormat(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad,v")
+	if err := os.WriteFile(bad, []byte("head\t1.1;\naccess;\nsymbols\tfoo:1.1 bar;\nlocks; strict;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	good := filepath.Join(dir, "good,v")
+	if err := os.WriteFile(good, []byte(changesetsTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	err := runFormat(nil, &out, &stderr, "", false, false, true, false, false, false, "", bad, good)
+	var pe *rcs.ParseError
+	if !errors.As(err, &pe) || !strings.Contains(err.Error(), "error parsing file "+bad) {
+		t.Fatalf("runFormat() error = %v, want the parse error of %s", err, bad)
+	}
+	if out.Len() != 0 || stderr.Len() != 0 {
+		t.Errorf("runFormat() went on after the parse error: stdout %q, stderr %q", out.String(), stderr.String())
+	}
+
+	out.Reset()
+	err = runFormat(nil, &out, &stderr, "", false, false, true, false, false, false, "text", bad, good)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 files failed to parse") {
+		t.Fatalf("runFormat(text) error = %v, want failure summary", err)
+	}
+	if !strings.Contains(out.String(), "head\t1.2;") {
+		t.Errorf("runFormat(text) did not format the good file: %q", out.String())
+	}
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ApplyGlobalFlags prepares the commands for the command line, which starts
+// with the program name, and returns it without the flags it handled. It
+// turns on the journal first when JournalDirEnv is set, so every master a
+// command rewrites is backed up, and then takes the flags given before the
+// subcommand, which apply to every command:
+//
+//	--dry-run  print the changes to RCS files as unified diffs instead of
+//	           writing them
+//
+// The generated command parser knows nothing of these, so they are taken
+// off the command line before it runs.
+func ApplyGlobalFlags(command []string) []string {
+	EnableJournal(command)
+	if len(command) == 0 {
+		return command
+	}
+	prog, args := command[0], command[1:]
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if !strings.HasPrefix(args[0], "-") || name != "dry-run" {
+			break
+		}
+		on := true
+		if hasValue {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				break
+			}
+			on = b
+		}
+		if on && !dryRun {
+			EnableDryRun(os.Stdout)
+		}
+		args = args[1:]
+	}
+	return append([]string{prog}, args...)
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// Graph is a subcommand `gorcs graph`
+//
+// Flags:
+//
+//	format: -format Output format: dot or mermaid (default dot)
+//	filterStr: -F --filter Only graph revisions matching this filter (see gorcs log filter-reference)
+//	output: -o --output Output file path
+//	force: -f --force Force overwrite output
+//	files: ... List of working files to process
+func Graph(format, filterStr, output string, force bool, files ...string) error {
+	var filter rcs.Filter
+	if filterStr != "" {
+		f, err := rcs.ParseFilter(filterStr)
+		if err != nil {
+			return fmt.Errorf("parse filter %q: %w", filterStr, err)
+		}
+		filter = f
+	}
+	switch format {
+	case "", "dot", "mermaid":
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	var buf bytes.Buffer
+	for i, file := range files {
+		master, _ := resolveMaster(file, "")
+		f, err := parseMaster(master)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		g := f.Graph(filter)
+		if format == "mermaid" {
+			err = g.WriteMermaid(&buf)
+		} else {
+			err = g.WriteDOT(&buf, workingName(master))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if output != "" && output != "-" {
+		return writeOutput(output, buf.Bytes(), force)
+	}
+	_, err := os.Stdout.Write(buf.Bytes())
+	return err
+}
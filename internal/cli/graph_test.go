@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	dir := t.TempDir()
+	master := filepath.Join(dir, "a.txt,v")
+	if err := os.WriteFile(master, []byte(htmlTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "a.mmd")
+	if err := Graph("mermaid", "state=Exp", out, false, master); err != nil {
+		t.Fatalf("Graph() error = %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"graph LR\n", `r1_1 -->|"FIX"| r1_1_2_1`, "r1_1 --> r1_2\n"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("output missing %q in:\n%s", want, b)
+		}
+	}
+
+	if err := Graph("svg", "", "", false, master); err == nil {
+		t.Error("Graph(svg) error = nil, want error")
+	}
+}
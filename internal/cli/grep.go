@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// Grep is a subcommand `gorcs grep`
+//
+// Flags:
+//
+//	pattern: -e --regexp Regular expression to search for (default: the first argument)
+//	filterStr: -F --filter Only search revisions matching this filter (see gorcs log filter-reference)
+//	revisions: -r --revisions Only search revisions in this range: rev, from:to, from: or :to
+//	pickaxe: -S --pickaxe Report revisions where the number of matches changed instead of matching lines
+//	ignoreCase: -i --ignore-case Match case insensitively
+//	output: -o --output Output file path
+//	force: -f --force Force overwrite output
+//	files: ... RCS files or directories to scan for ,v files
+func Grep(pattern, filterStr, revisions string, pickaxe, ignoreCase bool, output string, force bool, files ...string) error {
+	if pattern == "" {
+		if len(files) == 0 {
+			return errors.New("no pattern given")
+		}
+		pattern, files = files[0], files[1:]
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("parse pattern: %w", err)
+	}
+	var filter rcs.Filter
+	if filterStr != "" {
+		f, err := rcs.ParseFilter(filterStr)
+		if err != nil {
+			return fmt.Errorf("parse filter %q: %w", filterStr, err)
+		}
+		filter = f
+	}
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	masters, closeArchives, err := collectMasters(files)
+	if err != nil {
+		return err
+	}
+	defer closeArchives()
+
+	var buf bytes.Buffer
+	for _, m := range masters {
+		f, err := parseMaster(m)
+		if err != nil {
+			return err
+		}
+		name := workingName(m)
+		fileFilter := filter
+		if revisions != "" {
+			rf, err := parseRevisionRange(f, revisions)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			fileFilter = rf
+			if filter != nil {
+				fileFilter = &rcs.AndFilter{Filters: []rcs.Filter{filter, rf}}
+			}
+		}
+		if pickaxe {
+			changes, err := f.Pickaxe(re, fileFilter)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			for _, c := range changes {
+				parent := c.Parent
+				if parent == "" {
+					parent = "none"
+				}
+				fmt.Fprintf(&buf, "%s:%s: %d -> %d matches (parent %s)\n", name, c.Revision, c.Before, c.After, parent)
+			}
+			continue
+		}
+		matches, err := f.Grep(re, fileFilter)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, m := range matches {
+			fmt.Fprintf(&buf, "%s:%s:%d:%s\n", name, m.Revision, m.Line, m.Text)
+		}
+	}
+
+	if output != "" && output != "-" {
+		return writeOutput(output, buf.Bytes(), force)
+	}
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+// parseRevisionRange turns "rev", "from:to", "from:" or ":to" into a range
+// filter, resolving each end as a revision number, symbol or branch.
+func parseRevisionRange(f *rcs.File, s string) (*rcs.RevisionRangeFilter, error) {
+	from, to, isRange := strings.Cut(s, ":")
+	if !isRange {
+		to = from
+	}
+	var err error
+	rf := &rcs.RevisionRangeFilter{}
+	if from != "" {
+		if rf.From, err = f.ResolveRevision(from); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if rf.To, err = f.ResolveRevision(to); err != nil {
+			return nil, err
+		}
+	}
+	return rf, nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGrep(t *testing.T) {
+	dir := t.TempDir()
+	master := filepath.Join(dir, "a.txt,v")
+	if err := os.WriteFile(master, []byte(htmlTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "a.txt")
+	for _, tc := range []struct {
+		desc      string
+		pattern   string
+		revisions string
+		pickaxe   bool
+		args      []string
+		want      string
+	}{
+		{"positional pattern", "", "", false, []string{"^b$", master}, name + ":1.1:2:b\n" + name + ":1.1.2.1:2:b\n"},
+		{"branch range", "^b$", "FIX:", false, []string{master}, name + ":1.1.2.1:2:b\n"},
+		{"pickaxe", "^b$", "", true, []string{master}, name + ":1.1: 0 -> 1 matches (parent none)\n" + name + ":1.2: 1 -> 0 matches (parent 1.1)\n"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out.txt")
+			if err := Grep(tc.pattern, "", tc.revisions, tc.pickaxe, false, out, false, tc.args...); err != nil {
+				t.Fatalf("Grep() error = %v", err)
+			}
+			b, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.want {
+				t.Errorf("Grep() = %q, want %q", b, tc.want)
+			}
+		})
+	}
+
+	if err := Grep("(", "", "", false, false, "", false, master); err == nil {
+		t.Error("Grep(() error = nil, want error")
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// HookEvent names a point in a mutating command where hooks run.
+type HookEvent string
+
+const (
+	HookPreLock          HookEvent = "pre-lock"
+	HookPostLock         HookEvent = "post-lock"
+	HookPreUnlock        HookEvent = "pre-unlock"
+	HookPostUnlock       HookEvent = "post-unlock"
+	HookPreTag           HookEvent = "pre-tag"
+	HookPostTag          HookEvent = "post-tag"
+	HookPreUntag         HookEvent = "pre-untag"
+	HookPostUntag        HookEvent = "post-untag"
+	HookPreStateChange   HookEvent = "pre-state-change"
+	HookPostStateChange  HookEvent = "post-state-change"
+	HookPreLogChange     HookEvent = "pre-log-change"
+	HookPostLogChange    HookEvent = "post-log-change"
+	HookPreAccessChange  HookEvent = "pre-access-change"
+	HookPostAccessChange HookEvent = "post-access-change"
+	HookPreBranchChange  HookEvent = "pre-branch-change"
+	HookPostBranchChange HookEvent = "post-branch-change"
+	HookPreRenumber      HookEvent = "pre-renumber"
+	HookPostRenumber     HookEvent = "post-renumber"
+	HookPreInit          HookEvent = "pre-init"
+	HookPostInit         HookEvent = "post-init"
+	HookPreFormat        HookEvent = "pre-format"
+	HookPostFormat       HookEvent = "post-format"
+)
+
+// HooksDirEnv names the environment variable holding the directory searched
+// for hook executables. A hook is an executable file named after its event,
+// e.g. $GORCS_HOOKS_DIR/pre-lock.
+const HooksDirEnv = "GORCS_HOOKS_DIR"
+
+// ErrHookRejected is returned when a pre-hook refuses an operation.
+var ErrHookRejected = errors.New("rejected by hook")
+
+// HookPayload describes the operation a hook is called for. Executable hooks
+// receive it as JSON on stdin. Users is the access list as an access-list
+// command leaves it.
+type HookPayload struct {
+	Event    HookEvent `json:"event"`
+	Command  string    `json:"command"`
+	File     string    `json:"file,omitempty"`
+	RCSFile  string    `json:"rcsFile"`
+	Revision string    `json:"revision,omitempty"`
+	User     string    `json:"user,omitempty"`
+	Log      string    `json:"log,omitempty"`
+	State    string    `json:"state,omitempty"`
+	Symbol   string    `json:"symbol,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Users    []string  `json:"users,omitempty"`
+}
+
+// HookFunc is a Go hook. Returning an error from a pre-hook aborts the
+// operation.
+type HookFunc func(p HookPayload) error
+
+var (
+	hooksMu sync.Mutex
+	hooks   = map[HookEvent][]HookFunc{}
+)
+
+// RegisterHook adds fn to the hooks run for event. Go hooks run in
+// registration order, before any executable hook.
+func RegisterHook(event HookEvent, fn HookFunc) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks[event] = append(hooks[event], fn)
+}
+
+// runPreHooks runs the hooks for a pre- event. The first failure aborts the
+// operation with ErrHookRejected.
+func runPreHooks(p HookPayload) error {
+	if err := runHooks(p); err != nil {
+		return fmt.Errorf("%s: %w: %s: %v", p.RCSFile, ErrHookRejected, p.Event, err)
+	}
+	return nil
+}
+
+// runPostHooks runs the hooks for a post- event. The operation has already
+// happened, so failures are only reported. A dry run skips them, as nothing
+// happened.
+func runPostHooks(p HookPayload) {
+	if dryRun {
+		return
+	}
+	if err := runHooks(p); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s hook failed: %v\n", p.RCSFile, p.Event, err)
+	}
+}
+
+func runHooks(p HookPayload) error {
+	hooksMu.Lock()
+	fns := append([]HookFunc(nil), hooks[p.Event]...)
+	hooksMu.Unlock()
+	for _, fn := range fns {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return runHookExecutable(p)
+}
+
+// runHookExecutable runs $GORCS_HOOKS_DIR/<event> when it exists, feeding it
+// the payload as JSON. Its output goes to the command's stderr so it cannot
+// mix with data written to stdout.
+func runHookExecutable(p HookPayload) error {
+	dir := os.Getenv(HooksDirEnv)
+	if dir == "" {
+		return nil
+	}
+	path := filepath.Join(dir, string(p.Event))
+	st, err := os.Stat(path)
+	if err != nil || st.IsDir() {
+		return nil
+	}
+	if runtime.GOOS != "windows" && st.Mode().Perm()&0111 == 0 {
+		return nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "GORCS_HOOK_EVENT="+string(p.Event))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
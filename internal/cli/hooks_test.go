@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func resetHooks(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		hooksMu.Lock()
+		hooks = map[HookEvent][]HookFunc{}
+		hooksMu.Unlock()
+	})
+}
+
+func TestGoHooks(t *testing.T) {
+	resetHooks(t)
+	t.Setenv(HooksDirEnv, "")
+	t.Setenv("USER", "tester")
+	fn := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(fn+",v", []byte(changesetsTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ticket := regexp.MustCompile(`\b[A-Z]+-[0-9]+\b`)
+	RegisterHook(HookPreLogChange, func(p HookPayload) error {
+		if !ticket.MatchString(p.Log) {
+			return fmt.Errorf("log message needs a ticket reference")
+		}
+		return nil
+	})
+	var posted []HookPayload
+	RegisterHook(HookPostLogChange, func(p HookPayload) error {
+		posted = append(posted, p)
+		return nil
+	})
+
+	if err := LogMessageChange("1.2", "no ticket", fn+",v"); !errors.Is(err, ErrHookRejected) {
+		t.Fatalf("LogMessageChange() error = %v, want ErrHookRejected", err)
+	}
+	if len(posted) != 0 {
+		t.Fatalf("post hook ran after rejection: %v", posted)
+	}
+	if err := LogMessageChange("1.2", "fix RCS-42", fn+",v"); err != nil {
+		t.Fatalf("LogMessageChange() error = %v", err)
+	}
+	want := []HookPayload{{Event: HookPostLogChange, Command: "log message change", RCSFile: fn + ",v", Revision: "1.2", User: "tester", Log: "fix RCS-42"}}
+	if diff := cmp.Diff(want, posted); diff != "" {
+		t.Errorf("post hook payloads mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExecutableHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell hooks are not supported on windows")
+	}
+	resetHooks(t)
+	t.Setenv("USER", "tester")
+	dir := t.TempDir()
+	hooksDir := filepath.Join(dir, "hooks")
+	if err := os.Mkdir(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "payload.json")
+	scripts := map[string]string{
+		"pre-state-change":  "#!/bin/sh\ncat > /dev/null\n[ \"$GORCS_HOOK_EVENT\" = pre-state-change ] || exit 2\n",
+		"post-state-change": "#!/bin/sh\ncat > " + out + "\n",
+		"pre-tag":           "#!/bin/sh\necho no tags today >&2\nexit 1\n",
+	}
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(hooksDir, name), []byte(body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(HooksDirEnv, hooksDir)
+
+	fn := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(fn+",v", []byte(changesetsTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := StateAlter("Rel", "1.1", fn); err != nil {
+		t.Fatalf("StateAlter() error = %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got HookPayload
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := HookPayload{Event: HookPostStateChange, Command: "state alter", RCSFile: fn + ",v", Revision: "1.1", User: "tester", State: "Rel"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("payload mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := SymbolsAdd("REL", "", "", "", false, fn); !errors.Is(err, ErrHookRejected) {
+		t.Fatalf("SymbolsAdd() error = %v, want ErrHookRejected", err)
+	}
+	if syms := readSymbols(t, fn+",v"); len(syms) != 0 {
+		t.Errorf("symbols written despite rejected pre-tag hook: %v", syms)
+	}
+}
+
+func TestHookEventsForEveryMutatingCommand(t *testing.T) {
+	t.Setenv(HooksDirEnv, "")
+	t.Setenv("USER", "tester")
+	tests := []struct {
+		name      string
+		run       func(working string) error
+		pre, post HookEvent
+		command   string
+		create    bool
+	}{
+		{"access-list add", func(w string) error { return AccessListAdd("tester,jules", w) }, HookPreAccessChange, HookPostAccessChange, "access-list add", false},
+		{"access-list remove", func(w string) error { return AccessListRemove("jules", w) }, HookPreAccessChange, HookPostAccessChange, "access-list remove", false},
+		{"access-list clear", func(w string) error { return AccessListClear(w) }, HookPreAccessChange, HookPostAccessChange, "access-list clear", false},
+		{"branches default set", func(w string) error { return BranchesDefaultSet("1.2.1", w) }, HookPreBranchChange, HookPostBranchChange, "branches default set", false},
+		{"normalize-revisions", func(w string) error { return NormalizeRevisions(false, false, w+",v") }, HookPreRenumber, HookPostRenumber, "normalize-revisions", false},
+		{"format", func(w string) error { return Format("", true, false, false, false, "", w+",v") }, HookPreFormat, HookPostFormat, "format", false},
+		{"init", func(w string) error { return Init("", w) }, HookPreInit, HookPostInit, "init", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetHooks(t)
+			working := filepath.Join(t.TempDir(), "a.txt")
+			master := working + ",v"
+			if !tt.create {
+				if err := os.WriteFile(master, []byte(changesetsTestMaster), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var events []HookEvent
+			record := func(p HookPayload) error {
+				if p.Command != tt.command || p.RCSFile != master || p.User != "tester" {
+					t.Errorf("%s payload = %+v", p.Event, p)
+				}
+				events = append(events, p.Event)
+				return nil
+			}
+			RegisterHook(tt.pre, func(p HookPayload) error {
+				_ = record(p)
+				return fmt.Errorf("no")
+			})
+			if err := tt.run(working); !errors.Is(err, ErrHookRejected) {
+				t.Fatalf("run error = %v, want ErrHookRejected", err)
+			}
+			b, err := os.ReadFile(master)
+			if tt.create {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("master written after rejection: %v", err)
+				}
+			} else if err != nil || string(b) != changesetsTestMaster {
+				t.Errorf("master changed after rejection: %v", err)
+			}
+
+			hooksMu.Lock()
+			hooks = map[HookEvent][]HookFunc{}
+			hooksMu.Unlock()
+			RegisterHook(tt.pre, record)
+			RegisterHook(tt.post, record)
+			if err := tt.run(working); err != nil {
+				t.Fatalf("run error = %v", err)
+			}
+			if diff := cmp.Diff([]HookEvent{tt.pre, tt.pre, tt.post}, events); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAccessChangeHookUsers(t *testing.T) {
+	resetHooks(t)
+	t.Setenv(HooksDirEnv, "")
+	fn := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(fn+",v", []byte(changesetsTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var users [][]string
+	RegisterHook(HookPostAccessChange, func(p HookPayload) error {
+		users = append(users, p.Users)
+		return nil
+	})
+	if err := AccessListAdd("jules,martha", fn); err != nil {
+		t.Fatal(err)
+	}
+	if err := AccessListRemove("jules", fn); err != nil {
+		t.Fatal(err)
+	}
+	if err := AccessListClear(fn); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]string{{"jules", "martha"}, {"martha"}, nil}, users); diff != "" {
+		t.Errorf("access lists mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,384 @@
+package cli
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/arran4/golang-rcs/diff"
+)
+
+//go:embed html.tmpl
+var htmlTmpl string
+
+var htmlTemplate = template.Must(template.New("html").Parse(htmlTmpl))
+
+// ToHtml is a subcommand `gorcs to-html`
+//
+// Flags:
+//
+//	output: -o --output Output directory (default html)
+//	force: -f --force Write into an existing output directory
+//	files: ... RCS files or directories to scan for ,v files
+func ToHtml(output string, force bool, files ...string) error {
+	if output == "" {
+		output = "html"
+	}
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	index := filepath.Join(output, "index.html")
+	if _, err := os.Stat(index); err == nil && !force {
+		return fmt.Errorf("output %s already exists, use -f to force overwrite", output)
+	}
+	sources, closeArchives, err := htmlSources(files)
+	if err != nil {
+		return err
+	}
+	defer closeArchives()
+	site := &htmlSite{dir: output}
+	page := htmlIndexPage{htmlPage: htmlPage{Title: "RCS history"}}
+	for _, src := range sources {
+		f, err := parseMaster(src.master)
+		if err != nil {
+			return err
+		}
+		entry, err := site.writeFile(src.name, f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", src.master, err)
+		}
+		page.Files = append(page.Files, entry)
+	}
+	if err := site.write("index.html", "index", page); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote: %s (%d pages)\n", index, site.pages)
+	return nil
+}
+
+type htmlSource struct {
+	name   string
+	master string
+}
+
+// htmlSources lists the masters to render with their site names: the
+// working name relative to a directory argument, or the base name for a file
+// argument. The returned function closes the archives the masters were
+// listed from once they have been read.
+func htmlSources(paths []string) ([]htmlSource, func() error, error) {
+	var sources []htmlSource
+	closeArchives := func() error { return nil }
+	for _, p := range paths {
+		st, err := statMaster(p)
+		if err == nil && st.IsDir() {
+			masters, closeListed, err := collectMasters([]string{p})
+			if err != nil {
+				_ = closeArchives()
+				return nil, nil, err
+			}
+			// Every call closes the same repository's archives.
+			closeArchives = closeListed
+			for _, m := range masters {
+				rel, err := filepath.Rel(p, m)
+				if err != nil {
+					_ = closeArchives()
+					return nil, nil, err
+				}
+				sources = append(sources, htmlSource{name: filepath.ToSlash(workingName(rel)), master: m})
+			}
+			continue
+		}
+		master, _ := resolveMaster(p, "")
+		sources = append(sources, htmlSource{name: filepath.Base(workingName(master)), master: master})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].name < sources[j].name })
+	return sources, closeArchives, nil
+}
+
+// htmlPage is embedded by every page. Root is the relative path back to the
+// site root and Name the file the page belongs to, if any.
+type htmlPage struct {
+	Title string
+	Root  string
+	Name  string
+}
+
+type htmlIndexPage struct {
+	htmlPage
+	Files []htmlIndexEntry
+}
+
+type htmlIndexEntry struct {
+	Name      string
+	Head      string
+	Revisions int
+	Date      string
+	Author    string
+	Log       string
+}
+
+type htmlFilePage struct {
+	htmlPage
+	fileData
+	Pages map[string]bool
+	Tree  []*htmlTreeNode
+	Rows  []htmlRevisionRow
+}
+
+type htmlRevisionRow struct {
+	Revision string
+	Date     string
+	Author   string
+	State    string
+	Tags     []string
+	Stats    *rcs.RevisionStats
+	Log      string
+}
+
+type htmlTreeNode struct {
+	Revision string
+	Date     string
+	Author   string
+	Tags     []string
+	Branches []htmlTreeBranch
+}
+
+type htmlTreeBranch struct {
+	Number    string
+	Name      string
+	Revisions []*htmlTreeNode
+}
+
+type htmlRevisionPage struct {
+	htmlPage
+	Revision  string
+	Date      string
+	Author    string
+	State     string
+	CommitID  string
+	Tags      []string
+	Parent    string
+	Children  []string
+	Log       string
+	Diff      []htmlDiffLine
+	Text      string
+	Annotated bool
+}
+
+type htmlDiffLine struct {
+	Class string
+	Text  string
+}
+
+type htmlAnnotatePage struct {
+	htmlPage
+	Lines []rcs.AnnotatedLine
+}
+
+type htmlSite struct {
+	dir   string
+	pages int
+}
+
+func (s *htmlSite) write(name, tmpl string, data any) error {
+	var buf bytes.Buffer
+	if err := htmlTemplate.ExecuteTemplate(&buf, tmpl, data); err != nil {
+		return err
+	}
+	p := filepath.Join(s.dir, filepath.FromSlash(name))
+	if dryRun {
+		s.pages++
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing output to %s: %w", p, err)
+	}
+	s.pages++
+	return nil
+}
+
+// writeFile writes the pages for one master under name/: index.html with
+// symbols, the revision tree and the revision table, one page per revision
+// with its diff against its parent, and annotate pages for the head of the
+// trunk and of every branch.
+func (s *htmlSite) writeFile(name string, f *rcs.File) (htmlIndexEntry, error) {
+	entry := htmlIndexEntry{Name: name, Head: f.Head, Revisions: len(f.RevisionHeads)}
+	texts, err := f.RevisionTexts()
+	if err != nil {
+		return entry, err
+	}
+	data := newFileData(f)
+	parents := f.RevisionParents()
+	children := f.RevisionChildren()
+	tags := map[string][]string{}
+	for _, sym := range f.Symbols {
+		tags[sym.Revision] = append(tags[sym.Revision], sym.Name)
+	}
+	pages := map[string]bool{}
+	for _, rp := range data.Revisions {
+		pages[rp.Head.Revision.String()] = true
+	}
+
+	base := htmlPage{Root: strings.Repeat("../", strings.Count(name, "/")+1), Name: name}
+	tree, tips := htmlRevisionTree(f, tags)
+
+	page := htmlFilePage{htmlPage: base, fileData: data, Pages: pages, Tree: tree}
+	page.Title = name
+	for _, rp := range data.Revisions {
+		rev := rp.Head.Revision.String()
+		date := formatRevisionDate(time.DateTime, rp.Head.Date)
+		parent := parents[rev]
+		if parent != "" && !pages[parent] {
+			parent = ""
+		}
+		fromName := parent
+		if fromName == "" {
+			fromName = "/dev/null"
+		}
+		unified, err := diff.UnifiedText(fromName, rev, texts[parent], texts[rev], 3)
+		if err != nil {
+			return entry, err
+		}
+		lines, stats := htmlDiffLines(unified)
+		stats.Lines = len(diff.SplitText(texts[rev]))
+		row := htmlRevisionRow{
+			Revision: rev,
+			Date:     date,
+			Author:   rp.Head.Author.String(),
+			State:    rp.Head.State.String(),
+			Tags:     tags[rev],
+			Stats:    stats,
+			Log:      firstLogLine(rp.Content.Log),
+		}
+		page.Rows = append(page.Rows, row)
+		if rev == f.Head {
+			entry.Date, entry.Author, entry.Log = row.Date, row.Author, row.Log
+		}
+
+		rpage := htmlRevisionPage{
+			htmlPage:  base,
+			Revision:  rev,
+			Date:      date,
+			Author:    row.Author,
+			State:     row.State,
+			CommitID:  rp.Head.CommitID.String(),
+			Tags:      tags[rev],
+			Parent:    parent,
+			Children:  children[rev],
+			Log:       rp.Content.Log,
+			Diff:      lines,
+			Text:      texts[rev],
+			Annotated: tips[rev],
+		}
+		rpage.Title = name + " " + rev
+		if err := s.write(name+"/"+rev+".html", "revision", rpage); err != nil {
+			return entry, err
+		}
+	}
+
+	for rev := range tips {
+		lines, err := f.Annotate(rev)
+		if err != nil {
+			return entry, err
+		}
+		apage := htmlAnnotatePage{htmlPage: base, Lines: lines}
+		apage.Title = "Annotate " + name + " " + rev
+		if err := s.write(name+"/annotate-"+rev+".html", "annotate", apage); err != nil {
+			return entry, err
+		}
+	}
+	return entry, s.write(name+"/index.html", "file", page)
+}
+
+// htmlRevisionTree lays the trunk out oldest first with each branch nested
+// under its branch point. It also returns the tip of the trunk and of every
+// branch.
+func htmlRevisionTree(f *rcs.File, tags map[string][]string) ([]*htmlTreeNode, map[string]bool) {
+	heads := make(map[string]*rcs.RevisionHead, len(f.RevisionHeads))
+	for _, rh := range f.RevisionHeads {
+		heads[rh.Revision.String()] = rh
+	}
+	branchNames := f.BranchSymbols()
+	tips := map[string]bool{}
+	seen := map[string]bool{}
+
+	var line func(rev string) []*htmlTreeNode
+	line = func(rev string) []*htmlTreeNode {
+		var nodes []*htmlTreeNode
+		for rev != "" && !seen[rev] {
+			rh, ok := heads[rev]
+			if !ok {
+				break
+			}
+			seen[rev] = true
+			n := &htmlTreeNode{
+				Revision: rev,
+				Date:     formatRevisionDate(time.DateTime, rh.Date),
+				Author:   rh.Author.String(),
+				Tags:     tags[rev],
+			}
+			for _, b := range rh.Branches {
+				number := rcs.BranchNumber(b.String())
+				revs := line(b.String())
+				if len(revs) > 0 {
+					tips[revs[len(revs)-1].Revision] = true
+				}
+				n.Branches = append(n.Branches, htmlTreeBranch{Number: number, Name: branchNames[number], Revisions: revs})
+			}
+			nodes = append(nodes, n)
+			rev = rh.NextRevision.String()
+		}
+		return nodes
+	}
+
+	trunk := line(f.Head)
+	if len(trunk) > 0 {
+		tips[f.Head] = true
+	}
+	// Trunk deltas run from the head backwards; show the oldest first.
+	for i, j := 0, len(trunk)-1; i < j; i, j = i+1, j-1 {
+		trunk[i], trunk[j] = trunk[j], trunk[i]
+	}
+	return trunk, tips
+}
+
+func htmlDiffLines(unified string) ([]htmlDiffLine, *rcs.RevisionStats) {
+	stats := &rcs.RevisionStats{}
+	var lines []htmlDiffLine
+	for i, l := range diff.SplitText(unified) {
+		l = strings.TrimSuffix(l, "\n")
+		class := ""
+		switch {
+		case i < 2:
+			// The ---/+++ file header.
+		case strings.HasPrefix(l, "@@"):
+			class = "hunk"
+		case strings.HasPrefix(l, "-"):
+			class = "del"
+			stats.Deleted++
+		case strings.HasPrefix(l, "+"):
+			class = "ins"
+			stats.Added++
+		}
+		lines = append(lines, htmlDiffLine{Class: class, Text: l})
+	}
+	return lines, stats
+}
+
+func firstLogLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const htmlTestMaster = `head	1.2;
+access;
+symbols
+	REL_1:1.1
+	FIX:1.1.0.2;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author bob;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches
+	1.1.2.1;
+next	;
+
+1.1.2.1
+date	2021.03.05.05.06.07;	author carol;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@second <b>
+@
+text
+@a
+B
+c
+@
+
+
+1.1
+log
+@first
+@
+text
+@d2 1
+a2 1
+b
+@
+
+
+1.1.2.1
+log
+@fix
+@
+text
+@a3 1
+d
+@
+`
+
+func TestToHtml(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub", "RCS"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "RCS", "a.txt,v"), []byte(htmlTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	if err := ToHtml(out, false, src); err != nil {
+		t.Fatalf("ToHtml() error = %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	for name, wants := range map[string][]string{
+		"index.html": {`<a href="sub/a.txt/index.html">sub/a.txt</a>`, "second &lt;b&gt;"},
+		"sub/a.txt/index.html": {
+			`<a href="../../index.html">[index]</a>`,
+			`<td>REL_1</td><td><a href="1.1.html">1.1</a></td>`,
+			`<td>FIX</td><td>1.1.0.2</td>`,
+			`<li class="branch">branch 1.1.2 (FIX)</li>`,
+			`<td class="rev">+1 -1</td>`,
+		},
+		"sub/a.txt/1.2.html": {
+			`<span class="del">-b</span>`,
+			`<span class="ins">&#43;B</span>`,
+			`<a href="annotate-1.2.html">annotate</a>`,
+		},
+		"sub/a.txt/1.1.2.1.html": {
+			`<a href="1.1.html">1.1</a>`,
+			`<span class="ins">&#43;d</span>`,
+			"<pre>a\nb\nc\nd\n</pre>",
+		},
+		"sub/a.txt/annotate-1.1.2.1.html": {`<a href="1.1.2.1.html">1.1.2.1</a></td><td class="rev">carol`},
+	} {
+		got := read(name)
+		for _, want := range wants {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s missing %q in:\n%s", name, want, got)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "sub", "a.txt", "annotate-1.1.html")); err == nil {
+		t.Error("annotate page written for a revision that is not a tip")
+	}
+
+	if err := ToHtml(out, false, src); err == nil {
+		t.Error("ToHtml() into existing output error = nil, want error")
+	}
+	if err := ToHtml(out, true, src); err != nil {
+		t.Errorf("ToHtml() with force error = %v", err)
+	}
+}
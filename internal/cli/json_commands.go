@@ -16,24 +16,33 @@ import (
 //	force: -f --force Force overwrite output
 //	indent: -I --indent Indent JSON output
 //	mmap: -m --mmap Use mmap to read file
+//	text: --text Include the full text of every revision
+//	deltas: --deltas Include every stored delta parsed into add and delete commands
+//	links: --links Include the parent and children of every revision
+//	stats: --stats Include line counts and lines added and deleted per revision
+//	transcode: --transcode Convert authors and log messages from this charset (latin1 or cp1252) to UTF-8
 //	files: ... List of files to process, or - for stdin
-func ToJson(output string, force, indent, useMmap bool, files ...string) error {
+func ToJson(output string, force, indent, useMmap, text, deltas, links, stats bool, transcode string, files ...string) error {
 	var err error
 	if files, err = ensureFiles(files); err != nil {
 		return err
 	}
+	if err := checkTranscode(transcode); err != nil {
+		return err
+	}
 	if output != "" && output != "-" && len(files) > 1 {
 		return fmt.Errorf("cannot specify output file with multiple input files")
 	}
+	opts := rcs.JSONOptions{Indent: indent, Text: text, Deltas: deltas, Links: links, Stats: stats}
 	for _, fn := range files {
-		if err := processFileToJson(fn, output, force, indent, useMmap); err != nil {
+		if err := processFileToJson(fn, output, force, useMmap, transcode, opts); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func processFileToJson(fn string, output string, force, indent, useMmap bool) error {
+func processFileToJson(fn string, output string, force, useMmap bool, transcode string, opts rcs.JSONOptions) error {
 	f, err := OpenFile(fn, useMmap)
 	if err != nil {
 		return fmt.Errorf("error with file %s: %w", fn, err)
@@ -45,12 +54,12 @@ func processFileToJson(fn string, output string, force, indent, useMmap bool) er
 	if err != nil {
 		return fmt.Errorf("error parsing %s: %w", fn, err)
 	}
-	var b []byte
-	if indent {
-		b, err = json.MarshalIndent(r, "", "  ")
-	} else {
-		b, err = json.Marshal(r)
+	if transcode != "" {
+		if err := r.TranscodeToUTF8(transcode); err != nil {
+			return err
+		}
 	}
+	b, err := r.ToJSON(opts)
 	if err != nil {
 		return fmt.Errorf("error serializing %s: %w", fn, err)
 	}
@@ -137,12 +146,25 @@ func processFileFromJson(fn string, output string, force, useMmap bool) error {
 	return nil
 }
 
+// checkTranscode rejects a --transcode charset before any file is read.
+func checkTranscode(charset string) error {
+	if charset == "" {
+		return nil
+	}
+	_, err := rcs.TranscodeToUTF8("", charset)
+	return err
+}
+
 func writeOutput(path string, data []byte, force bool) error {
 	if !force {
 		if _, err := os.Stat(path); err == nil {
 			return fmt.Errorf("output file %s already exists, use -f to force overwrite", path)
 		}
 	}
+	if dryRun {
+		fmt.Printf("Would write: %s (%d bytes)\n", path, len(data))
+		return nil
+	}
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("error writing output to %s: %w", path, err)
 	}
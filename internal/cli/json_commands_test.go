@@ -91,7 +91,7 @@ func TestJsonCommandsStdIO(t *testing.T) {
 		}
 	}()
 
-	if err := ToJson("", false, false, false, "-"); err != nil {
+	if err := ToJson("", false, false, false, false, false, false, false, "", "-"); err != nil {
 		t.Errorf("ToJson failed: %v", err)
 	}
 
@@ -159,7 +159,7 @@ func TestJsonCommandsFileToFile(t *testing.T) {
 	}
 
 	// 1. ToJson default output
-	if err := ToJson("", false, false, false, inputFile); err != nil {
+	if err := ToJson("", false, false, false, false, false, false, false, "", inputFile); err != nil {
 		t.Errorf("ToJson failed: %v", err)
 	}
 	expectedJsonFile := inputFile + ".json"
@@ -195,7 +195,7 @@ func TestJsonCommandsFileToFile(t *testing.T) {
 
 	// 4. Custom output
 	customOut := filepath.Join(dir, "custom.json")
-	if err := ToJson(customOut, false, false, false, inputFile); err != nil {
+	if err := ToJson(customOut, false, false, false, false, false, false, false, "", inputFile); err != nil {
 		t.Errorf("ToJson failed: %v", err)
 	}
 	if _, err := os.Stat(customOut); os.IsNotExist(err) {
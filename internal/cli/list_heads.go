@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	rcs "github.com/arran4/golang-rcs"
+	"os"
 	"time"
 )
 
@@ -11,36 +12,56 @@ import (
 // Flags:
 //
 //	mmap: -m --mmap Use mmap to read file
+//	tolerant: --tolerant List what can be recovered from damaged files and keep going
 //	files: ... List of files to process
-func ListHeads(useMmap bool, files ...string) error {
+func ListHeads(useMmap, tolerant bool, files ...string) error {
 	var err error
 	if files, err = ensureFiles(files); err != nil {
 		return err
 	}
+	damaged := 0
 	for _, f := range files {
-		if err := listHeadsFile(f, useMmap); err != nil {
+		ok, err := listHeadsFile(f, useMmap, tolerant)
+		if err != nil {
 			return err
 		}
+		if !ok {
+			damaged++
+		}
+	}
+	if damaged > 0 {
+		return fmt.Errorf("%d of %d files had parse errors", damaged, len(files))
 	}
 	return nil
 }
 
-func listHeadsFile(fn string, useMmap bool) error {
+// listHeadsFile prints the revisions of fn. In tolerant mode parse problems
+// go to stderr and it reports false instead of failing.
+func listHeadsFile(fn string, useMmap, tolerant bool) (bool, error) {
 	f, err := OpenFile(fn, useMmap)
 	if err != nil {
-		return fmt.Errorf("error with file: %w", err)
+		return false, fmt.Errorf("error with file: %w", err)
 	}
 	defer func() {
 		_ = f.Close()
 	}()
 	fmt.Println("Parsing: ", fn)
-	r, err := rcs.ParseFile(f)
+	var r *rcs.File
+	var diags []*rcs.ParseError
+	if tolerant {
+		r, diags, err = rcs.ParseFileTolerant(f, rcs.TolerantOptions{})
+	} else {
+		r, err = rcs.ParseFile(f)
+	}
 	if err != nil {
-		return fmt.Errorf("error parsing %s: %w", fn, err)
+		return false, fmt.Errorf("error parsing %s: %w", fn, err)
+	}
+	for _, d := range diags {
+		reportParseError(os.Stderr, fn, "text", d)
 	}
 	for _, rh := range r.RevisionHeads {
 		dt, _ := rh.Date.DateTime()
 		fmt.Printf("%s on %s by %s\n", rh.Revision, dt.In(time.Local), rh.Author)
 	}
-	return nil
+	return len(diags) == 0, nil
 }
@@ -17,22 +17,32 @@ func Locks(subCommand, revision string, files ...string) error {
 	return nil
 }
 
-func locksFile(subCommand, revision string, workingFile string) error {
-	rcsFile := workingFile
-	if !strings.HasSuffix(rcsFile, ",v") {
-		rcsFile += ",v"
+func locksFile(subCommand, revision string, file string) error {
+	rcsFile, workingFile := resolveMaster(file, "")
+	lock, err := lockMaster(rcsFile)
+	if err != nil {
+		return err
 	}
-	b, err := os.ReadFile(rcsFile)
+	defer func() {
+		_ = lock.Release()
+	}()
+	b, err := readMaster(rcsFile)
 	if err != nil {
-		return fmt.Errorf("read %s: %w", rcsFile, err)
+		return err
 	}
 	parsed, err := rcs.ParseFile(strings.NewReader(string(b)))
 	if err != nil {
 		return fmt.Errorf("parse %s: %w", rcsFile, err)
 	}
 
+	if err := checkAccess(parsed, rcsFile, ""); err != nil {
+		return err
+	}
+
 	changed := false
 	user := currentLoggedInUser()
+	hook := HookPayload{Command: "locks " + subCommand, File: workingFile, RCSFile: rcsFile, Revision: revision, User: user}
+	var postEvent HookEvent
 
 	switch subCommand {
 	case "lock":
@@ -42,6 +52,7 @@ func locksFile(subCommand, revision string, workingFile string) error {
 		if parsed.SetLock(user, revision) {
 			changed = true
 		}
+		hook.Event, postEvent = HookPreLock, HookPostLock
 	case "unlock":
 		if revision == "" {
 			return fmt.Errorf("unlock requires revision")
@@ -49,6 +60,7 @@ func locksFile(subCommand, revision string, workingFile string) error {
 		if parsed.ClearLock(user, revision) {
 			changed = true
 		}
+		hook.Event, postEvent = HookPreUnlock, HookPostUnlock
 	case "strict":
 		if !parsed.Strict {
 			parsed.Strict = true
@@ -79,6 +91,8 @@ func locksFile(subCommand, revision string, workingFile string) error {
 			if parsed.ClearLock(user, targetRev) {
 				changed = true
 			}
+			hook.Revision = targetRev
+			hook.Event, postEvent = HookPreUnlock, HookPostUnlock
 		} else {
 			return fmt.Errorf("working file %s is modified", workingFile)
 		}
@@ -87,10 +101,20 @@ func locksFile(subCommand, revision string, workingFile string) error {
 		return fmt.Errorf("unknown subcommand: %s", subCommand)
 	}
 
-	if changed {
-		if err := os.WriteFile(rcsFile, []byte(parsed.String()), 0644); err != nil {
-			return fmt.Errorf("write %s: %w", rcsFile, err)
+	if !changed {
+		return nil
+	}
+	if hook.Event != "" {
+		if err := runPreHooks(hook); err != nil {
+			return err
 		}
 	}
+	if err := lock.Commit([]byte(parsed.String()), 0644); err != nil {
+		return err
+	}
+	if postEvent != "" {
+		hook.Event = postEvent
+		runPostHooks(hook)
+	}
 	return nil
 }
@@ -17,33 +17,56 @@ import (
 //	files: ... List of working files to process
 func LogMessageChange(revision, message string, files ...string) error {
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
+		rcsFile, _ := resolveMaster(file, "")
+		if err := logMessageChangeFile(revision, message, rcsFile); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		f, err := os.Open(rcsFile)
-		if err != nil {
-			return fmt.Errorf("open %s: %w", rcsFile, err)
-		}
+func logMessageChangeFile(revision, message, rcsFile string) error {
+	lock, err := lockMaster(rcsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
 
-		parsedFile, err := rcs.ParseFile(f)
-		if err := f.Close(); err != nil {
-			return fmt.Errorf("close %s: %w", rcsFile, err)
-		}
-		if err != nil {
-			return fmt.Errorf("parse %s: %w", rcsFile, err)
-		}
+	f, err := repo.Storage.Open(rcsFile)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", rcsFile, err)
+	}
 
-		if err := parsedFile.ChangeLogMessage(revision, message); err != nil {
-			return fmt.Errorf("change log message in %s: %w", rcsFile, err)
-		}
+	parsedFile, err := rcs.ParseFile(f)
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", rcsFile, err)
+	}
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", rcsFile, err)
+	}
 
-		// Write back the file
-		if err := os.WriteFile(rcsFile, []byte(parsedFile.String()), 0644); err != nil {
-			return fmt.Errorf("write %s: %w", rcsFile, err)
-		}
+	if err := checkAccess(parsedFile, rcsFile, ""); err != nil {
+		return err
+	}
+
+	hook := HookPayload{Command: "log message change", RCSFile: rcsFile, Revision: revision, User: currentLoggedInUser(), Log: message}
+	hook.Event = HookPreLogChange
+	if err := runPreHooks(hook); err != nil {
+		return err
 	}
+
+	if err := parsedFile.ChangeLogMessage(revision, message); err != nil {
+		return fmt.Errorf("change log message in %s: %w", rcsFile, err)
+	}
+
+	// Write back the file
+	if err := lock.Commit([]byte(parsedFile.String()), 0644); err != nil {
+		return err
+	}
+	hook.Event = HookPostLogChange
+	runPostHooks(hook)
 	return nil
 }
 
@@ -55,12 +78,9 @@ func LogMessageChange(revision, message string, files ...string) error {
 //	files: ... List of working files to process
 func LogMessagePrint(revision string, files ...string) error {
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
-		}
+		rcsFile, _ := resolveMaster(file, "")
 
-		f, err := os.Open(rcsFile)
+		f, err := OpenFile(rcsFile, false)
 		if err != nil {
 			return fmt.Errorf("open %s: %w", rcsFile, err)
 		}
@@ -90,12 +110,9 @@ func LogMessagePrint(revision string, files ...string) error {
 //	files: ... List of working files to process
 func LogMessageList(files ...string) error {
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
-		}
+		rcsFile, _ := resolveMaster(file, "")
 
-		f, err := os.Open(rcsFile)
+		f, err := OpenFile(rcsFile, false)
 		if err != nil {
 			return fmt.Errorf("open %s: %w", rcsFile, err)
 		}
@@ -166,12 +183,9 @@ func Log(filterStr string, stateFilter string, files ...string) error {
 	}
 
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
-		}
+		rcsFile, _ := resolveMaster(file, "")
 
-		f, err := os.Open(rcsFile)
+		f, err := OpenFile(rcsFile, false)
 		if err != nil {
 			return fmt.Errorf("open %s: %w", rcsFile, err)
 		}
@@ -2,10 +2,13 @@ package cli
 
 import (
 	"bufio"
+	"encoding/base64"
 	"fmt"
 	rcs "github.com/arran4/golang-rcs"
 	"io"
+	"strconv"
 	"strings"
+	"text/template"
 )
 
 // ToMarkdown is a subcommand `gorcs to-markdown`
@@ -14,24 +17,34 @@ import (
 //
 //	output: -o --output Output file path
 //	force: -f --force Force overwrite output
+//	variant: -variant Built-in layout: default, text (full text of each revision) or diff (unified diff against the parent)
+//	templateFile: -t --template Template file parsed over the chosen layout
+//	transcode: --transcode Convert authors and log messages from this charset (latin1 or cp1252) to UTF-8
 //	files: ... List of files to process, or - for stdin
-func ToMarkdown(output string, force bool, files ...string) error {
+func ToMarkdown(output string, force bool, variant, templateFile, transcode string, files ...string) error {
 	var err error
 	if files, err = ensureFiles(files); err != nil {
 		return err
 	}
+	if err := checkTranscode(transcode); err != nil {
+		return err
+	}
 	if output != "" && output != "-" && len(files) > 1 {
 		return fmt.Errorf("cannot specify output file with multiple input files")
 	}
+	t, err := loadMarkdownTemplate(variant, templateFile)
+	if err != nil {
+		return err
+	}
 	for _, fn := range files {
-		if err := processFileToMarkdown(fn, output, force); err != nil {
+		if err := processFileToMarkdown(fn, output, force, transcode, t); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func processFileToMarkdown(fn string, output string, force bool) error {
+func processFileToMarkdown(fn string, output string, force bool, transcode string, t *template.Template) error {
 	f, err := OpenFile(fn, false)
 	if err != nil {
 		return fmt.Errorf("error with file %s: %w", fn, err)
@@ -43,8 +56,13 @@ func processFileToMarkdown(fn string, output string, force bool) error {
 	if err != nil {
 		return fmt.Errorf("error parsing %s: %w", fn, err)
 	}
+	if transcode != "" {
+		if err := r.TranscodeToUTF8(transcode); err != nil {
+			return err
+		}
+	}
 
-	outString, err := rcsFileToMarkdown(r)
+	outString, err := renderMarkdown(t, r)
 	if err != nil {
 		return fmt.Errorf("error converting %s to markdown: %w", fn, err)
 	}
@@ -70,46 +88,53 @@ func processFileToMarkdown(fn string, output string, force bool) error {
 }
 
 func rcsFileToMarkdown(f *rcs.File) (string, error) {
-	var sb strings.Builder
+	return renderMarkdown(markdownTemplate, f)
+}
 
-	contentsMap := make(map[string]*rcs.RevisionContent)
-	for _, rc := range f.RevisionContents {
-		contentsMap[rc.Revision] = rc
-	}
+func renderMarkdown(base *template.Template, f *rcs.File) (string, error) {
+	var sb strings.Builder
 
-	t, err := markdownTemplate.Clone()
+	t, err := base.Clone()
 	if err != nil {
 		return "", fmt.Errorf("failed to clone template: %w", err)
 	}
+	t.Funcs(markdownFuncs(f))
 
-	type RevisionPair struct {
-		Head    *rcs.RevisionHead
-		Content *rcs.RevisionContent
+	if err := t.Execute(&sb, newFileData(f)); err != nil {
+		return "", err
 	}
 
-	var revisions []RevisionPair
+	return sb.String(), nil
+}
+
+// fileData is the model the to-markdown and to-html templates render: the
+// parsed file plus its revisions with heads and contents paired up.
+type fileData struct {
+	*rcs.File
+	Revisions []revisionPair
+}
+
+type revisionPair struct {
+	Head    *rcs.RevisionHead
+	Content *rcs.RevisionContent
+}
+
+func newFileData(f *rcs.File) fileData {
+	contentsMap := make(map[string]*rcs.RevisionContent)
+	for _, rc := range f.RevisionContents {
+		contentsMap[rc.Revision] = rc
+	}
+
+	var revisions []revisionPair
 	for _, rh := range f.RevisionHeads {
 		rev := rh.Revision.String()
 		rc := contentsMap[rev]
 		if rc == nil {
 			rc = &rcs.RevisionContent{}
 		}
-		revisions = append(revisions, RevisionPair{Head: rh, Content: rc})
+		revisions = append(revisions, revisionPair{Head: rh, Content: rc})
 	}
-
-	data := struct {
-		*rcs.File
-		Revisions []RevisionPair
-	}{
-		File:      f,
-		Revisions: revisions,
-	}
-
-	if err := t.Execute(&sb, data); err != nil {
-		return "", err
-	}
-
-	return sb.String(), nil
+	return fileData{File: f, Revisions: revisions}
 }
 
 // FromMarkdown is a subcommand `gorcs from-markdown`
@@ -200,16 +225,7 @@ func parseMarkdownFile(r io.Reader) (*rcs.File, error) {
 	var multilineBuilder strings.Builder
 	inQuote := false
 
-	commitQuote := func() {
-		if !inQuote {
-			return
-		}
-		inQuote = false
-		content := multilineBuilder.String()
-		if len(content) > 0 && content[len(content)-1] == '\n' {
-			content = content[:len(content)-1]
-		}
-
+	setContent := func(content string) {
 		switch state {
 		case stateDescription:
 			f.Description = content
@@ -229,9 +245,25 @@ func parseMarkdownFile(r io.Reader) (*rcs.File, error) {
 				f.Comment = content
 			}
 		}
+	}
+
+	commitQuote := func() {
+		if !inQuote {
+			return
+		}
+		inQuote = false
+		content := multilineBuilder.String()
+		if len(content) > 0 && content[len(content)-1] == '\n' {
+			content = content[:len(content)-1]
+		}
+		setContent(content)
 		multilineBuilder.Reset()
 	}
 
+	// inBase64 is set inside a base64 fence, which holds the exact bytes
+	// of content the quote helper could not block quote.
+	inBase64 := false
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		trimmed := strings.TrimSpace(line)
@@ -241,6 +273,25 @@ func parseMarkdownFile(r io.Reader) (*rcs.File, error) {
 		// Check if we expect a quote
 		expectingQuote := state == stateDescription || state == stateLog || state == stateText || (state == stateHeader && subState == "Comment")
 
+		if inBase64 {
+			if trimmed != "```" {
+				multilineBuilder.WriteString(trimmed)
+				continue
+			}
+			inBase64 = false
+			b, err := base64.StdEncoding.DecodeString(multilineBuilder.String())
+			multilineBuilder.Reset()
+			if err != nil {
+				return nil, fmt.Errorf("base64 block: %w", err)
+			}
+			setContent(string(b))
+			continue
+		}
+		if trimmed == "```base64" && expectingQuote && !inQuote {
+			inBase64 = true
+			continue
+		}
+
 		if isQuoteLine && expectingQuote {
 			inQuote = true
 			content := strings.TrimPrefix(trimmed, ">")
@@ -339,6 +390,16 @@ func parseMarkdownFile(r io.Reader) (*rcs.File, error) {
 				if len(parts) == 2 {
 					f.Locks = append(f.Locks, &rcs.Lock{User: unquote(strings.TrimSpace(parts[0])), Revision: unquote(strings.TrimSpace(parts[1]))})
 				}
+			case "Phrases":
+				parts := strings.SplitN(val, ":", 2)
+				if len(parts) != 2 {
+					return nil, fmt.Errorf("invalid phrase %q", val)
+				}
+				np, err := parseMarkdownPhrase(unquote(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]))
+				if err != nil {
+					return nil, err
+				}
+				f.NewPhrases = append(f.NewPhrases, np)
 			}
 			continue
 		}
@@ -421,3 +482,17 @@ func parseMarkdownFile(r io.Reader) (*rcs.File, error) {
 
 	return f, nil
 }
+
+// parseMarkdownPhrase reads a header newphrase written by the "phrase"
+// template helper.
+func parseMarkdownPhrase(key, quoted string) (*rcs.NewPhrase, error) {
+	text, err := strconv.Unquote(quoted)
+	if err != nil {
+		return nil, fmt.Errorf("phrase %s: %w", key, err)
+	}
+	v, err := rcs.ParseNewPhraseValue(rcs.NewScanner(strings.NewReader(text + ";")))
+	if err != nil {
+		return nil, fmt.Errorf("phrase %s: %w", key, err)
+	}
+	return &rcs.NewPhrase{Key: rcs.ID(key), Value: v}, nil
+}
@@ -2,30 +2,213 @@ package cli
 
 import (
 	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/arran4/golang-rcs/diff"
 )
 
 //go:embed markdown.tmpl
 var markdownTmpl string
 
-var markdownTemplate = template.Must(template.New("markdown").Funcs(template.FuncMap{
-	"quote": func(content string) string {
-		if content == "" {
-			return "> \n"
+//go:embed markdown_text.tmpl
+var markdownTextTmpl string
+
+//go:embed markdown_diff.tmpl
+var markdownDiffTmpl string
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(markdownFuncs(nil)).Parse(markdownTmpl))
+
+// markdownVariants are the built-in to-markdown layouts. The variants only
+// redefine the "text" block of the default template; only the default can be
+// read back by from-markdown.
+var markdownVariants = map[string]*template.Template{
+	"default": markdownTemplate,
+	"text":    template.Must(template.Must(markdownTemplate.Clone()).Parse(markdownTextTmpl)),
+	"diff":    template.Must(template.Must(markdownTemplate.Clone()).Parse(markdownDiffTmpl)),
+}
+
+// loadMarkdownTemplate returns the named built-in variant with the template
+// file, if any, parsed over it. A file with top level content replaces the
+// whole layout; a file of {{define}} actions replaces just those blocks.
+func loadMarkdownTemplate(variant, file string) (*template.Template, error) {
+	if variant == "" {
+		variant = "default"
+	}
+	base, ok := markdownVariants[variant]
+	if !ok {
+		return nil, fmt.Errorf("unknown markdown variant %q", variant)
+	}
+	if file == "" {
+		return base, nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	t, err := base.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone template: %w", err)
+	}
+	if _, err := t.Parse(string(b)); err != nil {
+		return nil, fmt.Errorf("template %s: %w", file, err)
+	}
+	return t, nil
+}
+
+// markdownFuncs are the helpers available to markdown templates. Those that
+// need the file are bound per file before execution; f is nil at parse time.
+// Revision arguments may be strings or rcs.Num.
+func markdownFuncs(f *rcs.File) template.FuncMap {
+	texts := map[string]string{}
+	checkout := func(r any) (string, error) {
+		rev := fmt.Sprint(r)
+		if rev == "" {
+			return "", nil
 		}
-		var sb strings.Builder
-		lines := strings.Split(content, "\n")
-		// If the last line is empty (due to trailing newline in Split), ignore it loop?
-		// strings.Split("a\n", "\n") -> ["a", ""]
-		// We want "> a\n"
+		if text, ok := texts[rev]; ok {
+			return text, nil
+		}
+		v, err := f.Checkout("", rcs.WithRevision(rev))
+		if err != nil {
+			return "", err
+		}
+		texts[rev] = v.Content
+		return v.Content, nil
+	}
+	return template.FuncMap{
+		"quote": func(content string) string {
+			if content == "" {
+				return "> \n"
+			}
+			if !markdownSafe(content) {
+				return base64Fence(content)
+			}
+			var sb strings.Builder
+			lines := strings.Split(content, "\n")
+			// If the last line is empty (due to trailing newline in Split), ignore it loop?
+			// strings.Split("a\n", "\n") -> ["a", ""]
+			// We want "> a\n"
 
-		for i, line := range lines {
-			if i == len(lines)-1 && line == "" {
-				continue
+			for i, line := range lines {
+				if i == len(lines)-1 && line == "" {
+					continue
+				}
+				sb.WriteString("> " + line + "\n")
+			}
+			return sb.String()
+		},
+		// fence wraps content in a fenced code block long enough not to be
+		// closed by any backticks inside it.
+		"fence": func(info, content string) string {
+			longest, run := 0, 0
+			for _, c := range content {
+				if c == '`' {
+					run++
+					longest = max(longest, run)
+				} else {
+					run = 0
+				}
 			}
-			sb.WriteString("> " + line + "\n")
+			fence := strings.Repeat("`", max(3, longest+1))
+			if content != "" && !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+			return fence + info + "\n" + content + fence + "\n"
+		},
+		"checkout": checkout,
+		// diff is the unified diff between two revisions; an empty revision
+		// stands for an empty file.
+		"diff": func(fromRev, toRev any) (string, error) {
+			from, to := fmt.Sprint(fromRev), fmt.Sprint(toRev)
+			a, err := checkout(from)
+			if err != nil {
+				return "", err
+			}
+			b, err := checkout(to)
+			if err != nil {
+				return "", err
+			}
+			fromName, toName := from, to
+			if fromName == "" {
+				fromName = "/dev/null"
+			}
+			if toName == "" {
+				toName = "/dev/null"
+			}
+			return diff.UnifiedText(fromName, toName, a, b, 3)
+		},
+		"parent": func(rev any) string {
+			return f.RevisionParents()[fmt.Sprint(rev)]
+		},
+		"date": formatRevisionDate,
+		"symbols": func(rev any) []string {
+			var names []string
+			for _, s := range f.Symbols {
+				if s.Revision == fmt.Sprint(rev) {
+					names = append(names, s.Name)
+				}
+			}
+			sort.Strings(names)
+			return names
+		},
+		"resolve": func(sel string) (string, error) {
+			return f.ResolveRevision(sel)
+		},
+		// phrase renders newphrase values as a Go quoted string of their RCS
+		// form, which from-markdown reads back exactly.
+		"phrase": func(v rcs.PhraseValues) string {
+			words := make([]string, len(v))
+			for i, w := range v {
+				words[i] = w.String()
+			}
+			return strconv.Quote(strings.Join(words, " "))
+		},
+	}
+}
+
+// formatRevisionDate formats a revision date in UTC, falling back to the raw
+// RCS date when it does not parse.
+func formatRevisionDate(layout string, d rcs.DateTime) string {
+	t, err := d.DateTime()
+	if err != nil {
+		return d.String()
+	}
+	return t.UTC().Format(layout)
+}
+
+// markdownSafe reports whether content can be block quoted and read back
+// unchanged: valid UTF-8 with no control characters but tab and newline.
+func markdownSafe(content string) bool {
+	if !utf8.ValidString(content) {
+		return false
+	}
+	for _, r := range content {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' {
+			return false
 		}
-		return sb.String()
-	},
-}).Parse(markdownTmpl))
+	}
+	return true
+}
+
+// base64Fence writes content as a base64 fenced code block, which
+// parseMarkdownFile decodes back to the exact bytes.
+func base64Fence(content string) string {
+	enc := base64.StdEncoding.EncodeToString([]byte(content))
+	var sb strings.Builder
+	sb.WriteString("```base64\n")
+	for len(enc) > 76 {
+		sb.WriteString(enc[:76] + "\n")
+		enc = enc[76:]
+	}
+	sb.WriteString(enc + "\n```\n")
+	return sb.String()
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+const markdownTemplateTestMaster = `head	1.2;
+access;
+symbols
+	REL_1:1.1;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author bob;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@second
+@
+text
+@a
+B
+c
+@
+
+
+1.1
+log
+@first
+@
+text
+@d2 1
+a2 1
+b
+@
+`
+
+func renderTestMarkdown(t *testing.T, variant, tmpl string) string {
+	t.Helper()
+	f, err := rcs.ParseFile(strings.NewReader(markdownTemplateTestMaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := ""
+	if tmpl != "" {
+		file = filepath.Join(t.TempDir(), "report.tmpl")
+		if err := os.WriteFile(file, []byte(tmpl), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tt, err := loadMarkdownTemplate(variant, file)
+	if err != nil {
+		t.Fatalf("loadMarkdownTemplate() error = %v", err)
+	}
+	got, err := renderMarkdown(tt, f)
+	if err != nil {
+		t.Fatalf("renderMarkdown() error = %v", err)
+	}
+	return got
+}
+
+func TestMarkdownVariants(t *testing.T) {
+	got := renderTestMarkdown(t, "text", "")
+	if want := "### `1.1`\n\n#### Log\n\n> first\n\n#### Text\n\n```\na\nb\nc\n```\n"; !strings.Contains(got, want) {
+		t.Errorf("text variant missing %q in:\n%s", want, got)
+	}
+
+	got = renderTestMarkdown(t, "diff", "")
+	for _, want := range []string{
+		"```diff\n--- 1.1\n+++ 1.2\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n```\n",
+		"```diff\n--- /dev/null\n+++ 1.1\n@@ -0,0 +1,3 @@\n+a\n+b\n+c\n```\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diff variant missing %q in:\n%s", want, got)
+		}
+	}
+
+	if _, err := loadMarkdownTemplate("nope", ""); err == nil {
+		t.Error("loadMarkdownTemplate(nope) error = nil, want error")
+	}
+}
+
+func TestMarkdownDiffNoNewline(t *testing.T) {
+	// 1.2 drops the final newline that 1.1 has.
+	master := strings.Replace(markdownTemplateTestMaster, "B\nc\n@", "B\nc@", 1)
+	master = strings.Replace(master, "@d2 1\na2 1\nb\n@", "@d2 2\na3 2\nb\nc\n@", 1)
+	f, err := rcs.ParseFile(strings.NewReader(master))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt, err := loadMarkdownTemplate("diff", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := renderMarkdown(tt, f)
+	if err != nil {
+		t.Fatalf("renderMarkdown() error = %v", err)
+	}
+	want := "```diff\n--- 1.1\n+++ 1.2\n@@ -1,3 +1,3 @@\n a\n-b\n-c\n+B\n+c\n\\ No newline at end of file\n```\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("diff variant missing %q in:\n%s", want, got)
+	}
+}
+
+func TestMarkdownCustomTemplate(t *testing.T) {
+	got := renderTestMarkdown(t, "", `# Report
+{{range .Revisions}}- {{.Head.Revision}} {{date "2006-01-02" .Head.Date}}{{range symbols .Head.Revision}} [{{.}}]{{end}}
+{{end}}REL_1 is {{resolve "REL_1"}}: {{checkout (resolve "REL_1") | printf "%q"}}
+`)
+	want := "# Report\n- 1.2 2021-03-04\n- 1.1 2021-03-03 [REL_1]\nREL_1 is 1.1: \"a\\nb\\nc\\n\"\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("custom template mismatch (-want +got):\n%s", diff)
+	}
+
+	got = renderTestMarkdown(t, "", `{{define "text"}}#### Size
+
+{{len (checkout .Head.Revision)}} bytes
+{{end}}`)
+	for _, want := range []string{"## Description", "#### Size\n\n6 bytes\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("block override missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestMarkdownHeaderPhrases(t *testing.T) {
+	f, err := rcs.ParseFile(strings.NewReader(markdownTemplateTestMaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.NewPhrases = rcs.NewPhrases{
+		{Key: "namespace", Value: rcs.PhraseValues{rcs.QuotedString("a \"b\"@"), rcs.SimpleString("v2")}},
+		{Key: "flag"},
+	}
+	md, err := rcsFileToMarkdown(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Phrases\n: `namespace`: \"@a \\\"b\\\"@@@ v2\"\n: `flag`: \"\"\n"; !strings.Contains(md, want) {
+		t.Errorf("markdown missing %q in:\n%s", want, md)
+	}
+	got, err := parseMarkdownFile(strings.NewReader(md))
+	if err != nil {
+		t.Fatalf("parseMarkdownFile() error = %v", err)
+	}
+	if diff := cmp.Diff(f.NewPhrases, got.NewPhrases); diff != "" {
+		t.Errorf("phrases mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarkdownNonUTF8(t *testing.T) {
+	f, err := rcs.ParseFile(strings.NewReader(markdownTemplateTestMaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Description = "caf\xe9\n"
+	f.RevisionContents[0].Log = "r\xe9sum\xe9\n"
+	f.RevisionContents[0].Text = "\x00\x01\xff\xfe binary\r\nline\n"
+	md, err := rcsFileToMarkdown(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.ValidString(md) || !strings.Contains(md, "```base64\n") {
+		t.Errorf("markdown does not base64 encode non-UTF-8 values:\n%s", md)
+	}
+	got, err := parseMarkdownFile(strings.NewReader(md))
+	if err != nil {
+		t.Fatalf("parseMarkdownFile() error = %v", err)
+	}
+	if got.Description != f.Description {
+		t.Errorf("Description = %q, want %q", got.Description, f.Description)
+	}
+	if got.RevisionContents[0].Log != f.RevisionContents[0].Log {
+		t.Errorf("Log = %q, want %q", got.RevisionContents[0].Log, f.RevisionContents[0].Log)
+	}
+	if got.RevisionContents[0].Text != f.RevisionContents[0].Text {
+		t.Errorf("Text = %q, want %q", got.RevisionContents[0].Text, f.RevisionContents[0].Text)
+	}
+}
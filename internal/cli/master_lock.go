@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"os"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/arran4/golang-rcs/repository"
+)
+
+// ErrMasterInUse is returned when another writer holds the lock of an RCS
+// master.
+var ErrMasterInUse = repository.ErrMasterInUse
+
+// ErrReadOnlyMaster is returned when a command would modify a master its
+// storage cannot write, such as one that is compressed or inside an archive.
+var ErrReadOnlyMaster = repository.ErrReadOnly
+
+// repo holds the RCS masters the commands read and write. It is the working
+// directory on disk unless SetRepository replaced it.
+var repo = repository.New(repository.OS())
+
+// SetRepository makes the commands read, lock and write masters in r and
+// returns the repository used before. Working files and output files are
+// still read and written on disk.
+func SetRepository(r *repository.Repository) *repository.Repository {
+	prev := repo
+	repo = r
+	return prev
+}
+
+// lockMaster takes the writer lock of master. Readers keep seeing the old
+// master until the lock is committed, and other writers fail with
+// ErrMasterInUse.
+func lockMaster(master string) (repository.Lock, error) {
+	return repo.Storage.Lock(master)
+}
+
+// readMaster returns the contents of master.
+func readMaster(master string) ([]byte, error) {
+	return repo.Read(master)
+}
+
+// statMaster describes master, or the directory of masters, name.
+func statMaster(name string) (os.FileInfo, error) {
+	return repo.Storage.Stat(name)
+}
+
+// writeMaster atomically replaces master with data under its lock.
+func writeMaster(master string, data []byte, perm os.FileMode) error {
+	return repo.Write(master, data, perm)
+}
+
+// updateMaster locks master, parses it, applies fn and writes the result
+// back. Nothing is written when fn returns an error.
+func updateMaster(master string, fn func(f *rcs.File) error) error {
+	return repo.Update(master, fn)
+}
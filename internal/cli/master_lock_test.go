@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/arran4/golang-rcs/repository"
+)
+
+func TestMasterLockCommit(t *testing.T) {
+	dir := t.TempDir()
+	master := filepath.Join(dir, "file.txt,v")
+	if err := os.WriteFile(master, []byte("old"), 0440); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lockMaster(master)
+	if err != nil {
+		t.Fatalf("lockMaster() error = %v", err)
+	}
+	if _, err := lockMaster(master); !errors.Is(err, ErrMasterInUse) {
+		t.Fatalf("second lockMaster() error = %v, want ErrMasterInUse", err)
+	}
+	if err := writeMaster(master, []byte("other"), 0644); !errors.Is(err, ErrMasterInUse) {
+		t.Fatalf("writeMaster() error = %v, want ErrMasterInUse", err)
+	}
+	if err := l.Commit([]byte("new"), 0644); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	b, err := os.ReadFile(master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "new" {
+		t.Errorf("master = %q, want %q", b, "new")
+	}
+	if runtime.GOOS != "windows" {
+		st, err := os.Stat(master)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Mode().Perm() != 0440 {
+			t.Errorf("mode = %o, want 440", st.Mode().Perm())
+		}
+	}
+	if _, err := os.Stat(repository.LockFileName(master)); !os.IsNotExist(err) {
+		t.Errorf("lock file still exists: %v", err)
+	}
+	if err := l.Release(); err != nil {
+		t.Errorf("Release() after Commit error = %v", err)
+	}
+}
+
+func TestMasterLockRelease(t *testing.T) {
+	dir := t.TempDir()
+	master := filepath.Join(dir, "file.txt,v")
+	if err := os.WriteFile(master, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := lockMaster(master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMaster(master, []byte("new"), 0644); err != nil {
+		t.Fatalf("writeMaster() after Release error = %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 	rcs "github.com/arran4/golang-rcs"
-	"os"
 	"sort"
 	"time"
 )
@@ -47,6 +46,9 @@ func NormalizeRevisions(padCommits, useMmap bool, files ...string) error {
 		if err != nil {
 			return err
 		}
+		if err := checkAccess(r, f, ""); err != nil {
+			return err
+		}
 		rs = append(rs, Pair{
 			Rcs: r,
 			FN:  f,
@@ -134,16 +136,27 @@ func NormalizeRevisions(padCommits, useMmap bool, files ...string) error {
 		r.Rcs.RevisionHeads = newHeads
 		r.Rcs.RevisionContents = newContents
 	}
-	for _, r := range rs {
+	// Every pre-hook runs before the first master is written, so a rejection
+	// leaves all of them untouched.
+	hooks := make([]HookPayload, len(rs))
+	for i, r := range rs {
+		hooks[i] = HookPayload{Event: HookPreRenumber, Command: "normalize-revisions", RCSFile: r.FN, Revision: r.Rcs.Head, User: currentLoggedInUser()}
+		if err := runPreHooks(hooks[i]); err != nil {
+			return err
+		}
+	}
+	for i, r := range rs {
 		if err := WriteFile(r.FN, r.Rcs); err != nil {
 			return err
 		}
+		hooks[i].Event = HookPostRenumber
+		runPostHooks(hooks[i])
 	}
 	return nil
 }
 
 func WriteFile(fn string, file *rcs.File) error {
-	if err := os.WriteFile(fn, []byte(file.String()), 0644); err != nil {
+	if err := writeMaster(fn, []byte(file.String()), 0644); err != nil {
 		return fmt.Errorf("error saving file: %s: %w", fn, err)
 	}
 	fmt.Printf("Wrote: %s\n", fn)
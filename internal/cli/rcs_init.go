@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 
 	rcs "github.com/arran4/golang-rcs"
 )
@@ -28,12 +27,10 @@ func Init(description string, files ...string) error {
 }
 
 func initFile(description, workingFile string) error {
-	rcsFile := workingFile
-	if !strings.HasSuffix(rcsFile, ",v") {
-		rcsFile += ",v"
-	}
+	rcsFile, _ := resolveMaster(workingFile, "")
+	hook := HookPayload{Event: HookPreInit, Command: "init", File: workingFile, RCSFile: rcsFile, User: currentLoggedInUser()}
 
-	if _, err := os.Stat(rcsFile); err == nil {
+	if _, err := statMaster(rcsFile); err == nil {
 		return fmt.Errorf("file %s already exists", rcsFile)
 	} else if !os.IsNotExist(err) {
 		return fmt.Errorf("stat %s: %w", rcsFile, err)
@@ -51,9 +48,24 @@ func initFile(description, workingFile string) error {
 		}
 	}
 
-	if err := os.WriteFile(rcsFile, []byte(f.String()), mode); err != nil {
+	lock, err := lockMaster(rcsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+	if _, err := statMaster(rcsFile); err == nil {
+		return fmt.Errorf("file %s already exists", rcsFile)
+	}
+	if err := runPreHooks(hook); err != nil {
+		return err
+	}
+	if err := lock.Commit([]byte(f.String()), mode); err != nil {
 		return fmt.Errorf("write %s: %w", rcsFile, err)
 	}
+	hook.Event = HookPostInit
+	runPostHooks(hook)
 
 	return nil
 }
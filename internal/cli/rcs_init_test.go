@@ -145,8 +145,8 @@ func TestCoIntegration(t *testing.T) {
 
 	// 2. Checkout with lock (modifies RCS file)
 	// We ask to lock revision 1.1
-	// Co(revision string, lock, unlock bool, user string, quiet bool, checkoutDate, checkoutZone string, files ...string)
-	if err := Co("1.1", true, false, "tester", true, "", "", workFile); err != nil {
+	// Co(revision string, lock, unlock bool, user string, quiet bool, checkoutDate, checkoutZone, suffixes string, files ...string)
+	if err := Co("1.1", true, false, "tester", true, "", "", "", workFile); err != nil {
 		t.Fatalf("Co -l failed: %v", err)
 	}
 
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/arran4/golang-rcs/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+// useRepository points the commands at r for the rest of the test.
+func useRepository(t *testing.T, r *repository.Repository) {
+	t.Helper()
+	prev := SetRepository(r)
+	t.Cleanup(func() {
+		SetRepository(prev)
+	})
+}
+
+func TestCommandsInMemory(t *testing.T) {
+	mem := repository.Memory()
+	mem.WriteFile("proj/a.txt,v", []byte(changesetsTestMaster), 0444)
+	mem.WriteFile("proj/RCS/b.txt,v", []byte(changesetsTestMaster), 0444)
+	useRepository(t, repository.New(mem))
+
+	masters, closeArchives, err := collectMasters([]string{"proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeArchives()
+	if diff := cmp.Diff([]string{"proj/RCS/b.txt,v", "proj/a.txt,v"}, masters); diff != "" {
+		t.Errorf("collectMasters() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := SymbolsAdd("REL", "", "", "", false, "proj/a.txt", "proj/b.txt"); err != nil {
+		t.Fatalf("SymbolsAdd() error = %v", err)
+	}
+	for _, m := range masters {
+		f, err := parseMaster(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(f.Symbols) != 1 || f.Symbols[0].Name != "REL" {
+			t.Errorf("%s symbols = %v, want REL", m, f.Symbols)
+		}
+	}
+	if err := StateAlter("Rel", "1.1", "proj/a.txt,v"); err != nil {
+		t.Fatalf("StateAlter() error = %v", err)
+	}
+	f, err := parseMaster("proj/a.txt,v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rh := f.RevisionHeads[len(f.RevisionHeads)-1]; rh.Revision != "1.1" || rh.State != "Rel" {
+		t.Errorf("revision %s state = %q, want 1.1 Rel", rh.Revision, rh.State)
+	}
+
+	l, err := lockMaster("proj/a.txt,v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = l.Release()
+	}()
+	if err := SymbolsDelete("REL", "proj/a.txt,v"); !errors.Is(err, ErrMasterInUse) {
+		t.Errorf("SymbolsDelete() while locked error = %v, want ErrMasterInUse", err)
+	}
+}
+
+func TestCommandsReadOnlyFS(t *testing.T) {
+	useRepository(t, repository.New(repository.FS(fstest.MapFS{
+		"a.txt,v": {Data: []byte(changesetsTestMaster), Mode: 0444},
+	})))
+	if _, err := parseMaster("a.txt,v"); err != nil {
+		t.Fatalf("parseMaster() error = %v", err)
+	}
+	if err := SymbolsAdd("REL", "", "", "", false, "a.txt"); !errors.Is(err, ErrReadOnlyMaster) {
+		t.Errorf("SymbolsAdd() error = %v, want ErrReadOnlyMaster", err)
+	}
+}
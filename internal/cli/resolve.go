@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arran4/golang-rcs/archivefs"
+	"github.com/arran4/golang-rcs/repository"
+)
+
+// DefaultSuffixes is the GNU RCS default -x suffix list: masters end in ",v",
+// or carry no suffix at all when kept in an RCS directory.
+const DefaultSuffixes = ",v/"
+
+// rcsDirName is the subdirectory searched for masters.
+const rcsDirName = "RCS"
+
+// rcsInitOptions holds the options gorcs understands from the RCSINIT
+// environment variable.
+type rcsInitOptions struct {
+	Suffixes string
+	Zone     string
+	Quiet    bool
+}
+
+// parseRCSInit parses an RCSINIT value. Like GNU RCS, options are separated
+// by spaces and a backslash escapes the next character. Options gorcs has no
+// equivalent for are ignored.
+func parseRCSInit(s string) rcsInitOptions {
+	var opts rcsInitOptions
+	for _, arg := range splitRCSInit(s) {
+		switch {
+		case strings.HasPrefix(arg, "-x"):
+			opts.Suffixes = arg[2:]
+		case strings.HasPrefix(arg, "-z"):
+			opts.Zone = arg[2:]
+		case strings.HasPrefix(arg, "-q"):
+			opts.Quiet = true
+		}
+	}
+	return opts
+}
+
+func splitRCSInit(s string) []string {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+// rcsInit returns the options from the RCSINIT environment variable.
+func rcsInit() rcsInitOptions {
+	return parseRCSInit(os.Getenv("RCSINIT"))
+}
+
+// splitSuffixes turns a -x value such as ",v/" into its suffix list. An
+// empty value means DefaultSuffixes.
+func splitSuffixes(x string) []string {
+	if x == "" {
+		x = DefaultSuffixes
+	}
+	return strings.Split(x, "/")
+}
+
+// effectiveSuffixes picks the suffix list from an explicit -x value, then
+// RCSINIT, then the default.
+func effectiveSuffixes(x string) []string {
+	if x == "" {
+		x = rcsInit().Suffixes
+	}
+	return splitSuffixes(x)
+}
+
+// resolveMaster pairs a command line name with its RCS master and working
+// file the way GNU RCS does. The name may be either. Master names are
+// recognised by a non-empty suffix from the list or by living in an RCS
+// directory. For working files the candidates RCS/file<suffix> and
+// file<suffix> are tried for each suffix in turn; the empty suffix is only
+// tried inside RCS/. When no master exists the first candidate is returned,
+// preferring RCS/ when that directory exists. Compressed masters
+// (",v.gz", ",v.zst") and masters inside archives (archive//member,v) are
+// taken as named.
+func resolveMaster(name, x string) (master, working string) {
+	if _, member, ok := archivefs.SplitPath(name); ok || (repository.IsMasterName(name) && archivefs.TrimCompressionExt(name) != name) {
+		if ok && !repository.IsMasterName(member) {
+			name += ",v"
+		}
+		return name, workingName(name)
+	}
+	suffixes := effectiveSuffixes(x)
+	dir, base := filepath.Split(name)
+	inRCSDir := filepath.Base(filepath.Clean(dir)) == rcsDirName && dir != ""
+
+	for _, sfx := range suffixes {
+		if sfx != "" && strings.HasSuffix(base, sfx) && len(base) > len(sfx) {
+			return name, workingFor(dir, strings.TrimSuffix(base, sfx), inRCSDir)
+		}
+	}
+	if inRCSDir {
+		return name, workingFor(dir, base, true)
+	}
+
+	rcsDir := filepath.Join(dir, rcsDirName)
+	var candidates []string
+	for _, sfx := range suffixes {
+		candidates = append(candidates, filepath.Join(rcsDir, base+sfx))
+		if sfx != "" {
+			candidates = append(candidates, filepath.Join(dir, base+sfx))
+		}
+	}
+	for _, c := range candidates {
+		if st, err := statMaster(c); err == nil && !st.IsDir() {
+			return c, name
+		}
+	}
+
+	sfx := ",v"
+	for _, s := range suffixes {
+		if s != "" {
+			sfx = s
+			break
+		}
+	}
+	if st, err := statMaster(rcsDir); err == nil && st.IsDir() {
+		return filepath.Join(rcsDir, base+sfx), name
+	}
+	return filepath.Join(dir, base+sfx), name
+}
+
+// workingFor derives a working file name from a master's directory and
+// suffix-stripped base name.
+func workingFor(dir, base string, inRCSDir bool) string {
+	if inRCSDir {
+		dir = filepath.Dir(filepath.Clean(dir))
+		if dir == "." {
+			return base
+		}
+		return filepath.Join(dir, base)
+	}
+	return dir + base
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRCSInit(t *testing.T) {
+	got := parseRCSInit(`-q  -x,v/   -zLT -k\ v`)
+	want := rcsInitOptions{Suffixes: ",v/", Zone: "LT", Quiet: true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseRCSInit() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"-a", "b c", "-d"}, splitRCSInit(` -a b\ c	-d `)); diff != "" {
+		t.Errorf("splitRCSInit() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolveMaster(t *testing.T) {
+	dir := t.TempDir()
+	touch := func(p string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch(filepath.Join(dir, "RCS", "a.txt,v"))
+	touch(filepath.Join(dir, "b.txt,v"))
+	touch(filepath.Join(dir, "RCS", "c.txt"))
+	t.Setenv("RCSINIT", "")
+
+	tests := []struct {
+		name        string
+		in          string
+		x           string
+		wantMaster  string
+		wantWorking string
+	}{
+		{"RCS dir", filepath.Join(dir, "a.txt"), "", filepath.Join(dir, "RCS", "a.txt,v"), filepath.Join(dir, "a.txt")},
+		{"same dir", filepath.Join(dir, "b.txt"), "", filepath.Join(dir, "b.txt,v"), filepath.Join(dir, "b.txt")},
+		{"empty suffix in RCS dir", filepath.Join(dir, "c.txt"), "", filepath.Join(dir, "RCS", "c.txt"), filepath.Join(dir, "c.txt")},
+		{"master given", filepath.Join(dir, "RCS", "a.txt,v"), "", filepath.Join(dir, "RCS", "a.txt,v"), filepath.Join(dir, "a.txt")},
+		{"master in RCS without suffix", filepath.Join(dir, "RCS", "c.txt"), "", filepath.Join(dir, "RCS", "c.txt"), filepath.Join(dir, "c.txt")},
+		{"missing prefers RCS dir", filepath.Join(dir, "new.txt"), "", filepath.Join(dir, "RCS", "new.txt,v"), filepath.Join(dir, "new.txt")},
+		{"custom suffix", filepath.Join(dir, "b"), ".txt,v", filepath.Join(dir, "b.txt,v"), filepath.Join(dir, "b")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, working := resolveMaster(tt.in, tt.x)
+			if master != tt.wantMaster || working != tt.wantWorking {
+				t.Errorf("resolveMaster(%q, %q) = %q, %q; want %q, %q", tt.in, tt.x, master, working, tt.wantMaster, tt.wantWorking)
+			}
+		})
+	}
+
+	t.Run("RCSINIT suffixes", func(t *testing.T) {
+		t.Setenv("RCSINIT", "-x.txt,v")
+		master, _ := resolveMaster(filepath.Join(dir, "b"), "")
+		if want := filepath.Join(dir, "b.txt,v"); master != want {
+			t.Errorf("resolveMaster() = %q, want %q", master, want)
+		}
+	})
+}
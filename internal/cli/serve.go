@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/arran4/golang-rcs/rcsweb"
+)
+
+// Serve is a subcommand `gorcs serve`
+//
+// Flags:
+//
+//	addr: -addr Address to listen on (default localhost:8080)
+//	dir: -dir Directory of RCS files to serve (default .)
+func Serve(addr, dir string) error {
+	if addr == "" {
+		addr = "localhost:8080"
+	}
+	if dir == "" {
+		dir = "."
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s: not a directory", dir)
+	}
+	fmt.Printf("Serving %s on http://%s/\n", dir, addr)
+	return http.ListenAndServe(addr, rcsweb.New(os.DirFS(dir)))
+}
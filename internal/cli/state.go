@@ -2,7 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	rcs "github.com/arran4/golang-rcs"
@@ -17,40 +16,63 @@ import (
 //	files: ... List of working files to process
 func StateAlter(state string, revision string, files ...string) error {
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
+		rcsFile, _ := resolveMaster(file, "")
+		if err := stateAlterFile(state, revision, rcsFile); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		b, err := os.ReadFile(rcsFile)
-		if err != nil {
-			return fmt.Errorf("read %s: %w", rcsFile, err)
-		}
+func stateAlterFile(state, revision, rcsFile string) error {
+	lock, err := lockMaster(rcsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
 
-		parsedFile, err := rcs.ParseFile(strings.NewReader(string(b)))
-		if err != nil {
-			return fmt.Errorf("parse %s: %w", rcsFile, err)
-		}
+	b, err := readMaster(rcsFile)
+	if err != nil {
+		return err
+	}
 
-		rev := revision
-		if rev == "" {
-			rev = parsedFile.Head
-		}
+	parsedFile, err := rcs.ParseFile(strings.NewReader(string(b)))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", rcsFile, err)
+	}
 
-		st := state
-		if st == "" {
-			st = "Exp"
-		}
+	if err := checkAccess(parsedFile, rcsFile, ""); err != nil {
+		return err
+	}
 
-		if err := parsedFile.SetState(rev, st); err != nil {
-			return fmt.Errorf("set state in %s: %w", rcsFile, err)
-		}
+	rev := revision
+	if rev == "" {
+		rev = parsedFile.Head
+	}
 
-		// Write back the file
-		if err := os.WriteFile(rcsFile, []byte(parsedFile.String()), 0644); err != nil {
-			return fmt.Errorf("write %s: %w", rcsFile, err)
-		}
+	st := state
+	if st == "" {
+		st = "Exp"
+	}
+
+	hook := HookPayload{Command: "state alter", RCSFile: rcsFile, Revision: rev, User: currentLoggedInUser(), State: st}
+	hook.Event = HookPreStateChange
+	if err := runPreHooks(hook); err != nil {
+		return err
+	}
+
+	if err := parsedFile.SetState(rev, st); err != nil {
+		return fmt.Errorf("set state in %s: %w", rcsFile, err)
 	}
+
+	// Write back the file
+	if err := lock.Commit([]byte(parsedFile.String()), 0644); err != nil {
+		return err
+	}
+	hook.Event = HookPostStateChange
+	runPostHooks(hook)
 	return nil
 }
 
@@ -62,12 +84,9 @@ func StateAlter(state string, revision string, files ...string) error {
 //	files: ... List of working files to process
 func StateGet(revision string, files ...string) error {
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
-		}
+		rcsFile, _ := resolveMaster(file, "")
 
-		f, err := os.Open(rcsFile)
+		f, err := OpenFile(rcsFile, false)
 		if err != nil {
 			return fmt.Errorf("open %s: %w", rcsFile, err)
 		}
@@ -102,12 +121,9 @@ func StateGet(revision string, files ...string) error {
 //	files: ... List of working files to process
 func StateLs(files ...string) error {
 	for _, file := range files {
-		rcsFile := file
-		if !strings.HasSuffix(rcsFile, ",v") {
-			rcsFile += ",v"
-		}
+		rcsFile, _ := resolveMaster(file, "")
 
-		f, err := os.Open(rcsFile)
+		f, err := OpenFile(rcsFile, false)
 		if err != nil {
 			return fmt.Errorf("open %s: %w", rcsFile, err)
 		}
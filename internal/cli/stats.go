@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// Stats is a subcommand `gorcs stats`
+//
+// Flags:
+//
+//	format: -format Output format: text, csv or json (default text)
+//	top: -top Number of most churned files to list (default 10)
+//	output: -o --output Output file path
+//	force: -f --force Force overwrite output
+//	files: ... RCS files or directories to scan for ,v files
+func Stats(format string, top int, output string, force bool, files ...string) error {
+	if top < 0 {
+		return fmt.Errorf("invalid -top %d: must not be negative", top)
+	}
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	masters, closeArchives, err := collectMasters(files)
+	if err != nil {
+		return err
+	}
+	defer closeArchives()
+	var named []rcs.NamedFile
+	for _, m := range masters {
+		f, err := parseMaster(m)
+		if err != nil {
+			return err
+		}
+		named = append(named, rcs.NamedFile{Name: workingName(m), File: f})
+	}
+	st := rcs.BuildStats(named, rcs.StatsOptions{Top: top})
+
+	var buf bytes.Buffer
+	switch format {
+	case "", "text":
+		writeStatsText(&buf, st)
+	case "csv":
+		if err := writeStatsCSV(&buf, st); err != nil {
+			return err
+		}
+	case "json":
+		b, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error serializing stats: %w", err)
+		}
+		buf.Write(b)
+		buf.WriteString("\n")
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	if output != "" && output != "-" {
+		return writeOutput(output, buf.Bytes(), force)
+	}
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+func statsDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+func writeStatsText(w io.Writer, st *rcs.Stats) {
+	fmt.Fprintf(w, "Revisions: %d\n", st.Total.Revisions)
+	fmt.Fprintf(w, "Lines: +%d -%d\n", st.Total.Added, st.Total.Removed)
+	fmt.Fprintf(w, "First change: %s\n", statsDate(st.Total.First))
+	fmt.Fprintf(w, "Last change: %s\n", statsDate(st.Total.Last))
+	for _, section := range []struct {
+		title, column string
+		rows          []*rcs.Churn
+	}{
+		{"By author", "AUTHOR", st.Authors},
+		{"By month", "MONTH", st.Months},
+		{"By file", "FILE", st.Files},
+		{"Most churned files", "FILE", st.MostChurned},
+	} {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "  %s\tREVISIONS\tADDED\tREMOVED\tFIRST\tLAST\n", section.column)
+		for _, c := range section.rows {
+			fmt.Fprintf(tw, "  %s\t%d\t+%d\t-%d\t%s\t%s\n", c.Name, c.Revisions, c.Added, c.Removed, statsDate(c.First), statsDate(c.Last))
+		}
+		_ = tw.Flush()
+	}
+}
+
+// writeStatsCSV writes every group as rows of one table; the group column is
+// total, author, month, file or most-churned.
+func writeStatsCSV(w io.Writer, st *rcs.Stats) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"group", "name", "revisions", "added", "removed", "first", "last"})
+	row := func(group string, c *rcs.Churn) {
+		_ = cw.Write([]string{group, c.Name, strconv.Itoa(c.Revisions), strconv.Itoa(c.Added), strconv.Itoa(c.Removed), statsDate(c.First), statsDate(c.Last)})
+	}
+	row("total", &st.Total)
+	for _, g := range []struct {
+		name string
+		rows []*rcs.Churn
+	}{
+		{"author", st.Authors},
+		{"month", st.Months},
+		{"file", st.Files},
+		{"most-churned", st.MostChurned},
+	} {
+		for _, c := range g.rows {
+			row(g.name, c)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
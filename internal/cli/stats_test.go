@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	master := filepath.Join(dir, "a.txt,v")
+	if err := os.WriteFile(master, []byte(htmlTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "stats.csv")
+	if err := Stats("csv", 0, out, false, dir); err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"group,name,revisions,added,removed,first,last\n",
+		"total,,3,2,1,2021-03-03,2021-03-05\n",
+		"author,bob,1,1,1,2021-03-04,2021-03-04\n",
+		"month,2021-03,3,2,1,2021-03-03,2021-03-05\n",
+		"most-churned," + filepath.Join(dir, "a.txt") + ",3,2,1,",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("output missing %q in:\n%s", want, b)
+		}
+	}
+
+	if err := Stats("xml", 0, "", false, dir); err == nil {
+		t.Error("Stats(xml) error = nil, want error")
+	}
+	if err := Stats("csv", -1, "", false, dir); err == nil {
+		t.Error("Stats(top -1) error = nil, want error")
+	}
+}
+
+func TestStats_Archive(t *testing.T) {
+	dir := t.TempDir()
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	tw := tar.NewWriter(zw)
+	var compressed bytes.Buffer
+	cw := gzip.NewWriter(&compressed)
+	if _, err := cw.Write([]byte(htmlTestMaster)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		"proj/RCS/a.txt,v": []byte(htmlTestMaster),
+		"proj/b.txt,v.gz":  compressed.Bytes(),
+		"proj/README":      []byte("readme\n"),
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0444, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "backup.tar.gz")
+	if err := os.WriteFile(archive, gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, arg := range []string{archive, archive + "//proj"} {
+		out := filepath.Join(dir, "stats.csv")
+		if err := Stats("csv", 0, out, true, arg); err != nil {
+			t.Fatalf("Stats(%s) error = %v", arg, err)
+		}
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"total,,6,4,2,", "file,proj/a.txt,3,", "file,proj/b.txt,3,"} {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("Stats(%s) output missing %q in:\n%s", arg, want, b)
+			}
+		}
+	}
+
+	f, err := parseMaster(archive + "//proj/b.txt,v.gz")
+	if err != nil {
+		t.Fatalf("parseMaster() error = %v", err)
+	}
+	if f.Head != "1.2" {
+		t.Errorf("Head = %q, want 1.2", f.Head)
+	}
+	if _, err := lockMaster(archive + "//proj/b.txt,v.gz"); !errors.Is(err, ErrReadOnlyMaster) {
+		t.Errorf("lockMaster() error = %v, want ErrReadOnlyMaster", err)
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// SymbolsAdd is a subcommand `gorcs symbols add`
+//
+// Flags:
+//
+//	name: -name symbolic name to assign
+//	revision: -rev revision or branch to tag (defaults to head)
+//	date: -d --date tag the revision current at this date
+//	zone: -z --zone zone for date parsing (e.g. "LT", "UTC", "-0700")
+//	force: -f --force move the symbol if it is already bound to another revision
+//	files: ... List of working files to process
+func SymbolsAdd(name, revision, date, zone string, force bool, files ...string) error {
+	return setSymbols("symbols add", name, revision, date, zone, force, files)
+}
+
+// SymbolsMove is a subcommand `gorcs symbols move`
+//
+// Flags:
+//
+//	name: -name symbolic name to move
+//	revision: -rev revision or branch to tag (defaults to head)
+//	date: -d --date tag the revision current at this date
+//	zone: -z --zone zone for date parsing (e.g. "LT", "UTC", "-0700")
+//	files: ... List of working files to process
+func SymbolsMove(name, revision, date, zone string, files ...string) error {
+	return setSymbols("symbols move", name, revision, date, zone, true, files)
+}
+
+func setSymbols(command, name, revision, date, zone string, force bool, files []string) error {
+	if name == "" {
+		return fmt.Errorf("symbol name is required")
+	}
+	if err := rcs.ValidateSymbolName(name); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no files provided")
+	}
+	var at time.Time
+	if date != "" {
+		if zone == "" {
+			zone = rcsInit().Zone
+		}
+		loc, err := rcs.ParseZone(zone)
+		if err != nil {
+			return fmt.Errorf("invalid zone %q: %w", zone, err)
+		}
+		if at, err = rcs.ParseDate(date, time.Now(), loc); err != nil {
+			return fmt.Errorf("invalid date %q: %w", date, err)
+		}
+	}
+	for _, file := range files {
+		rcsFile, workingFile := resolveMaster(file, "")
+		hook := HookPayload{Command: command, File: workingFile, RCSFile: rcsFile, User: currentLoggedInUser(), Symbol: name}
+		err := updateMaster(rcsFile, func(f *rcs.File) error {
+			if err := f.CheckAccess(currentLoggedInUser()); err != nil {
+				return err
+			}
+			rev := revision
+			if !at.IsZero() {
+				var err error
+				if rev, err = f.ResolveRevisionAtDate(revision, at, nil); err != nil {
+					return err
+				}
+			} else if rev == "" {
+				rev = f.Head
+			}
+			hook.Event, hook.Revision = HookPreTag, rev
+			if err := runPreHooks(hook); err != nil {
+				return err
+			}
+			return f.SetSymbol(name, rev, force)
+		})
+		if err != nil {
+			return err
+		}
+		hook.Event = HookPostTag
+		runPostHooks(hook)
+	}
+	return nil
+}
+
+// SymbolsDelete is a subcommand `gorcs symbols delete`
+//
+// Flags:
+//
+//	name: -name symbolic name to delete
+//	files: ... List of working files to process
+func SymbolsDelete(name string, files ...string) error {
+	if name == "" {
+		return fmt.Errorf("symbol name is required")
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no files provided")
+	}
+	for _, file := range files {
+		rcsFile, workingFile := resolveMaster(file, "")
+		hook := HookPayload{Command: "symbols delete", File: workingFile, RCSFile: rcsFile, User: currentLoggedInUser(), Symbol: name}
+		if err := updateMaster(rcsFile, func(f *rcs.File) error {
+			if err := f.CheckAccess(currentLoggedInUser()); err != nil {
+				return err
+			}
+			for _, s := range f.Symbols {
+				if s.Name == name {
+					hook.Revision = s.Revision
+				}
+			}
+			hook.Event = HookPreUntag
+			if err := runPreHooks(hook); err != nil {
+				return err
+			}
+			return f.DeleteSymbol(name)
+		}); err != nil {
+			return err
+		}
+		hook.Event = HookPostUntag
+		runPostHooks(hook)
+	}
+	return nil
+}
+
+// SymbolsList is a subcommand `gorcs symbols list`
+//
+// Flags:
+//
+//	files: ... List of working files to process
+func SymbolsList(files ...string) error {
+	for _, file := range files {
+		rcsFile, _ := resolveMaster(file, "")
+
+		f, err := OpenFile(rcsFile, false)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", rcsFile, err)
+		}
+
+		parsedFile, err := rcs.ParseFile(f)
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("close %s: %w", rcsFile, err)
+		}
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", rcsFile, err)
+		}
+
+		if len(files) > 1 {
+			fmt.Printf("File: %s\n", file)
+		}
+		for _, s := range parsedFile.Symbols {
+			fmt.Printf("%s:%s\n", s.Name, s.Revision)
+		}
+		if len(files) > 1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func readSymbols(t *testing.T, fn string) []*rcs.Symbol {
+	t.Helper()
+	f, err := parseMaster(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Symbols
+}
+
+func TestSymbolsCommands(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		fn := filepath.Join(dir, name)
+		if err := os.WriteFile(fn+",v", []byte(changesetsTestMaster), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, fn)
+	}
+
+	if err := SymbolsAdd("REL", "", "", "", false, files...); err != nil {
+		t.Fatalf("SymbolsAdd() error = %v", err)
+	}
+	if err := SymbolsAdd("OLD", "", "2021-03-03 12:00:00", "UTC", false, files...); err != nil {
+		t.Fatalf("SymbolsAdd(date) error = %v", err)
+	}
+	for _, fn := range files {
+		want := []*rcs.Symbol{{Name: "OLD", Revision: "1.1"}, {Name: "REL", Revision: "1.2"}}
+		if diff := cmp.Diff(want, readSymbols(t, fn+",v")); diff != "" {
+			t.Errorf("%s symbols mismatch (-want +got):\n%s", fn, diff)
+		}
+	}
+
+	if err := SymbolsAdd("REL", "1.1", "", "", false, files...); !errors.Is(err, rcs.ErrSymbolExists) {
+		t.Fatalf("SymbolsAdd() without force error = %v, want ErrSymbolExists", err)
+	}
+	if err := SymbolsMove("REL", "1.1", "", "", files...); err != nil {
+		t.Fatalf("SymbolsMove() error = %v", err)
+	}
+	if err := SymbolsDelete("OLD", files...); err != nil {
+		t.Fatalf("SymbolsDelete() error = %v", err)
+	}
+	for _, fn := range files {
+		want := []*rcs.Symbol{{Name: "REL", Revision: "1.1"}}
+		if diff := cmp.Diff(want, readSymbols(t, fn+",v")); diff != "" {
+			t.Errorf("%s symbols mismatch (-want +got):\n%s", fn, diff)
+		}
+	}
+
+	if err := SymbolsAdd("bad:name", "", "", "", false, files...); !errors.Is(err, rcs.ErrInvalidSymbolName) {
+		t.Errorf("SymbolsAdd() invalid name error = %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arran4/golang-rcs/repository"
+)
+
+// JournalDirEnv names the environment variable holding the directory where
+// masters are backed up before they are rewritten. The journal is off while
+// it is unset.
+const JournalDirEnv = "GORCS_JOURNAL_DIR"
+
+// EnableJournal makes the commands back up every master to the journal
+// before rewriting it, recording command as the command line. It does
+// nothing unless JournalDirEnv is set. Only commands that lock a master
+// write to the journal; the backups are what `gorcs undo` restores.
+func EnableJournal(command []string) {
+	dir := os.Getenv(JournalDirEnv)
+	if dir == "" {
+		return
+	}
+	repo = repository.New(repository.Journaled(repo.Storage, &repository.Journal{Dir: dir}, command, currentLoggedInUser()))
+}
+
+// Undo is a subcommand `gorcs undo`
+//
+// Flags:
+//
+//	list: -l --list List the journal instead of undoing
+//	ids: ... Changes to undo, by change or run ID; the most recent run when omitted
+func Undo(list bool, ids ...string) error {
+	dir := os.Getenv(JournalDirEnv)
+	if dir == "" {
+		return fmt.Errorf("the journal is off; set %s to turn it on", JournalDirEnv)
+	}
+	j := &repository.Journal{Dir: dir}
+	if list {
+		return undoList(j)
+	}
+	if len(ids) == 0 {
+		ids = []string{""}
+	}
+	// Restoring must not journal itself, or undo would only undo the undo.
+	s := repo.Storage
+	if js, ok := s.(*repository.JournaledStorage); ok {
+		s = js.Base
+	}
+	for _, id := range ids {
+		entries, err := j.Select(id)
+		if err != nil {
+			return err
+		}
+		if err := j.Undo(s, entries); err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := j.MarkUndone(entries); err != nil {
+				return fmt.Errorf("journal: %w", err)
+			}
+		}
+		verb := "Restored"
+		if dryRun {
+			verb = "Would restore"
+		}
+		for _, e := range entries {
+			fmt.Printf("%s %s from %s (%s)\n", verb, e.Master, e.ID, strings.Join(e.Command, " "))
+		}
+	}
+	return nil
+}
+
+func undoList(j *repository.Journal) error {
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("the journal is empty")
+	}
+	for _, e := range entries {
+		var notes []string
+		if e.Created {
+			notes = append(notes, "created")
+		}
+		if e.Undone {
+			notes = append(notes, "undone")
+		}
+		note := ""
+		if len(notes) > 0 {
+			note = " [" + strings.Join(notes, ", ") + "]"
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s%s\n", e.ID, e.Time.Format(time.RFC3339), e.User, e.Master, strings.Join(e.Command, " "), note)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arran4/golang-rcs/repository"
+)
+
+func TestUndo(t *testing.T) {
+	t.Setenv(JournalDirEnv, t.TempDir())
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		fn := filepath.Join(dir, name)
+		if err := os.WriteFile(fn+",v", []byte(changesetsTestMaster), 0444); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, fn)
+	}
+
+	prev := repo
+	t.Cleanup(func() {
+		repo = prev
+	})
+	EnableJournal([]string{"gorcs", "state", "alter"})
+	if err := StateAlter("Rel", "1.2", files...); err != nil {
+		t.Fatalf("StateAlter() error = %v", err)
+	}
+	if err := Undo(true); err != nil {
+		t.Fatalf("Undo(list) error = %v", err)
+	}
+
+	// Editing a master after the change blocks the undo.
+	edited, err := os.ReadFile(files[1] + ",v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(files[1]+",v", append(edited, '\n'), 0444); err != nil {
+		t.Fatal(err)
+	}
+	if err := Undo(false); !errors.Is(err, repository.ErrMasterChanged) {
+		t.Fatalf("Undo() error = %v, want ErrMasterChanged", err)
+	}
+	if err := os.WriteFile(files[1]+",v", edited, 0444); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Undo(false); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	for _, fn := range files {
+		b, err := os.ReadFile(fn + ",v")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != changesetsTestMaster {
+			t.Errorf("%s,v not restored:\n%s", fn, b)
+		}
+	}
+	// Undo itself is not journaled, so there is nothing left to undo.
+	if err := Undo(false); !errors.Is(err, repository.ErrNothingToUndo) {
+		t.Errorf("second Undo() error = %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestJournalOffByDefault(t *testing.T) {
+	t.Setenv(JournalDirEnv, "")
+	prev := repo
+	t.Cleanup(func() {
+		repo = prev
+	})
+	EnableJournal([]string{"gorcs", "state", "alter"})
+	if _, ok := repo.Storage.(*repository.JournaledStorage); ok {
+		t.Error("EnableJournal() turned the journal on without " + JournalDirEnv)
+	}
+	if err := Undo(false); err == nil {
+		t.Error("Undo() with the journal off: error = nil")
+	}
+}
@@ -2,9 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"github.com/arran4/golang-rcs/archivefs"
+	"github.com/arran4/golang-rcs/repository"
 	"golang.org/x/exp/mmap"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // ensureFiles checks if file arguments are provided.
@@ -43,20 +48,77 @@ func OpenFile(filename string, useMmap bool) (io.ReadCloser, error) {
 	if filename == "-" {
 		return io.NopCloser(os.Stdin), nil
 	}
-	if useMmap {
-		r, err := mmap.Open(filename)
-		if err != nil {
-			return nil, err
-		}
-		sr := io.NewSectionReader(r, 0, int64(r.Len()))
-		return &mmapReadCloser{
-			SectionReader: sr,
-			closer:        r.Close,
-		}, nil
+	d, onDisk := repo.Storage.(*repository.DirStorage)
+	if _, _, inArchive := archivefs.SplitPath(filename); !useMmap || !onDisk || inArchive {
+		return repo.Storage.Open(filename)
 	}
-	f, err := os.Open(filename)
+	r, err := mmap.Open(d.Path(filename))
 	if err != nil {
 		return nil, err
 	}
-	return f, nil
+	f := &mmapReadCloser{
+		SectionReader: io.NewSectionReader(r, 0, int64(r.Len())),
+		closer:        r.Close,
+	}
+	dr, err := archivefs.Decompress(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return dr, nil
+}
+
+// collectMasters expands the given paths into a list of RCS master files.
+// Directories are walked recursively for files ending in ",v" (or ",v.gz"
+// and ",v.zst"); other paths have ",v" appended when missing. An archive, or
+// a directory inside one named as archive//dir, is walked the same way and
+// its masters follow the others in archive order. The archives stay open
+// so their masters are read without reopening them; the caller closes them
+// with the returned function once it is done reading.
+func collectMasters(paths []string) ([]string, func() error, error) {
+	r := repo
+	var masters, archived []string
+	for _, p := range paths {
+		_, member, inArchive := archivefs.SplitPath(p)
+		if (inArchive && !repository.IsMasterName(member)) || (!inArchive && archivefs.IsArchive(p)) {
+			found, err := r.Storage.List(p)
+			if err != nil {
+				_ = r.Close()
+				return nil, nil, fmt.Errorf("walk %s: %w", p, err)
+			}
+			archived = append(archived, found...)
+			continue
+		}
+		if st, err := statMaster(p); err == nil && st.IsDir() {
+			found, err := r.Storage.List(p)
+			if err != nil {
+				_ = r.Close()
+				return nil, nil, fmt.Errorf("walk %s: %w", p, err)
+			}
+			masters = append(masters, found...)
+			continue
+		}
+		master, _ := resolveMaster(p, "")
+		masters = append(masters, master)
+	}
+	sort.Strings(masters)
+	return append(masters, archived...), r.Close, nil
+}
+
+// workingName derives the working file name reported for a master: the ",v"
+// suffix is dropped along with any RCS or CVS Attic directory component.
+func workingName(master string) string {
+	if _, member, ok := archivefs.SplitPath(master); ok {
+		master = member
+	}
+	name := strings.TrimSuffix(archivefs.TrimCompressionExt(master), ",v")
+	dir, base := filepath.Split(name)
+	dir = filepath.Clean(dir)
+	if b := filepath.Base(dir); b == "RCS" || b == "Attic" {
+		dir = filepath.Dir(dir)
+	}
+	if dir == "." {
+		return base
+	}
+	return filepath.Join(dir, base)
 }
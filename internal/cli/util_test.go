@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenFile_Mmap(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "a.txt,v")
+	if err := os.WriteFile(plain, []byte(htmlTestMaster), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write([]byte(htmlTestMaster)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	compressed := filepath.Join(dir, "b.txt,v.gz")
+	if err := os.WriteFile(compressed, gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		readerAt bool
+	}{
+		{plain, true},
+		{compressed, false},
+	} {
+		r, err := OpenFile(tt.name, true)
+		if err != nil {
+			t.Fatalf("OpenFile(%s) error = %v", tt.name, err)
+		}
+		if _, ok := r.(io.ReaderAt); ok != tt.readerAt {
+			t.Errorf("OpenFile(%s) is io.ReaderAt = %v, want %v", tt.name, ok, tt.readerAt)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != htmlTestMaster {
+			t.Errorf("OpenFile(%s) = %q, want the master", tt.name, b)
+		}
+	}
+}
@@ -9,13 +9,14 @@ import "os"
 //	output: -o --output Output file path
 //	force: -f --force Force overwrite output
 //	mmap: -m --mmap Use mmap to read file
+//	errorFormat: --error-format Report parse errors as text (compiler style) or json and go on with the other files
 //	files: ... List of files to process, or - for stdin
-func Validate(output string, force, useMmap bool, files ...string) error {
+func Validate(output string, force, useMmap bool, errorFormat string, files ...string) error {
 	var err error
 	if files, err = ensureFiles(files); err != nil {
 		return err
 	}
 	// Validate is currently functionally identical to Format (parse and re-serialize).
 	// If validation rules diverge in future, logic can be separated here.
-	return runFormat(os.Stdin, os.Stdout, output, force, false, false, false, useMmap, files...)
+	return runFormat(os.Stdin, os.Stdout, os.Stderr, output, force, false, false, false, useMmap, false, errorFormat, files...)
 }
@@ -0,0 +1,415 @@
+package rcs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arran4/golang-rcs/diff"
+)
+
+// JSONOptions selects the derived data File.ToJSON adds next to the parsed
+// master. With every option off the output is plain json.Marshal(f), which
+// `gorcs from-json` reads back.
+type JSONOptions struct {
+	Indent bool
+	// Text includes the full reconstructed text of every revision.
+	Text bool
+	// Deltas includes each stored delta parsed into add and delete commands.
+	Deltas bool
+	// Links includes the parent and children of every revision.
+	Links bool
+	// Stats includes line counts and lines added and deleted relative to
+	// the parent revision.
+	Stats bool
+}
+
+// JSONFile is the document File.ToJSON produces. Revisions is only present
+// when one of the JSONOptions asks for derived data.
+type JSONFile struct {
+	*File
+	Revisions []*JSONRevision `json:",omitempty"`
+}
+
+// JSONRevision carries the derived data for one revision. DeltaBase is the
+// revision the stored delta applies to: the newer trunk revision for trunk
+// deltas and the previous revision for branch deltas. The head stores its
+// full text and so has neither DeltaBase nor Delta.
+type JSONRevision struct {
+	Revision  string
+	Text      *string        `json:",omitempty"`
+	DeltaBase string         `json:",omitempty"`
+	Delta     []DeltaCommand `json:",omitempty"`
+	Parent    string         `json:",omitempty"`
+	Children  []string       `json:",omitempty"`
+	Stats     *RevisionStats `json:",omitempty"`
+}
+
+// DeltaCommand is one command of an RCS delta. Op is "a" to add Lines after
+// line Line of the base, or "d" to delete Count lines starting at Line.
+// NoNewline is set when the last added line is the last line of the
+// revision and has no newline.
+type DeltaCommand struct {
+	Op        string
+	Line      int
+	Count     int
+	Lines     []string `json:",omitempty"`
+	NoNewline bool     `json:",omitempty"`
+}
+
+// RevisionStats counts the lines of a revision and the lines added and
+// deleted relative to its parent.
+type RevisionStats struct {
+	Lines   int
+	Added   int
+	Deleted int
+}
+
+// ToJSON serialises the file, adding the derived data selected by opts so
+// consumers do not have to apply RCS deltas themselves.
+func (f *File) ToJSON(opts JSONOptions) ([]byte, error) {
+	doc := JSONFile{File: f}
+	if opts.Text || opts.Deltas || opts.Links || opts.Stats {
+		revs, err := f.jsonRevisions(opts)
+		if err != nil {
+			return nil, err
+		}
+		doc.Revisions = revs
+	}
+	if opts.Indent {
+		return json.MarshalIndent(doc, "", "  ")
+	}
+	return json.Marshal(doc)
+}
+
+func (f *File) jsonRevisions(opts JSONOptions) ([]*JSONRevision, error) {
+	var texts map[string]string
+	if opts.Text || opts.Stats {
+		var err error
+		if texts, err = f.RevisionTexts(); err != nil {
+			return nil, err
+		}
+	}
+	deltas := make(map[string]string, len(f.RevisionContents))
+	for _, rc := range f.RevisionContents {
+		deltas[rc.Revision] = rc.Text
+	}
+	bases := map[string]string{}
+	for _, rh := range f.RevisionHeads {
+		for _, b := range rh.Branches {
+			bases[b.String()] = rh.Revision.String()
+		}
+		if next := rh.NextRevision.String(); next != "" {
+			bases[next] = rh.Revision.String()
+		}
+	}
+	parents := f.RevisionParents()
+	children := f.RevisionChildren()
+
+	revs := make([]*JSONRevision, 0, len(f.RevisionHeads))
+	for _, rh := range f.RevisionHeads {
+		rev := rh.Revision.String()
+		jr := &JSONRevision{Revision: rev}
+		if opts.Text {
+			text := texts[rev]
+			jr.Text = &text
+		}
+		if opts.Deltas && rev != f.Head {
+			ed, err := diff.ParseDelta(deltas[rev])
+			if err != nil {
+				return nil, fmt.Errorf("revision %s: %w", rev, err)
+			}
+			jr.DeltaBase = bases[rev]
+			jr.Delta = deltaCommands(ed)
+		}
+		if opts.Links {
+			jr.Parent = parents[rev]
+			jr.Children = children[rev]
+		}
+		if opts.Stats {
+			stats, err := lineStats(diff.SplitText(texts[parents[rev]]), diff.SplitText(texts[rev]))
+			if err != nil {
+				return nil, fmt.Errorf("revision %s: %w", rev, err)
+			}
+			jr.Stats = stats
+		}
+		revs = append(revs, jr)
+	}
+	return revs, nil
+}
+
+func deltaCommands(ed diff.EdDiff) []DeltaCommand {
+	cmds := make([]DeltaCommand, 0, len(ed))
+	for _, c := range ed {
+		switch c := c.(type) {
+		case diff.Add:
+			dc := DeltaCommand{Op: "a", Line: c.LineStart, Count: len(c.Lines), Lines: make([]string, len(c.Lines))}
+			for i, l := range c.Lines {
+				dc.Lines[i] = strings.TrimSuffix(l, "\n")
+			}
+			dc.NoNewline = len(c.Lines) > 0 && !strings.HasSuffix(c.Lines[len(c.Lines)-1], "\n")
+			cmds = append(cmds, dc)
+		case diff.Delete:
+			cmds = append(cmds, DeltaCommand{Op: "d", Line: c[0], Count: c[1]})
+		}
+	}
+	return cmds
+}
+
+// lineStats compares lines split by diff.SplitText, so a revision that only
+// adds or drops the final newline changes its last line.
+func lineStats(from, to []string) (*RevisionStats, error) {
+	ed, err := diff.Generate(from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats := &RevisionStats{Lines: len(to)}
+	for _, c := range ed {
+		switch c := c.(type) {
+		case diff.Add:
+			stats.Added += len(c.Lines)
+		case diff.Delete:
+			stats.Deleted += c[1]
+		}
+	}
+	return stats, nil
+}
+
+// RevisionTexts reconstructs the text of every revision in one pass: down
+// the trunk from the head, then forwards along each branch from its branch
+// point. The map is keyed by revision number.
+func (f *File) RevisionTexts() (map[string]string, error) {
+	texts := map[string]string{}
+	err := f.WalkRevisionTexts(func(rev, text string) error {
+		texts[rev] = text
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return texts, nil
+}
+
+// WalkRevisionTexts calls fn with the text of every revision in the order
+// RevisionTexts reconstructs them. Each delta is applied to the text of the
+// revision before it, and only the texts of pending branch points are kept
+// between calls. An error from fn stops the walk and is returned.
+func (f *File) WalkRevisionTexts(fn func(rev, text string) error) error {
+	if f.Head == "" {
+		return nil
+	}
+	heads := make(map[string]*RevisionHead, len(f.RevisionHeads))
+	for _, rh := range f.RevisionHeads {
+		heads[rh.Revision.String()] = rh
+	}
+	contents := make(map[string]*RevisionContent, len(f.RevisionContents))
+	for _, rc := range f.RevisionContents {
+		contents[rc.Revision] = rc
+	}
+	headContent, ok := contents[f.Head]
+	if !ok {
+		return fmt.Errorf("head revision %q content not found", f.Head)
+	}
+
+	type branchStart struct {
+		rev, base string
+	}
+	var queue []branchStart
+	seen := map[string]bool{}
+	visit := func(rev, text string) error {
+		seen[rev] = true
+		if err := fn(rev, text); err != nil {
+			return err
+		}
+		if rh, ok := heads[rev]; ok {
+			for _, b := range rh.Branches {
+				queue = append(queue, branchStart{rev: b.String(), base: text})
+			}
+		}
+		return nil
+	}
+	walk := func(rev, base string) error {
+		for rev != "" {
+			if seen[rev] {
+				return fmt.Errorf("loop detected at revision %q", rev)
+			}
+			rc, ok := contents[rev]
+			if !ok {
+				return fmt.Errorf("revision content %q not found", rev)
+			}
+			text, err := applyDelta(base, rc.Text)
+			if err != nil {
+				return fmt.Errorf("apply delta for %q: %w", rev, err)
+			}
+			if err := visit(rev, text); err != nil {
+				return err
+			}
+			rh, ok := heads[rev]
+			if !ok {
+				return fmt.Errorf("revision header %q not found", rev)
+			}
+			rev, base = rh.NextRevision.String(), text
+		}
+		return nil
+	}
+
+	if err := visit(f.Head, headContent.Text); err != nil {
+		return err
+	}
+	if rh, ok := heads[f.Head]; ok {
+		if err := walk(rh.NextRevision.String(), headContent.Text); err != nil {
+			return err
+		}
+	}
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		if err := walk(b.rev, b.base); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSONBinaryPrefix marks a JSON string that holds the base64 encoding of a
+// value rather than the value itself. encoding/json can only carry valid
+// UTF-8, so values that are not, such as Latin-1 author names or binary
+// revision texts, are written this way, as are values that happen to start
+// with the prefix.
+const JSONBinaryPrefix = "base64:"
+
+func encodeJSONString(s string) string {
+	if utf8.ValidString(s) && !strings.HasPrefix(s, JSONBinaryPrefix) {
+		return s
+	}
+	return JSONBinaryPrefix + base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func decodeJSONString(s string) (string, error) {
+	if !strings.HasPrefix(s, JSONBinaryPrefix) {
+		return s, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s[len(JSONBinaryPrefix):])
+	if err != nil {
+		return "", fmt.Errorf("decoding %s value: %w", JSONBinaryPrefix, err)
+	}
+	return string(b), nil
+}
+
+// fileJSON and jsonFileJSON have the fields of File and JSONFile without
+// their methods, so the methods below can marshal them.
+type fileJSON File
+
+type jsonFileJSON struct {
+	*fileJSON
+	Revisions []*JSONRevision `json:",omitempty"`
+}
+
+// MarshalJSON writes the file with values that are not valid UTF-8
+// base64 encoded, see JSONBinaryPrefix.
+func (f File) MarshalJSON() ([]byte, error) {
+	c, err := mapStrings(reflect.ValueOf(&f), encodeJSONString)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal((*fileJSON)(c.Interface().(*File)))
+}
+
+// UnmarshalJSON reads a file written by MarshalJSON.
+func (f *File) UnmarshalJSON(b []byte) error {
+	var raw fileJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	c, err := mapStringsErr(reflect.ValueOf((*File)(&raw)), decodeJSONString)
+	if err != nil {
+		return err
+	}
+	*f = *c.Interface().(*File)
+	return nil
+}
+
+// MarshalJSON encodes the file and its derived data as File.MarshalJSON
+// does.
+func (d JSONFile) MarshalJSON() ([]byte, error) {
+	c, err := mapStrings(reflect.ValueOf(&d), encodeJSONString)
+	if err != nil {
+		return nil, err
+	}
+	cd := c.Interface().(*JSONFile)
+	return json.Marshal(jsonFileJSON{fileJSON: (*fileJSON)(cd.File), Revisions: cd.Revisions})
+}
+
+// UnmarshalJSON reads a file written by JSONFile.MarshalJSON.
+func (d *JSONFile) UnmarshalJSON(b []byte) error {
+	raw := jsonFileJSON{fileJSON: new(fileJSON)}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	c, err := mapStringsErr(reflect.ValueOf(&JSONFile{File: (*File)(raw.fileJSON), Revisions: raw.Revisions}), decodeJSONString)
+	if err != nil {
+		return err
+	}
+	*d = *c.Interface().(*JSONFile)
+	return nil
+}
+
+// mapStrings returns a deep copy of v, a pointer, with fn applied to every
+// string it holds. Values behind interfaces are copied as they are;
+// PhraseValues encodes its own words.
+func mapStrings(v reflect.Value, fn func(string) string) (reflect.Value, error) {
+	return mapStringsErr(v, func(s string) (string, error) { return fn(s), nil })
+}
+
+func mapStringsErr(v reflect.Value, fn func(string) (string, error)) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := fn(v.String())
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(s).Convert(v.Type()), nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, nil
+		}
+		e, err := mapStringsErr(v.Elem(), fn)
+		if err != nil {
+			return v, err
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(e)
+		return p, nil
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			fv, err := mapStringsErr(v.Field(i), fn)
+			if err != nil {
+				return v, err
+			}
+			out.Field(i).Set(fv)
+		}
+		return out, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ev, err := mapStringsErr(v.Index(i), fn)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(ev)
+		}
+		return out, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,173 @@
+package rcs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func jsonTestFile() *File {
+	f := NewFile()
+	f.Head = "1.2"
+	f.RevisionHeads = []*RevisionHead{
+		{Revision: "1.2", Date: "2021.03.04.05.06.07", NextRevision: "1.1"},
+		{Revision: "1.1", Date: "2021.03.03.05.06.07", Branches: []Num{"1.1.1.1"}},
+		{Revision: "1.1.1.1", Date: "2021.03.05.05.06.07"},
+	}
+	f.RevisionContents = []*RevisionContent{
+		{Revision: "1.2", Text: "a\nB\nc\n"},
+		{Revision: "1.1", Text: "d2 1\na2 1\nb\n"},
+		{Revision: "1.1.1.1", Text: "a3 2\nd\ne\n"},
+	}
+	return f
+}
+
+func TestToJSON(t *testing.T) {
+	f := jsonTestFile()
+
+	plain, err := f.ToJSON(JSONOptions{})
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	want, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(plain)); diff != "" {
+		t.Errorf("ToJSON() without options differs from json.Marshal (-want +got):\n%s", diff)
+	}
+
+	b, err := f.ToJSON(JSONOptions{Text: true, Deltas: true, Links: true, Stats: true})
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var doc struct {
+		Head      string
+		Revisions []*JSONRevision
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	text := func(s string) *string { return &s }
+	wantRevs := []*JSONRevision{
+		{
+			Revision: "1.2",
+			Text:     text("a\nB\nc\n"),
+			Parent:   "1.1",
+			Stats:    &RevisionStats{Lines: 3, Added: 1, Deleted: 1},
+		},
+		{
+			Revision:  "1.1",
+			Text:      text("a\nb\nc\n"),
+			DeltaBase: "1.2",
+			Delta: []DeltaCommand{
+				{Op: "d", Line: 2, Count: 1},
+				{Op: "a", Line: 2, Count: 1, Lines: []string{"b"}},
+			},
+			Children: []string{"1.2", "1.1.1.1"},
+			Stats:    &RevisionStats{Lines: 3, Added: 3},
+		},
+		{
+			Revision:  "1.1.1.1",
+			Text:      text("a\nb\nc\nd\ne\n"),
+			DeltaBase: "1.1",
+			Delta:     []DeltaCommand{{Op: "a", Line: 3, Count: 2, Lines: []string{"d", "e"}}},
+			Parent:    "1.1",
+			Stats:     &RevisionStats{Lines: 5, Added: 2},
+		},
+	}
+	if doc.Head != "1.2" {
+		t.Errorf("Head = %q, want 1.2", doc.Head)
+	}
+	if diff := cmp.Diff(wantRevs, doc.Revisions); diff != "" {
+		t.Errorf("Revisions mismatch (-want +got):\n%s", diff)
+	}
+
+	var back File
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatalf("unmarshal as File: %v", err)
+	}
+	if diff := cmp.Diff(f.String(), back.String()); diff != "" {
+		t.Errorf("derived output does not round trip (-want +got):\n%s", diff)
+	}
+}
+
+const nonUTF8TestMaster = "head\t1.2;\naccess;\nsymbols\n\tv\xe9r:1.2;\nlocks; strict;\ncomment\t@# @;\n\n\n" +
+	"1.2\ndate\t2021.03.03.05.06.08;\tauthor jos\xe9;\tstate Exp;\nbranches;\nnext\t1.1;\n\n" +
+	"1.1\ndate\t2021.03.03.05.06.07;\tauthor alice;\tstate Exp;\nbranches;\nnext\t;\n\n\n" +
+	"desc\n@caf\xe9\n@\n\n\n" +
+	"1.2\nlog\n@r\xe9sum\xe9 \x93quoted\x94\n@\ntext\n@\x00\x01\xff\xfe binary\r\nline\n@\n\n\n" +
+	"1.1\nlog\n@first\n@\ntext\n@d1 1\na1 1\n\xff\xfe\n@\n"
+
+func TestToJSON_StatsFinalNewline(t *testing.T) {
+	f := NewFile()
+	f.Head = "1.2"
+	f.RevisionHeads = []*RevisionHead{
+		{Revision: "1.2", Date: "2021.03.04.05.06.07", NextRevision: "1.1"},
+		{Revision: "1.1", Date: "2021.03.03.05.06.07"},
+	}
+	// 1.2 only drops the final newline of 1.1.
+	f.RevisionContents = []*RevisionContent{
+		{Revision: "1.2", Text: "a\nb"},
+		{Revision: "1.1", Text: "d2 1\na2 1\nb\n"},
+	}
+	b, err := f.ToJSON(JSONOptions{Stats: true})
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var doc struct{ Revisions []*JSONRevision }
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got, want := doc.Revisions[0].Stats, (&RevisionStats{Lines: 2, Added: 1, Deleted: 1}); !cmp.Equal(want, got) {
+		t.Errorf("1.2 Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestJSON_NonUTF8(t *testing.T) {
+	f, err := ParseFile(strings.NewReader(nonUTF8TestMaster))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if got := f.String(); got != nonUTF8TestMaster {
+		t.Errorf("String() differs:\n%q\nwant\n%q", got, nonUTF8TestMaster)
+	}
+	for _, opts := range []JSONOptions{{}, {Text: true, Deltas: true}} {
+		b, err := f.ToJSON(opts)
+		if err != nil {
+			t.Fatalf("ToJSON(%+v) error = %v", opts, err)
+		}
+		if !utf8.Valid(b) {
+			t.Errorf("ToJSON(%+v) is not valid UTF-8", opts)
+		}
+		if !strings.Contains(string(b), `"Author":"`+JSONBinaryPrefix) {
+			t.Errorf("ToJSON(%+v) author is not base64 encoded: %s", opts, b)
+		}
+		got := new(File)
+		if err := json.Unmarshal(b, got); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if s := got.String(); s != nonUTF8TestMaster {
+			t.Errorf("JSON round trip differs:\n%q\nwant\n%q", s, nonUTF8TestMaster)
+		}
+	}
+}
+
+func TestJSON_BinaryPrefixEscaped(t *testing.T) {
+	f := jsonTestFile()
+	f.RevisionContents[0].Log = JSONBinaryPrefix + "not base64"
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := new(File)
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.RevisionContents[0].Log != f.RevisionContents[0].Log {
+		t.Errorf("Log = %q, want %q", got.RevisionContents[0].Log, f.RevisionContents[0].Log)
+	}
+}
@@ -43,7 +43,7 @@ type RevisionHead struct {
 	Mergepoint   PhraseValues `json:",omitempty"` // CVS-NT
 	Filename     PhraseValues `json:",omitempty"` // CVS-NT
 	Username     PhraseValues `json:",omitempty"` // CVS-NT
-	NewPhrases   []*NewPhrase `json:",omitempty"`
+	NewPhrases   NewPhrases   `json:",omitempty"`
 }
 
 func (h *RevisionHead) String() string {
@@ -97,34 +97,27 @@ func (h *RevisionHead) StringWithNewLine(nl string) string {
 		fmt.Fprintf(&sb, "commitid\t%s;%s", h.CommitID, nl)
 	}
 
-	writePhrase := func(key string, values PhraseValues) {
-		if len(values) == 0 {
-			return
+	for _, phrase := range []struct {
+		key    string
+		values PhraseValues
+	}{
+		{"owner", h.Owner},
+		{"group", h.Group},
+		{"permissions", h.Permissions},
+		{"hardlinks", h.Hardlinks},
+		{"deltatype", h.Deltatype},
+		{"kopt", h.Kopt},
+		{"mergepoint", h.Mergepoint},
+		{"filename", h.Filename},
+		{"username", h.Username},
+	} {
+		if len(phrase.values) > 0 {
+			writePhrase(&sb, phrase.key, phrase.values, nl)
 		}
-		sb.WriteString(key)
-		sb.WriteString("\t")
-		for i, v := range values {
-			if i > 0 {
-				sb.WriteString(" ")
-			}
-			sb.WriteString(v.String())
-		}
-		sb.WriteString(";")
-		sb.WriteString(nl)
 	}
 
-	writePhrase("owner", h.Owner)
-	writePhrase("group", h.Group)
-	writePhrase("permissions", h.Permissions)
-	writePhrase("hardlinks", h.Hardlinks)
-	writePhrase("deltatype", h.Deltatype)
-	writePhrase("kopt", h.Kopt)
-	writePhrase("mergepoint", h.Mergepoint)
-	writePhrase("filename", h.Filename)
-	writePhrase("username", h.Username)
-
 	for _, phrase := range h.NewPhrases {
-		writePhrase(phrase.Key.String(), phrase.Value)
+		writePhrase(&sb, phrase.Key.String(), phrase.Value, nl)
 	}
 
 	return sb.String()
@@ -169,6 +162,7 @@ type File struct {
 	Strict           bool
 	Integrity        string
 	Expand           string
+	NewPhrases       NewPhrases `json:",omitempty"`
 	NewLine          string
 	RevisionHeads    []*RevisionHead
 	RevisionContents []*RevisionContent
@@ -184,6 +178,7 @@ type FileFormattingOptions struct {
 	HeadSeparatorSpaces      int    `json:",omitempty"`
 	BranchSeparatorSpaces    int    `json:",omitempty"`
 	AccessSeparatorSpaces    int    `json:",omitempty"`
+	AccessOnNewLines         bool   `json:",omitempty"`
 	SymbolsSeparatorSpaces   int    `json:",omitempty"`
 	SymbolsInline            bool   `json:",omitempty"`
 	SymbolsFirstSpaces       int    `json:",omitempty"`
@@ -298,13 +293,27 @@ func (f *File) SwitchLineEnding(nl string) {
 			np.Value = replaceSlice(np.Value)
 		}
 	}
+	for _, np := range f.NewPhrases {
+		np.Value = replaceSlice(np.Value)
+	}
 
+	binary := f.IsBinary()
 	for _, rc := range f.RevisionContents {
 		rc.Log = replace(rc.Log)
-		rc.Text = replace(rc.Text)
+		if !binary && !strings.ContainsRune(rc.Text, 0) {
+			rc.Text = replace(rc.Text)
+		}
 	}
 }
 
+// IsBinary reports whether the master stores binary revisions, that is its
+// keyword expansion mode is "b". The bytes of binary texts must not be
+// touched, so SwitchLineEnding leaves them, and any text holding a NUL
+// byte, alone.
+func (f *File) IsBinary() bool {
+	return f.Expand == "b"
+}
+
 func (f *File) String() string {
 	nl := f.NewLine
 	if nl == "" {
@@ -324,7 +333,16 @@ func (f *File) String() string {
 		fmt.Fprintf(&sb, "branch%s%s;%s", branchSep, f.Branch, nl)
 	}
 	if f.Access {
-		if len(f.AccessUsers) > 0 {
+		if len(f.AccessUsers) > 0 && f.AccessOnNewLines {
+			sb.WriteString("access")
+			for _, user := range f.AccessUsers {
+				sb.WriteString(nl)
+				sb.WriteString("\t")
+				sb.WriteString(user)
+			}
+			sb.WriteString(";")
+			sb.WriteString(nl)
+		} else if len(f.AccessUsers) > 0 {
 			sb.WriteString("access ")
 			sb.WriteString(strings.Join(f.AccessUsers, " "))
 			sb.WriteString(";")
@@ -415,6 +433,9 @@ func (f *File) String() string {
 		sb.WriteString(";")
 		sb.WriteString(nl)
 	}
+	for _, phrase := range f.NewPhrases {
+		writePhrase(&sb, phrase.Key.String(), phrase.Value, nl)
+	}
 	if f.RevisionStartLineOffset+2 > 0 {
 		sb.WriteString(strings.Repeat(nl, f.RevisionStartLineOffset+2))
 	}
@@ -21,6 +21,13 @@ type SymbolFormattingOptions struct {
 	BetweenItemWhitespace string
 }
 
+type AccessFormattingOptions struct {
+	SeparatorWhitespace string
+	// OnNewLines is set when each login is on a line of its own after a
+	// tab, the layout GNU RCS writes.
+	OnNewLines bool
+}
+
 type HeaderLocksFormattingOptions struct {
 	SeparatorWhitespace string
 }
@@ -35,12 +42,14 @@ type HeaderLocksParseResult struct {
 func ParseFile(r io.Reader) (*File, error) {
 	f := new(File)
 	s := NewScanner(r)
+	s.phrase = parsePhrase{section: "admin"}
 	if err := ParseHeader(s, f); err != nil {
-		return nil, fmt.Errorf("parsing %s: %w", s.pos, err)
+		return nil, s.parseError(err)
 	}
 	descConsumed := false
+	s.phrase = parsePhrase{section: "delta"}
 	if rhs, dc, offset, descOffset, err := parseRevisionHeadersWithOffset(s); err != nil {
-		return nil, fmt.Errorf("parsing %s: %w", s.pos, err)
+		return nil, s.parseError(err)
 	} else {
 		descConsumed = dc
 		f.RevisionHeads = rhs
@@ -56,8 +65,9 @@ func ParseFile(r io.Reader) (*File, error) {
 			}
 		}
 	}
+	s.phrase = parsePhrase{section: "desc"}
 	if desc, err := ParseDescription(s, descConsumed); err != nil {
-		return nil, fmt.Errorf("parsing %s: %w", s.pos, err)
+		return nil, s.parseError(err)
 	} else {
 		f.Description = desc
 		if len(f.RevisionHeads) == 0 && shouldPreserveEmptyMasterHeaderSpacing(f) {
@@ -67,8 +77,9 @@ func ParseFile(r io.Reader) (*File, error) {
 			f.DescriptionNewLineOffset = -1
 		}
 	}
+	s.phrase = parsePhrase{section: "deltatext"}
 	if rcs, offset, err := ParseRevisionContents(s); err != nil {
-		return nil, fmt.Errorf("parsing %s: %w", s.pos, err)
+		return nil, s.parseError(err)
 	} else {
 		f.RevisionContents = rcs
 		f.EndOfFileNewLineOffset = offset
@@ -136,6 +147,7 @@ func ParseMultiLineText(s *Scanner, havePropertyName bool, propertyName string,
 }
 
 func ParseHeader(s *Scanner, f *File) error {
+	s.phrase.field = "head"
 	if head, headWS, err := ParseOptionalTokenWithSpacing(s, ScanTokenNum, WithPropertyName("head"), WithLine(true)); err != nil {
 		return err
 	} else {
@@ -149,13 +161,25 @@ func ParseHeader(s *Scanner, f *File) error {
 		var nt string
 		if nextToken == "" {
 			if err := ScanStrings(s, "branch", "access", "symbols", "locks", "strict", "integrity", "comment", "expand", "\n\n", "\r\n\r\n", " ", "\t", "\n", "\r\n"); err != nil {
-				return err
+				if !IsNotFound(err) {
+					return err
+				}
+				np, npErr := parseHeaderNewPhrase(s)
+				if npErr != nil {
+					return fmt.Errorf("%w: %v", err, npErr)
+				}
+				if np == nil {
+					return err
+				}
+				f.NewPhrases = append(f.NewPhrases, np)
+				continue
 			}
 			nt = s.Text()
 		} else {
 			nt = nextToken
 			nextToken = ""
 		}
+		s.setField(nt)
 
 		switch nt {
 		case " ", "\t", "\n", "\r\n":
@@ -174,12 +198,13 @@ func ParseHeader(s *Scanner, f *File) error {
 			}
 		case "access":
 			f.Access = true
-			if users, ws, err := ParseHeaderAccessWithSpacing(s, true); err != nil {
+			if users, accessFmt, err := ParseHeaderAccessWithFormatting(s, true); err != nil {
 				return fmt.Errorf("token %#v: %w", nt, err)
 			} else {
 				f.AccessUsers = users
-				if len(users) == 0 && isSpacesOnly(ws) {
-					f.AccessSeparatorSpaces = len(ws)
+				f.AccessOnNewLines = accessFmt.OnNewLines
+				if len(users) == 0 && isSpacesOnly(accessFmt.SeparatorWhitespace) {
+					f.AccessSeparatorSpaces = len(accessFmt.SeparatorWhitespace)
 				}
 			}
 		case "symbols":
@@ -252,6 +277,21 @@ func ParseHeader(s *Scanner, f *File) error {
 	}
 }
 
+// parseHeaderNewPhrase reads an admin section newphrase: an id, its values
+// and the terminating ';'. It returns nil when no id follows.
+func parseHeaderNewPhrase(s *Scanner) (*NewPhrase, error) {
+	id, err := ScanTokenId(s)
+	if err != nil || id == "" {
+		return nil, nil
+	}
+	s.phrase.field = id
+	v, err := ParseNewPhraseValue(s)
+	if err != nil {
+		return nil, fmt.Errorf("parsing new phrase %q: %w", id, err)
+	}
+	return &NewPhrase{Key: ID(id), Value: v}, nil
+}
+
 func parseRevisionHeadersWithOffset(s *Scanner) ([]*RevisionHead, bool, int, int, error) {
 	var rhs []*RevisionHead
 	revisionStartLineOffset := 0
@@ -292,6 +332,7 @@ func parseRevisionHeaderWithOffset(s *Scanner) (*RevisionHead, bool, bool, int,
 		}
 		if rev != "" {
 			rh.Revision = Num(rev)
+			s.phrase.revision, s.phrase.field = rev, ""
 			break
 		}
 		skippedNewLines++
@@ -311,6 +352,7 @@ func parseRevisionHeaderWithOffset(s *Scanner) (*RevisionHead, bool, bool, int,
 						return rh, false, true, skippedNewLines, skippedNewLines - 1, nil
 					}
 
+					s.phrase.field = id
 					np, err := ParseNewPhraseValue(s)
 					if err != nil {
 						return nil, false, false, skippedNewLines, 0, fmt.Errorf("parsing new phrase %q: %w", id, err)
@@ -325,6 +367,7 @@ func parseRevisionHeaderWithOffset(s *Scanner) (*RevisionHead, bool, bool, int,
 		}
 
 		nt := s.Text()
+		s.setField(nt)
 		switch nt {
 		case "branches":
 			if err := ParseRevisionHeaderBranches(s, rh, true); err != nil {
@@ -478,6 +521,7 @@ func ParseRevisionContent(s *Scanner) (*RevisionContent, int, error) {
 		}
 		if rev != "" {
 			rh.Revision = rev
+			s.phrase.revision, s.phrase.field = rev, ""
 			rh.PrecedingNewLinesOffset = precedingNewLines - 2
 			break
 		}
@@ -494,6 +538,7 @@ func ParseRevisionContent(s *Scanner) (*RevisionContent, int, error) {
 			return nil, 0, err
 		}
 		nt := s.Text()
+		s.setField(nt)
 		switch nt {
 		case "log":
 			if s, err := ParseRevisionContentLog(s); err != nil {
@@ -584,29 +629,40 @@ func ParseHeaderAccess(s *Scanner, havePropertyName bool) ([]string, error) {
 }
 
 func ParseHeaderAccessWithSpacing(s *Scanner, havePropertyName bool) ([]string, string, error) {
+	ids, accessFmt, err := ParseHeaderAccessWithFormatting(s, havePropertyName)
+	return ids, accessFmt.SeparatorWhitespace, err
+}
+
+func ParseHeaderAccessWithFormatting(s *Scanner, havePropertyName bool) ([]string, AccessFormattingOptions, error) {
 	if !havePropertyName {
 		if err := ScanStrings(s, "access"); err != nil {
-			return nil, "", err
+			return nil, AccessFormattingOptions{}, err
 		}
 	}
 	var ids []string
-	var wsBeforeTerm string
+	fmtOpts := AccessFormattingOptions{OnNewLines: true}
 	for {
 		if err := ScanWhiteSpace(s, 0); err != nil {
-			return nil, "", err
+			return nil, AccessFormattingOptions{}, err
 		}
 		ws := s.Text()
 		if err := ScanStrings(s, ";"); err == nil {
-			wsBeforeTerm = ws
+			fmtOpts.SeparatorWhitespace = ws
 			break
 		}
+		if ws != "\n\t" && ws != "\r\n\t" {
+			fmtOpts.OnNewLines = false
+		}
 		id, err := ScanTokenId(s)
 		if err != nil {
-			return nil, "", fmt.Errorf("expected id in access: %w", err)
+			return nil, AccessFormattingOptions{}, fmt.Errorf("expected id in access: %w", err)
 		}
 		ids = append(ids, id)
 	}
-	return ids, wsBeforeTerm, nil
+	if len(ids) == 0 {
+		fmtOpts.OnNewLines = false
+	}
+	return ids, fmtOpts, nil
 }
 
 func ParseHeaderSymbols(s *Scanner, havePropertyName bool) ([]*Symbol, string, error) {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"io/fs"
 	"strings"
 	"testing"
@@ -2242,3 +2243,47 @@ newfield	value1 @value 2@;
 		t.Errorf("String() mismatch (-got +want):\n%s", diff)
 	}
 }
+
+func TestParseFile_ParseError(t *testing.T) {
+	input := "head\t1.1;\naccess;\nsymbols\tfoo:1.1 bar;\nlocks; strict;\n"
+	_, err := ParseFile(strings.NewReader(input))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ParseFile() error = %v, want *ParseError", err)
+	}
+	want := &ParseError{
+		Line:    3,
+		Column:  20,
+		Offset:  37,
+		Section: "admin",
+		Field:   "symbols",
+		Source:  "symbols\tfoo:1.1 bar;",
+	}
+	if diff := cmp.Diff(want, pe, cmpopts.IgnoreFields(ParseError{}, "Err")); diff != "" {
+		t.Errorf("ParseError mismatch (-want +got):\n%s", diff)
+	}
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = false, want the scan error to stay wrapped", err)
+	}
+	pe.Filename = "a,v"
+	diag := pe.Diagnostic()
+	wantDiag := "a,v:3:20: admin \"symbols\": " + pe.Err.Error() + "\n\tsymbols\tfoo:1.1 bar;\n\t       \t           ^\n"
+	if diag != wantDiag {
+		t.Errorf("Diagnostic() = %q, want %q", diag, wantDiag)
+	}
+}
+
+func TestParseFile_ParseErrorRevision(t *testing.T) {
+	input := "head\t1.1;\naccess;\nsymbols;\nlocks; strict;\ncomment\t@# @;\n\n\n1.1\ndate\t2021.03.03.05.06.07;\tauthor alice;\tstate Exp;\nbranches;\nnext\t;\n\n\ndesc\n@@\n\n\n1.1\nlog\n@x@\ntext\n@a\n"
+	_, err := ParseFile(strings.NewReader(input))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ParseFile() error = %v, want *ParseError", err)
+	}
+	if pe.Section != "deltatext" || pe.Revision != "1.1" || pe.Field != "text" {
+		t.Errorf("phrase = %q, want deltatext 1.1 \"text\"", pe.Phrase())
+	}
+	if pe.Line != 22 || pe.Source != "@a" {
+		t.Errorf("position = %d %q, want line 22 %q", pe.Line, pe.Source, "@a")
+	}
+}
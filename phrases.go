@@ -0,0 +1,195 @@
+package rcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NewPhrases are the newphrase extensions of the admin section or of a
+// delta, in file order. They carry keywords this package does not know,
+// such as those written by CVS-NT, OpenCVS or local tools.
+type NewPhrases []*NewPhrase
+
+// reservedPhraseKeys are the keywords a newphrase may not use because they
+// already mean something in the admin section or a delta.
+var reservedPhraseKeys = map[string]bool{
+	"head": true, "branch": true, "access": true, "symbols": true, "locks": true,
+	"strict": true, "integrity": true, "comment": true, "expand": true,
+	"date": true, "author": true, "state": true, "branches": true, "next": true,
+	"commitid": true, "desc": true, "log": true, "text": true,
+}
+
+// Get returns the values of the first phrase with the given key.
+func (p NewPhrases) Get(key string) (PhraseValues, bool) {
+	for _, np := range p {
+		if np.Key.String() == key {
+			return np.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Words returns the raw, unquoted words of the phrase with the given key.
+func (p NewPhrases) Words(key string) ([]string, bool) {
+	v, ok := p.Get(key)
+	if !ok {
+		return nil, false
+	}
+	words := make([]string, len(v))
+	for i, w := range v {
+		words[i] = w.Raw()
+	}
+	return words, true
+}
+
+// Set replaces the values of the phrase with the given key, appending a new
+// phrase when there is none.
+func (p *NewPhrases) Set(key string, values PhraseValues) error {
+	if err := validatePhraseKey(key); err != nil {
+		return err
+	}
+	for _, np := range *p {
+		if np.Key.String() == key {
+			np.Value = values
+			return nil
+		}
+	}
+	*p = append(*p, &NewPhrase{Key: ID(key), Value: values})
+	return nil
+}
+
+// SetWords is Set with each word written as an id when it is a valid one
+// and as an @-quoted string otherwise.
+func (p *NewPhrases) SetWords(key string, words ...string) error {
+	return p.Set(key, NewPhraseValues(words...))
+}
+
+// Delete removes every phrase with the given key and reports whether there
+// was one.
+func (p *NewPhrases) Delete(key string) bool {
+	kept := (*p)[:0]
+	for _, np := range *p {
+		if np.Key.String() != key {
+			kept = append(kept, np)
+		}
+	}
+	found := len(kept) != len(*p)
+	*p = kept
+	return found
+}
+
+func validatePhraseKey(key string) error {
+	if key == "" {
+		return ErrEmptyId
+	}
+	for _, r := range key {
+		if !isIdChar(r) && r != '.' {
+			return fmt.Errorf("invalid phrase key %q", key)
+		}
+	}
+	if reservedPhraseKeys[key] {
+		return fmt.Errorf("phrase key %q is a reserved keyword", key)
+	}
+	return nil
+}
+
+// NewPhraseValues builds phrase values from raw words, as ids where
+// possible and @-quoted strings otherwise.
+func NewPhraseValues(words ...string) PhraseValues {
+	v := make(PhraseValues, len(words))
+	for i, w := range words {
+		v[i] = QuotedString(w)
+	}
+	v.Format()
+	return v
+}
+
+// MarshalJSON writes the raw values, without RCS quoting. Values that are
+// not valid UTF-8 are base64 encoded, see JSONBinaryPrefix.
+func (p PhraseValues) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	words := make([]string, len(p))
+	for i, v := range p {
+		words[i] = encodeJSONString(v.Raw())
+	}
+	return json.Marshal(words)
+}
+
+// UnmarshalJSON reads values written by MarshalJSON. Words that are valid
+// ids become SimpleString and everything else QuotedString.
+func (p *PhraseValues) UnmarshalJSON(b []byte) error {
+	var words []string
+	if err := json.Unmarshal(b, &words); err != nil {
+		return err
+	}
+	if words == nil {
+		*p = nil
+		return nil
+	}
+	for i, w := range words {
+		w, err := decodeJSONString(w)
+		if err != nil {
+			return err
+		}
+		words[i] = w
+	}
+	*p = NewPhraseValues(words...)
+	return nil
+}
+
+// newPhraseJSON is the JSON form of a NewPhrase. Quoted holds the indexes
+// of the values written as @-quoted strings, so a phrase survives a round
+// trip byte for byte.
+type newPhraseJSON struct {
+	Key    ID
+	Value  PhraseValues
+	Quoted []int `json:",omitempty"`
+}
+
+// MarshalJSON writes the phrase with its raw values and the indexes of the
+// quoted ones.
+func (np NewPhrase) MarshalJSON() ([]byte, error) {
+	j := newPhraseJSON{Key: np.Key, Value: np.Value}
+	for i, v := range np.Value {
+		if _, ok := v.(QuotedString); ok {
+			j.Quoted = append(j.Quoted, i)
+		}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON reads a phrase written by MarshalJSON. Values listed in
+// Quoted are quoted even when they are valid ids.
+func (np *NewPhrase) UnmarshalJSON(b []byte) error {
+	var j newPhraseJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	for _, i := range j.Quoted {
+		if i < 0 || i >= len(j.Value) {
+			return fmt.Errorf("phrase %q: quoted index %d out of range", j.Key, i)
+		}
+		j.Value[i] = QuotedString(j.Value[i].Raw())
+	}
+	*np = NewPhrase{Key: j.Key, Value: j.Value}
+	return nil
+}
+
+// writePhrase writes a newphrase line: the key, its values and the
+// terminator.
+func writePhrase(sb *strings.Builder, key string, values PhraseValues, nl string) {
+	sb.WriteString(key)
+	for i, v := range values {
+		if i == 0 {
+			sb.WriteString("\t")
+		} else {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(v.String())
+	}
+	sb.WriteString(";")
+	sb.WriteString(nl)
+}
@@ -0,0 +1,145 @@
+package rcs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const headerPhrasesTestMaster = `head	1.1;
+access;
+symbols;
+locks; strict;
+comment	@# @;
+namespace	@my ns@ v2;
+flag;
+local.ext	x;
+
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+x-extra	@hi@;
+
+
+desc
+@@
+
+
+1.1
+log
+@first
+@
+text
+@a
+@
+`
+
+func TestHeaderNewPhrases(t *testing.T) {
+	f, err := ParseFile(strings.NewReader(headerPhrasesTestMaster))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	want := NewPhrases{
+		{Key: "namespace", Value: PhraseValues{QuotedString("my ns"), SimpleString("v2")}},
+		{Key: "flag"},
+		{Key: "local.ext", Value: PhraseValues{SimpleString("x")}},
+	}
+	if diff := cmp.Diff(want, f.NewPhrases); diff != "" {
+		t.Errorf("NewPhrases mismatch (-want +got):\n%s", diff)
+	}
+	if got := f.String(); got != headerPhrasesTestMaster {
+		t.Errorf("String() round trip mismatch:\n%s", cmp.Diff(headerPhrasesTestMaster, got))
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"NewPhrases":[{"Key":"namespace","Value":["my ns","v2"],"Quoted":[0]},{"Key":"flag","Value":null},{"Key":"local.ext","Value":["x"]}]`) {
+		t.Errorf("JSON missing header phrases: %s", b)
+	}
+	var back File
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got := back.String(); got != headerPhrasesTestMaster {
+		t.Errorf("JSON round trip mismatch:\n%s", cmp.Diff(headerPhrasesTestMaster, got))
+	}
+}
+
+func TestNewPhrasesAccessors(t *testing.T) {
+	var p NewPhrases
+	if err := p.SetWords("namespace", "my ns", "v2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetWords("namespace", "v3"); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := p.Get("namespace"); !ok || !cmp.Equal(v, PhraseValues{SimpleString("v3")}) {
+		t.Errorf("Get(namespace) = %v, %v", v, ok)
+	}
+	if err := p.SetWords("owner", "a b"); err != nil {
+		t.Fatal(err)
+	}
+	if words, ok := p.Words("owner"); !ok || !cmp.Equal(words, []string{"a b"}) {
+		t.Errorf("Words(owner) = %q, %v", words, ok)
+	}
+	if v, _ := p.Get("owner"); v[0].String() != "@a b@" {
+		t.Errorf("owner value = %s, want quoted", v[0])
+	}
+	for _, key := range []string{"", "comment", "a;b", "bad key"} {
+		if err := p.SetWords(key, "x"); err == nil {
+			t.Errorf("SetWords(%q) error = nil, want error", key)
+		}
+	}
+	if !p.Delete("namespace") || p.Delete("namespace") {
+		t.Error("Delete(namespace) should report true then false")
+	}
+	if len(p) != 1 || p[0].Key != "owner" {
+		t.Errorf("phrases after Delete = %v", p)
+	}
+}
+
+func TestPhraseValuesJSON(t *testing.T) {
+	v := PhraseValues{SimpleString("640"), QuotedString("stringize.m4"), QuotedString("a@b")}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["640","stringize.m4","a@b"]`; string(b) != want {
+		t.Errorf("Marshal = %s, want %s", b, want)
+	}
+	var got PhraseValues
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := PhraseValues{SimpleString("640"), SimpleString("stringize.m4"), QuotedString("a@b")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewPhraseJSON(t *testing.T) {
+	np := &NewPhrase{Key: "hardlinks", Value: PhraseValues{QuotedString("stringize.m4"), SimpleString("x"), QuotedString("a b")}}
+	b, err := json.Marshal(np)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Key":"hardlinks","Value":["stringize.m4","x","a b"],"Quoted":[0,2]}`; string(b) != want {
+		t.Errorf("Marshal = %s, want %s", b, want)
+	}
+	var got NewPhrase
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(np, &got); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+	if err := json.Unmarshal([]byte(`{"Key":"k","Value":["x"],"Quoted":[1]}`), &got); err == nil {
+		t.Error("Unmarshal with an out of range Quoted index: error = nil")
+	}
+}
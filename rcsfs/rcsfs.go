@@ -0,0 +1,360 @@
+// Package rcsfs presents a tree of RCS masters as a read-only fs.FS holding
+// the working files as they were at a symbol, branch, revision or date.
+//
+// A master foo.c,v, RCS/foo.c,v or Attic/foo.c,v appears as foo.c in its
+// directory. Files are parsed and checked out lazily, when they are first
+// opened, stated or listed. Files that do not carry the selected symbol,
+// have no revision at the selected date, or are dead at the selected
+// revision are absent.
+//
+//	fsys := rcsfs.New(os.DirFS("/cvsroot/project"), rcsfs.Selector{Revision: "REL_1_0"})
+//	http.Handle("/", http.FileServer(http.FS(fsys)))
+package rcsfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// Selector chooses the revision of every file exposed by an FS.
+type Selector struct {
+	// Revision is a symbol, branch number or revision number. Empty selects
+	// each file's default branch, or its head.
+	Revision string
+	// Date, when set, selects the latest revision on the line of development
+	// chosen by Revision that is not newer than Date.
+	Date time.Time
+}
+
+const masterSuffix = ",v"
+
+// masterDirs are the subdirectories whose masters appear in their parent, in
+// lookup order.
+var masterDirs = []string{"RCS", "", "Attic"}
+
+// FS is a read-only view of the working files of a tree of RCS masters.
+type FS struct {
+	root fs.FS
+	sel  Selector
+
+	mu    sync.Mutex
+	cache map[string]*entry
+}
+
+var (
+	_ fs.FS        = (*FS)(nil)
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+)
+
+// entry is a checked out master. present is false when the file does not
+// exist at the selector.
+type entry struct {
+	present bool
+	content []byte
+	info    fileInfo
+}
+
+// New returns the view of the masters in root selected by sel.
+func New(root fs.FS, sel Selector) *FS {
+	return &FS{root: root, sel: sel, cache: map[string]*entry{}}
+}
+
+// Open opens the working file or directory name.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	e, err := fsys.lookupFile(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if e != nil {
+		return &file{info: e.info, Reader: bytes.NewReader(e.content)}, nil
+	}
+	info, err := fsys.dirInfo(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	entries, err := fsys.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &dir{info: info, entries: entries}, nil
+}
+
+// Stat returns the file info of the working file or directory name.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	e, err := fsys.lookupFile(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	if e != nil {
+		return e.info, nil
+	}
+	info, err := fsys.dirInfo(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return info, nil
+}
+
+// ReadDir lists the working files and subdirectories of name, sorted by
+// name.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if _, err := fsys.dirInfo(name); err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries, err := fsys.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+// lookupFile returns the entry for the working file name, or nil when no
+// master for it exists at the selector.
+func (fsys *FS) lookupFile(name string) (*entry, error) {
+	if name == "." {
+		return nil, nil
+	}
+	if inMasterDir(name) {
+		return nil, nil
+	}
+	dirName, base := path.Split(name)
+	for _, sub := range masterDirs {
+		master := path.Join(dirName, sub, base+masterSuffix)
+		st, err := fs.Stat(fsys.root, master)
+		if err != nil || st.IsDir() {
+			continue
+		}
+		e, err := fsys.load(master, base, st)
+		if err != nil {
+			return nil, err
+		}
+		if e.present {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+// dirInfo returns the info of directory name, which must exist in root and
+// not be a master directory.
+func (fsys *FS) dirInfo(name string) (fs.FileInfo, error) {
+	if inMasterDir(name) {
+		return nil, fs.ErrNotExist
+	}
+	st, err := fs.Stat(fsys.root, name)
+	if err != nil {
+		return nil, fs.ErrNotExist
+	}
+	if !st.IsDir() {
+		return nil, fs.ErrNotExist
+	}
+	return fileInfo{name: path.Base(name), mode: fs.ModeDir | 0555}, nil
+}
+
+func (fsys *FS) readDir(name string) ([]fs.DirEntry, error) {
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for _, sub := range masterDirs {
+		dirName := path.Join(name, sub)
+		des, err := fs.ReadDir(fsys.root, dirName)
+		if err != nil {
+			if sub != "" && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, de := range des {
+			n := de.Name()
+			if de.IsDir() {
+				if sub == "" && !isMasterDir(n) && !seen[n] {
+					seen[n] = true
+					entries = append(entries, fs.FileInfoToDirEntry(fileInfo{name: n, mode: fs.ModeDir | 0555}))
+				}
+				continue
+			}
+			base, ok := strings.CutSuffix(n, masterSuffix)
+			if !ok || base == "" || seen[base] {
+				continue
+			}
+			e, err := fsys.lookupFile(path.Join(name, base))
+			if err != nil {
+				return nil, err
+			}
+			if e != nil {
+				seen[base] = true
+				entries = append(entries, fs.FileInfoToDirEntry(e.info))
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// load parses and checks out master once, caching the result.
+func (fsys *FS) load(master, base string, st fs.FileInfo) (*entry, error) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	if e, ok := fsys.cache[master]; ok {
+		return e, nil
+	}
+	e, err := fsys.checkout(master, base, st)
+	if err != nil {
+		return nil, err
+	}
+	fsys.cache[master] = e
+	return e, nil
+}
+
+func (fsys *FS) checkout(master, base string, st fs.FileInfo) (*entry, error) {
+	b, err := fs.ReadFile(fsys.root, master)
+	if err != nil {
+		return nil, err
+	}
+	f, err := rcs.ParseFile(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", master, err)
+	}
+	var rev string
+	if fsys.sel.Date.IsZero() {
+		rev, err = f.ResolveRevision(fsys.sel.Revision)
+	} else {
+		rev, err = f.ResolveRevisionAtDate(fsys.sel.Revision, fsys.sel.Date, nil)
+	}
+	if errors.Is(err, rcs.ErrSymbolNotFound) || errors.Is(err, rcs.ErrRevisionNotFound) {
+		return &entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", master, err)
+	}
+	var head *rcs.RevisionHead
+	for _, rh := range f.RevisionHeads {
+		if rh.Revision.String() == rev {
+			head = rh
+			break
+		}
+	}
+	if head == nil || head.State == "dead" {
+		return &entry{}, nil
+	}
+	verdict, err := f.Checkout("", rcs.WithRevision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", master, err)
+	}
+	modTime, err := head.Date.DateTime()
+	if err != nil {
+		return nil, fmt.Errorf("%s: revision %s: %w", master, rev, err)
+	}
+	mode := fs.FileMode(0444)
+	if st.Mode().Perm()&0111 != 0 {
+		mode |= 0111
+	}
+	return &entry{
+		present: true,
+		content: []byte(verdict.Content),
+		info: fileInfo{
+			name:     base,
+			size:     int64(len(verdict.Content)),
+			mode:     mode,
+			modTime:  modTime,
+			revision: rev,
+		},
+	}, nil
+}
+
+// inMasterDir reports whether name is or lies within a master directory,
+// which the view hides.
+func inMasterDir(name string) bool {
+	for _, elem := range strings.Split(name, "/") {
+		if isMasterDir(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMasterDir(name string) bool {
+	for _, d := range masterDirs {
+		if d != "" && d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fileInfo describes a working file or directory. Directories have no
+// history of their own, so their mod time is zero. Sys returns the selected
+// revision number for files.
+type fileInfo struct {
+	name     string
+	size     int64
+	mode     fs.FileMode
+	modTime  time.Time
+	revision string
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi fileInfo) Sys() any           { return fi.revision }
+
+// file is an open working file. It supports Seek and ReadAt so that
+// http.FileServer can serve ranges.
+type file struct {
+	info fileInfo
+	*bytes.Reader
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+// dir is an open directory.
+type dir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,157 @@
+package rcsfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const taggedMaster = `head	1.2;
+access;
+symbols
+	REL_1:1.1;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author alice;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@second
+@
+text
+@new
+@
+
+
+1.1
+log
+@first
+@
+text
+@d1 1
+a1 1
+old
+@
+`
+
+const untaggedMaster = `head	1.1;
+access;
+symbols;
+locks; strict;
+comment	@# @;
+
+
+1.1
+date	2021.03.05.00.00.00;	author bob;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.1
+log
+@added later
+@
+text
+@later
+@
+`
+
+func testRoot() fstest.MapFS {
+	return fstest.MapFS{
+		"a.txt,v":           {Data: []byte(taggedMaster)},
+		"RCS/b.txt,v":       {Data: []byte(taggedMaster), Mode: 0555},
+		"sub/RCS/c.txt,v":   {Data: []byte(untaggedMaster)},
+		"sub/notes.txt":     {Data: []byte("not a master")},
+		"sub/Attic/d.txt,v": {Data: []byte(taggedMaster)},
+	}
+}
+
+func readAll(t *testing.T, fsys fs.FS) map[string]string {
+	t.Helper()
+	got := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, p)
+		got[p] = string(b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestFS(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		sel  Selector
+		want map[string]string
+	}{
+		{"head", Selector{}, map[string]string{"a.txt": "new\n", "b.txt": "new\n", "sub/c.txt": "later\n", "sub/d.txt": "new\n"}},
+		{"tag", Selector{Revision: "REL_1"}, map[string]string{"a.txt": "old\n", "b.txt": "old\n", "sub/d.txt": "old\n"}},
+		{"date", Selector{Date: time.Date(2021, 3, 3, 12, 0, 0, 0, time.UTC)}, map[string]string{"a.txt": "old\n", "b.txt": "old\n", "sub/d.txt": "old\n"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := New(testRoot(), tt.sel)
+			if diff := cmp.Diff(tt.want, readAll(t, fsys)); diff != "" {
+				t.Errorf("contents mismatch (-want +got):\n%s", diff)
+			}
+			var names []string
+			for n := range tt.want {
+				names = append(names, n)
+			}
+			if err := fstest.TestFS(fsys, names...); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFSInfo(t *testing.T) {
+	fsys := New(testRoot(), Selector{Revision: "REL_1"})
+	fi, err := fsys.Stat("RCS/b.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(RCS/b.txt) error = %v, want fs.ErrNotExist", err)
+	}
+	fi, err = fsys.Stat("b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 3, 3, 5, 6, 7, 0, time.UTC); !fi.ModTime().Equal(want) {
+		t.Errorf("ModTime() = %v, want %v", fi.ModTime(), want)
+	}
+	if fi.Mode() != 0555 {
+		t.Errorf("Mode() = %v, want -r-xr-xr-x", fi.Mode())
+	}
+	if fi.Sys() != "1.1" {
+		t.Errorf("Sys() = %v, want 1.1", fi.Sys())
+	}
+	if _, err := fsys.Stat("sub/c.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(sub/c.txt) error = %v, want fs.ErrNotExist for untagged file", err)
+	}
+}
@@ -0,0 +1,629 @@
+// Package rcsweb serves a browsable web UI and JSON API over a tree of RCS
+// masters, in the spirit of ViewVC. It uses only net/http and html/template,
+// so a Server can be mounted in any mux or exercised with httptest.
+//
+// HTML pages:
+//
+//	/browse/<dir>            directory listing
+//	/log/<master>            revision log of a master
+//	/rlog/<master>           rlog output as plain text
+//	/rev/<master>?r=         a revision's metadata, log and content
+//	/diff/<master>?r1=&r2=   unified diff between two revisions
+//	/annotate/<master>?r=    each line with the revision that introduced it
+//	/raw/<master>?r=         download a revision
+//
+// JSON API:
+//
+//	/api/browse/<dir>
+//	/api/file/<master>       the master as produced by gorcs to-json; the
+//	                         text, deltas, links and stats parameters add the
+//	                         matching File.ToJSON options
+//	/api/rev/<master>?r=
+//	/api/diff/<master>?r1=&r2=
+//	/api/annotate/<master>?r=
+//
+// Revision parameters accept anything File.ResolveRevision does: revision
+// numbers, branch numbers and symbols. They default to the head.
+package rcsweb
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/arran4/golang-rcs/diff"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templates = template.Must(template.New("").ParseFS(templatesFS, "templates/*.html"))
+
+const masterSuffix = ",v"
+
+// DiffContext is the number of context lines shown around diff hunks.
+const DiffContext = 3
+
+// Server serves the masters found in a file system.
+type Server struct {
+	root fs.FS
+	mux  *http.ServeMux
+}
+
+// New returns a Server for the masters in root.
+func New(root fs.FS) *Server {
+	s := &Server{root: root, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /{$}", s.handleBrowse)
+	s.mux.HandleFunc("GET /browse/{path...}", s.handleBrowse)
+	s.mux.HandleFunc("GET /log/{path...}", s.handleLog)
+	s.mux.HandleFunc("GET /rlog/{path...}", s.handleRLog)
+	s.mux.HandleFunc("GET /rev/{path...}", s.handleRev)
+	s.mux.HandleFunc("GET /diff/{path...}", s.handleDiff)
+	s.mux.HandleFunc("GET /annotate/{path...}", s.handleAnnotate)
+	s.mux.HandleFunc("GET /raw/{path...}", s.handleRaw)
+	s.mux.HandleFunc("GET /api/browse/{path...}", s.handleAPIBrowse)
+	s.mux.HandleFunc("GET /api/file/{path...}", s.handleAPIFile)
+	s.mux.HandleFunc("GET /api/rev/{path...}", s.handleAPIRev)
+	s.mux.HandleFunc("GET /api/diff/{path...}", s.handleAPIDiff)
+	s.mux.HandleFunc("GET /api/annotate/{path...}", s.handleAPIAnnotate)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Entry is a row of a directory listing. Path is the directory or master
+// path relative to the served root.
+type Entry struct {
+	Name   string
+	Path   string
+	Dir    bool   `json:",omitempty"`
+	Head   string `json:",omitempty"`
+	Date   string `json:",omitempty"`
+	Author string `json:",omitempty"`
+	Log    string `json:",omitempty"`
+}
+
+// Revision describes one revision. Parent and Children link to the
+// neighbouring revisions on its lines of development.
+type Revision struct {
+	Revision string
+	Date     string
+	Author   string
+	State    string
+	Log      string
+	Tags     []string `json:",omitempty"`
+	Parent   string   `json:",omitempty"`
+	Children []string `json:",omitempty"`
+	Content  string   `json:",omitempty"`
+}
+
+// DiffResult is the JSON form of a diff.
+type DiffResult struct {
+	From    string
+	To      string
+	Unified string
+}
+
+type crumb struct {
+	Name string
+	URL  string
+}
+
+type page struct {
+	Title  string
+	Path   string
+	Crumbs []crumb
+}
+
+func newPage(p string, file bool) page {
+	pg := page{Title: p, Path: p}
+	if p == "" || p == "." {
+		pg.Title = "/"
+		return pg
+	}
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		u := "/browse/" + strings.Join(parts[:i+1], "/")
+		if file && i == len(parts)-1 {
+			u = "/log/" + p
+		}
+		pg.Crumbs = append(pg.Crumbs, crumb{Name: part, URL: u})
+	}
+	return pg
+}
+
+// httpError carries an HTTP status with an error.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+func (e *httpError) Unwrap() error { return e.err }
+
+func notFound(format string, a ...any) error {
+	return &httpError{status: http.StatusNotFound, err: fmt.Errorf(format, a...)}
+}
+
+func badRequest(format string, a ...any) error {
+	return &httpError{status: http.StatusBadRequest, err: fmt.Errorf(format, a...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var he *httpError
+	switch {
+	case errors.As(err, &he):
+		status = he.status
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, rcs.ErrRevisionNotFound), errors.Is(err, rcs.ErrSymbolNotFound):
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data any) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = buf.WriteTo(w)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+func dirPath(r *http.Request) (string, error) {
+	p := strings.TrimSuffix(r.PathValue("path"), "/")
+	if p == "" {
+		p = "."
+	}
+	if !fs.ValidPath(p) {
+		return "", badRequest("invalid path %q", p)
+	}
+	return p, nil
+}
+
+// open parses the master named by the request path.
+func (s *Server) open(r *http.Request) (string, *rcs.File, error) {
+	p := r.PathValue("path")
+	if !fs.ValidPath(p) || !strings.HasSuffix(p, masterSuffix) {
+		return "", nil, notFound("%s is not an RCS file", p)
+	}
+	b, err := fs.ReadFile(s.root, p)
+	if err != nil {
+		return "", nil, err
+	}
+	f, err := rcs.ParseFile(bytes.NewReader(b))
+	if err != nil {
+		return "", nil, fmt.Errorf("parse %s: %w", p, err)
+	}
+	return p, f, nil
+}
+
+// workingName strips the ,v suffix and an RCS or Attic directory from a
+// master path.
+func workingName(master string) string {
+	dir, base := path.Split(strings.TrimSuffix(master, masterSuffix))
+	d := path.Clean(dir)
+	if b := path.Base(d); b == "RCS" || b == "Attic" {
+		dir = path.Dir(d)
+		if dir == "." {
+			return base
+		}
+		return dir + "/" + base
+	}
+	return dir + base
+}
+
+// listDir lists the subdirectories of dir and the masters in it and in its
+// RCS and Attic subdirectories.
+func (s *Server) listDir(dir string) ([]Entry, error) {
+	des, err := fs.ReadDir(s.root, dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, de := range des {
+		p := path.Join(dir, de.Name())
+		if !de.IsDir() {
+			continue
+		}
+		if de.Name() == "RCS" || de.Name() == "Attic" {
+			sub, err := fs.ReadDir(s.root, p)
+			if err != nil {
+				return nil, err
+			}
+			for _, sde := range sub {
+				if !sde.IsDir() && strings.HasSuffix(sde.Name(), masterSuffix) {
+					entries = append(entries, s.fileEntry(path.Join(p, sde.Name())))
+				}
+			}
+			continue
+		}
+		entries = append(entries, Entry{Name: de.Name(), Path: p, Dir: true})
+	}
+	for _, de := range des {
+		if !de.IsDir() && strings.HasSuffix(de.Name(), masterSuffix) {
+			entries = append(entries, s.fileEntry(path.Join(dir, de.Name())))
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Dir != entries[j].Dir {
+			return entries[i].Dir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// fileEntry describes a master by its head revision. Masters that fail to
+// parse are still listed.
+func (s *Server) fileEntry(master string) Entry {
+	e := Entry{Name: path.Base(workingName(master)), Path: master}
+	b, err := fs.ReadFile(s.root, master)
+	if err != nil {
+		return e
+	}
+	f, err := rcs.ParseFile(bytes.NewReader(b))
+	if err != nil {
+		return e
+	}
+	e.Head = f.Head
+	for _, rh := range f.RevisionHeads {
+		if rh.Revision.String() == f.Head {
+			e.Date = formatDate(rh.Date)
+			e.Author = rh.Author.String()
+		}
+	}
+	if msg, err := f.GetLogMessage(f.Head); err == nil {
+		e.Log = firstLine(msg)
+	}
+	return e
+}
+
+func formatDate(d rcs.DateTime) string {
+	t, err := d.DateTime()
+	if err != nil {
+		return d.String()
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
+
+// revisions describes every revision of f in file order, without content.
+func revisions(f *rcs.File) []Revision {
+	parents := f.RevisionParents()
+	children := f.RevisionChildren()
+	tags := map[string][]string{}
+	for _, sym := range f.Symbols {
+		tags[sym.Revision] = append(tags[sym.Revision], sym.Name)
+	}
+	var revs []Revision
+	for _, rh := range f.RevisionHeads {
+		rev := rh.Revision.String()
+		msg, _ := f.GetLogMessage(rev)
+		revs = append(revs, Revision{
+			Revision: rev,
+			Date:     formatDate(rh.Date),
+			Author:   rh.Author.String(),
+			State:    rh.State.String(),
+			Log:      msg,
+			Tags:     tags[rev],
+			Parent:   parents[rev],
+			Children: children[rev],
+		})
+	}
+	return revs
+}
+
+// revision resolves the query parameter key and returns that revision with
+// its content.
+func revision(f *rcs.File, r *http.Request, key string) (Revision, error) {
+	rev, err := f.ResolveRevision(r.URL.Query().Get(key))
+	if err != nil {
+		return Revision{}, err
+	}
+	verdict, err := f.Checkout("", rcs.WithRevision(rev))
+	if err != nil {
+		return Revision{}, err
+	}
+	for _, rv := range revisions(f) {
+		if rv.Revision == rev {
+			rv.Content = verdict.Content
+			return rv, nil
+		}
+	}
+	return Revision{}, fmt.Errorf("%w: %s", rcs.ErrRevisionNotFound, rev)
+}
+
+// diffRevisions resolves r1 and r2 and diffs them. r2 defaults to the head
+// and r1 to the parent of r2.
+func diffRevisions(master string, f *rcs.File, r *http.Request) (DiffResult, error) {
+	to, err := f.ResolveRevision(r.URL.Query().Get("r2"))
+	if err != nil {
+		return DiffResult{}, err
+	}
+	from := r.URL.Query().Get("r1")
+	if from == "" {
+		from = f.RevisionParents()[to]
+		if from == "" {
+			return DiffResult{}, badRequest("revision %s has no parent to diff against", to)
+		}
+	}
+	if from, err = f.ResolveRevision(from); err != nil {
+		return DiffResult{}, err
+	}
+	a, err := f.Checkout("", rcs.WithRevision(from))
+	if err != nil {
+		return DiffResult{}, err
+	}
+	b, err := f.Checkout("", rcs.WithRevision(to))
+	if err != nil {
+		return DiffResult{}, err
+	}
+	name := workingName(master)
+	u, err := diff.UnifiedText(name+"\t"+from, name+"\t"+to, a.Content, b.Content, DiffContext)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	return DiffResult{From: from, To: to, Unified: u}, nil
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func (s *Server) handleBrowse(w http.ResponseWriter, r *http.Request) {
+	dir, err := dirPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	entries, err := s.listDir(dir)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.render(w, "dir.html", struct {
+		page
+		Entries []Entry
+	}{newPage(dir, false), entries})
+}
+
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	p, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.render(w, "log.html", struct {
+		page
+		File      *rcs.File
+		Revisions []Revision
+	}{newPage(p, true), f, revisions(f)})
+}
+
+func (s *Server) handleRLog(w http.ResponseWriter, r *http.Request) {
+	p, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := rcs.PrintRLog(&buf, f, p, workingName(p), nil); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = buf.WriteTo(w)
+}
+
+func (s *Server) handleRev(w http.ResponseWriter, r *http.Request) {
+	p, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rev, err := revision(f, r, "r")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	pg := newPage(p, true)
+	pg.Title = p + " " + rev.Revision
+	s.render(w, "rev.html", struct {
+		page
+		Revision Revision
+	}{pg, rev})
+}
+
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	p, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	d, err := diffRevisions(p, f, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var dl []diffLine
+	for _, l := range lines(d.Unified) {
+		class := ""
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			class = "hunk"
+		case strings.HasPrefix(l, "---"), strings.HasPrefix(l, "+++"):
+		case strings.HasPrefix(l, "-"):
+			class = "del"
+		case strings.HasPrefix(l, "+"):
+			class = "ins"
+		}
+		dl = append(dl, diffLine{Class: class, Text: l})
+	}
+	pg := newPage(p, true)
+	pg.Title = fmt.Sprintf("%s %s to %s", p, d.From, d.To)
+	s.render(w, "diff.html", struct {
+		page
+		From, To string
+		Lines    []diffLine
+	}{pg, d.From, d.To, dl})
+}
+
+func (s *Server) handleAnnotate(w http.ResponseWriter, r *http.Request) {
+	p, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rev, err := f.ResolveRevision(r.URL.Query().Get("r"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	al, err := f.Annotate(rev)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	pg := newPage(p, true)
+	pg.Title = p + " " + rev + " annotated"
+	s.render(w, "annotate.html", struct {
+		page
+		Revision string
+		Lines    []rcs.AnnotatedLine
+	}{pg, rev, al})
+}
+
+func (s *Server) handleRaw(w http.ResponseWriter, r *http.Request) {
+	p, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rev, err := revision(f, r, "r")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(workingName(p))}))
+	_, _ = w.Write([]byte(rev.Content))
+}
+
+func (s *Server) handleAPIBrowse(w http.ResponseWriter, r *http.Request) {
+	dir, err := dirPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	entries, err := s.listDir(dir)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, entries)
+}
+
+func (s *Server) handleAPIFile(w http.ResponseWriter, r *http.Request) {
+	_, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	q := r.URL.Query()
+	b, err := f.ToJSON(rcs.JSONOptions{
+		Indent: true,
+		Text:   q.Has("text"),
+		Deltas: q.Has("deltas"),
+		Links:  q.Has("links"),
+		Stats:  q.Has("stats"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+func (s *Server) handleAPIRev(w http.ResponseWriter, r *http.Request) {
+	_, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rev, err := revision(f, r, "r")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, rev)
+}
+
+func (s *Server) handleAPIDiff(w http.ResponseWriter, r *http.Request) {
+	p, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	d, err := diffRevisions(p, f, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, d)
+}
+
+func (s *Server) handleAPIAnnotate(w http.ResponseWriter, r *http.Request) {
+	_, f, err := s.open(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	al, err := f.Annotate(r.URL.Query().Get("r"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, al)
+}
@@ -0,0 +1,191 @@
+package rcsweb
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testMaster = `head	1.2;
+access;
+symbols
+	REL_1:1.1;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author bob;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@second
+@
+text
+@a
+B
+c
+@
+
+
+1.1
+log
+@first <b>
+@
+text
+@d2 1
+a2 1
+b
+@
+`
+
+func testServer() *httptest.Server {
+	return httptest.NewServer(New(fstest.MapFS{
+		"a.txt,v":         {Data: []byte(testMaster)},
+		"sub/RCS/b.txt,v": {Data: []byte(testMaster)},
+		"sub/readme":      {Data: []byte("not a master")},
+	}))
+}
+
+func get(t *testing.T, ts *httptest.Server, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := ts.Client().Get(ts.URL + url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(b)
+}
+
+func TestPages(t *testing.T) {
+	ts := testServer()
+	defer ts.Close()
+	for _, tt := range []struct {
+		url  string
+		want []string
+	}{
+		{"/", []string{`href="/browse/sub"`, `href="/log/a.txt,v"`, "second"}},
+		{"/browse/sub", []string{`href="/log/sub/RCS/b.txt,v"`, ">b.txt<"}},
+		{"/log/a.txt,v", []string{"REL_1", `href="/diff/a.txt,v?r1=1.1&amp;r2=1.2"`, "first &lt;b&gt;"}},
+		{"/rlog/a.txt,v", []string{"Working file: a.txt", "revision 1.1"}},
+		{"/rev/a.txt,v?r=REL_1", []string{"a\nb\nc\n", `href="/rev/a.txt,v?r=1.2"`}},
+		{"/diff/a.txt,v", []string{`<span class="del">-b</span>`, `<span class="ins">&#43;B</span>`}},
+		{"/annotate/a.txt,v", []string{`?r=1.2">1.2</a></td><td class="rev">bob`}},
+	} {
+		resp, body := get(t, ts, tt.url)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d: %s", tt.url, resp.StatusCode, body)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("GET %s missing %q in:\n%s", tt.url, want, body)
+			}
+		}
+	}
+}
+
+func TestRaw(t *testing.T) {
+	ts := testServer()
+	defer ts.Close()
+	resp, body := get(t, ts, "/raw/sub/RCS/b.txt,v?r=1.1")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+	if body != "a\nb\nc\n" {
+		t.Errorf("body = %q", body)
+	}
+	if got := resp.Header.Get("Content-Disposition"); got != "attachment; filename=b.txt" {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+}
+
+func TestAPI(t *testing.T) {
+	ts := testServer()
+	defer ts.Close()
+
+	_, body := get(t, ts, "/api/file/a.txt,v")
+	var f rcs.File
+	if err := json.Unmarshal([]byte(body), &f); err != nil {
+		t.Fatalf("unmarshal file: %v", err)
+	}
+	if f.Head != "1.2" || len(f.RevisionHeads) != 2 {
+		t.Errorf("file = head %q with %d revisions", f.Head, len(f.RevisionHeads))
+	}
+
+	_, body = get(t, ts, "/api/file/a.txt,v?text&links")
+	var doc rcs.JSONFile
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatalf("unmarshal file with options: %v", err)
+	}
+	if len(doc.Revisions) != 2 || doc.Revisions[1].Text == nil || *doc.Revisions[1].Text != "a\nb\nc\n" || doc.Revisions[0].Parent != "1.1" {
+		t.Errorf("file revisions = %+v", doc.Revisions)
+	}
+
+	_, body = get(t, ts, "/api/rev/a.txt,v?r=1.1")
+	var rev Revision
+	if err := json.Unmarshal([]byte(body), &rev); err != nil {
+		t.Fatalf("unmarshal revision: %v", err)
+	}
+	want := Revision{Revision: "1.1", Date: "2021-03-03 05:06:07", Author: "alice", State: "Exp", Log: "first <b>\n", Tags: []string{"REL_1"}, Children: []string{"1.2"}, Content: "a\nb\nc\n"}
+	if diff := cmp.Diff(want, rev); diff != "" {
+		t.Errorf("revision mismatch (-want +got):\n%s", diff)
+	}
+
+	_, body = get(t, ts, "/api/diff/a.txt,v?r1=REL_1")
+	var d DiffResult
+	if err := json.Unmarshal([]byte(body), &d); err != nil {
+		t.Fatalf("unmarshal diff: %v", err)
+	}
+	if d.From != "1.1" || d.To != "1.2" || !strings.Contains(d.Unified, "-b\n+B\n") {
+		t.Errorf("diff = %+v", d)
+	}
+
+	_, body = get(t, ts, "/api/browse/")
+	var entries []Entry
+	if err := json.Unmarshal([]byte(body), &entries); err != nil {
+		t.Fatalf("unmarshal entries: %v", err)
+	}
+	wantEntries := []Entry{
+		{Name: "sub", Path: "sub", Dir: true},
+		{Name: "a.txt", Path: "a.txt,v", Head: "1.2", Date: "2021-03-04 05:06:07", Author: "bob", Log: "second"},
+	}
+	if diff := cmp.Diff(wantEntries, entries); diff != "" {
+		t.Errorf("entries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	ts := testServer()
+	defer ts.Close()
+	for _, url := range []string{"/log/missing,v", "/log/sub/readme", "/rev/a.txt,v?r=9.9", "/rev/a.txt,v?r=NOPE", "/browse/nowhere"} {
+		if resp, body := get(t, ts, url); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want 404: %s", url, resp.StatusCode, body)
+		}
+	}
+}
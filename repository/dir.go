@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/arran4/golang-rcs/archivefs"
+)
+
+// DirStorage keeps masters in a directory on disk. Writers take a GNU RCS
+// style ",file," lock file next to the master, so they exclude GNU RCS and
+// other gorcs processes as well. Compressed masters and masters inside
+// archives, named as for archivefs.Open, can be read and listed but not
+// locked.
+type DirStorage struct {
+	root string
+
+	mu       sync.Mutex
+	archives map[string]*archivefs.FS
+}
+
+// Dir returns a DirStorage for the directory root. Names are relative to
+// root; an empty root takes names as they are, relative to the working
+// directory.
+func Dir(root string) *DirStorage {
+	return &DirStorage{root: root, archives: map[string]*archivefs.FS{}}
+}
+
+// OS returns a DirStorage that uses names as given, like the os package.
+func OS() *DirStorage {
+	return Dir("")
+}
+
+// Path returns the file path of the master name.
+func (d *DirStorage) Path(name string) string {
+	if d.root == "" {
+		return name
+	}
+	if archive, member, ok := archivefs.SplitPath(name); ok {
+		return filepath.Join(d.root, archive) + archivefs.Separator + member
+	}
+	return filepath.Join(d.root, name)
+}
+
+// archive returns the archive at path, opening it on first use.
+func (d *DirStorage) archive(path string) (*archivefs.FS, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if a, ok := d.archives[path]; ok {
+		return a, nil
+	}
+	a, err := archivefs.OpenArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	d.archives[path] = a
+	return a, nil
+}
+
+// Open opens the master name, decompressing it when it is compressed.
+// Members of archives that List walked are read through the open archive.
+func (d *DirStorage) Open(name string) (io.ReadCloser, error) {
+	p := d.Path(name)
+	if archive, member, ok := archivefs.SplitPath(p); ok {
+		d.mu.Lock()
+		a, open := d.archives[archive]
+		d.mu.Unlock()
+		if open {
+			f, err := a.Open(member)
+			if err != nil {
+				return nil, err
+			}
+			return archivefs.Decompress(f)
+		}
+	}
+	return archivefs.Open(p)
+}
+
+// Stat describes the master or directory name. An archive that List has
+// not opened is opened for the call and closed again.
+func (d *DirStorage) Stat(name string) (fs.FileInfo, error) {
+	p := d.Path(name)
+	if archive, member, ok := archivefs.SplitPath(p); ok {
+		if member == "" {
+			member = "."
+		}
+		d.mu.Lock()
+		a, open := d.archives[archive]
+		d.mu.Unlock()
+		if open {
+			return a.Stat(member)
+		}
+		a, err := archivefs.OpenArchive(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer a.Close()
+		return a.Stat(member)
+	}
+	return os.Stat(p)
+}
+
+// List returns the masters below dir in sorted order. dir may also be an
+// archive or a directory inside one (archive//dir); masters inside an
+// archive are named archive//member and listed in archive order, so a
+// compressed archive is read once when they are opened in turn.
+func (d *DirStorage) List(dir string) ([]string, error) {
+	name, member, inArchive := archivefs.SplitPath(dir)
+	if !inArchive && archivefs.IsArchive(dir) {
+		name, inArchive = dir, true
+	}
+	if inArchive {
+		a, err := d.archive(d.Path(name))
+		if err != nil {
+			return nil, err
+		}
+		var masters []string
+		for _, m := range a.Names() {
+			if (member == "" || strings.HasPrefix(m, member+"/")) && IsMasterName(m) {
+				masters = append(masters, name+archivefs.Separator+m)
+			}
+		}
+		if member != "" && len(masters) == 0 {
+			if _, err := a.Stat(member); err != nil {
+				return nil, err
+			}
+		}
+		return masters, nil
+	}
+	p := d.Path(dir)
+	var masters []string
+	err := filepath.WalkDir(p, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !e.IsDir() && IsMasterName(path) {
+			rel, err := filepath.Rel(p, path)
+			if err != nil {
+				return err
+			}
+			masters = append(masters, filepath.Join(dir, rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(masters)
+	return masters, nil
+}
+
+// Close closes the archives List opened. The storage stays usable; later
+// calls open them again.
+func (d *DirStorage) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var errs []error
+	for name, a := range d.archives {
+		errs = append(errs, a.Close())
+		delete(d.archives, name)
+	}
+	return errors.Join(errs...)
+}
+
+// LockFileName returns the GNU RCS lock file name for a master: RCS/foo.c,v
+// is locked by RCS/,foo.c,.
+func LockFileName(master string) string {
+	dir, base := filepath.Split(master)
+	return filepath.Join(dir, ","+strings.TrimSuffix(base, ",v")+",")
+}
+
+// Lock creates the lock file of name exclusively. It receives the new
+// contents and is renamed over the master on Commit.
+func (d *DirStorage) Lock(name string) (Lock, error) {
+	master := d.Path(name)
+	if _, _, ok := archivefs.SplitPath(master); ok || archivefs.TrimCompressionExt(master) != master {
+		return nil, fmt.Errorf("%s: %w", master, ErrReadOnly)
+	}
+	p := LockFileName(master)
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0444)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("%s: %w: lock file %s exists", master, ErrMasterInUse, p)
+		}
+		return nil, fmt.Errorf("lock %s: %w", master, err)
+	}
+	return &dirLock{master: master, path: p, f: f}, nil
+}
+
+type dirLock struct {
+	master string
+	path   string
+	f      *os.File
+	done   bool
+}
+
+func (l *dirLock) Commit(data []byte, perm fs.FileMode) error {
+	if l.done {
+		return fmt.Errorf("lock for %s already released", l.master)
+	}
+	if st, err := os.Stat(l.master); err == nil {
+		perm = st.Mode().Perm()
+	}
+	if _, err := l.f.Write(data); err != nil {
+		_ = l.Release()
+		return fmt.Errorf("write %s: %w", l.path, err)
+	}
+	if err := l.f.Sync(); err != nil {
+		_ = l.Release()
+		return fmt.Errorf("sync %s: %w", l.path, err)
+	}
+	if err := l.f.Chmod(perm); err != nil {
+		_ = l.Release()
+		return fmt.Errorf("chmod %s: %w", l.path, err)
+	}
+	if err := l.f.Close(); err != nil {
+		l.f = nil
+		_ = l.Release()
+		return fmt.Errorf("close %s: %w", l.path, err)
+	}
+	l.f = nil
+	if err := os.Rename(l.path, l.master); err != nil {
+		_ = l.Release()
+		return fmt.Errorf("rename %s to %s: %w", l.path, l.master, err)
+	}
+	l.done = true
+	return nil
+}
+
+func (l *dirLock) Release() error {
+	if l.done {
+		return nil
+	}
+	l.done = true
+	if l.f != nil {
+		_ = l.f.Close()
+		l.f = nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/arran4/golang-rcs/diff"
+)
+
+// DryRunStorage previews the writes made through it. Committed masters are
+// kept in memory, so later reads see them, and a unified diff of each one
+// against its previous contents is written to W. The underlying storage is
+// only read: no lock files are taken and no master is replaced.
+type DryRunStorage struct {
+	Base Storage
+	W    io.Writer
+
+	overlay *MemStorage
+}
+
+// DryRun returns a DryRunStorage over base that writes its previews to w.
+func DryRun(base Storage, w io.Writer) *DryRunStorage {
+	return &DryRunStorage{Base: base, W: w, overlay: Memory()}
+}
+
+// Open opens the previewed contents of name, or name in the base storage.
+func (d *DryRunStorage) Open(name string) (io.ReadCloser, error) {
+	if r, err := d.overlay.Open(name); err == nil {
+		return r, nil
+	}
+	return d.Base.Open(name)
+}
+
+// Stat describes the previewed master name, or name in the base storage.
+func (d *DryRunStorage) Stat(name string) (fs.FileInfo, error) {
+	if _, err := d.overlay.ReadFile(name); err == nil {
+		return d.overlay.Stat(name)
+	}
+	return d.Base.Stat(name)
+}
+
+// Close closes the base storage when it is an io.Closer.
+func (d *DryRunStorage) Close() error {
+	return closeStorage(d.Base)
+}
+
+// List returns the masters below dir in the base storage along with any
+// new masters previewed there.
+func (d *DryRunStorage) List(dir string) ([]string, error) {
+	masters, err := d.Base.List(dir)
+	if err != nil {
+		return nil, err
+	}
+	created, err := d.overlay.List(dir)
+	if err != nil {
+		return masters, nil
+	}
+	seen := map[string]bool{}
+	for _, m := range masters {
+		seen[memName(m)] = true
+	}
+	var added bool
+	for _, m := range created {
+		if !seen[m] {
+			masters = append(masters, m)
+			added = true
+		}
+	}
+	if added {
+		sort.Strings(masters)
+	}
+	return masters, nil
+}
+
+// Lock takes an in-memory lock on name. Committing it writes the preview.
+func (d *DryRunStorage) Lock(name string) (Lock, error) {
+	l, err := d.overlay.Lock(name)
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunLock{d: d, name: name, Lock: l}, nil
+}
+
+type dryRunLock struct {
+	Lock
+	d    *DryRunStorage
+	name string
+}
+
+func (l *dryRunLock) Commit(data []byte, perm fs.FileMode) error {
+	from, fromName := "", "/dev/null"
+	if r, err := l.d.Open(l.name); err == nil {
+		b, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			_ = l.Release()
+			return fmt.Errorf("read %s: %w", l.name, err)
+		}
+		from, fromName = string(b), l.name
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		_ = l.Release()
+		return fmt.Errorf("open %s: %w", l.name, err)
+	}
+	if st, err := l.d.Base.Stat(l.name); err == nil {
+		perm = st.Mode().Perm()
+	}
+	if err := l.preview(fromName, from, data); err != nil {
+		_ = l.Release()
+		return err
+	}
+	return l.Lock.Commit(data, perm)
+}
+
+// preview writes the unified diff from the master's contents to data.
+func (l *dryRunLock) preview(fromName, from string, data []byte) error {
+	if from == string(data) && fromName != "/dev/null" {
+		_, err := fmt.Fprintf(l.d.W, "%s: no changes\n", l.name)
+		return err
+	}
+	u, err := diff.UnifiedText(fromName, l.name+" (dry run)", from, string(data), 3)
+	if err != nil {
+		return fmt.Errorf("diff %s: %w", l.name, err)
+	}
+	_, err = io.WriteString(l.d.W, u)
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arran4/golang-rcs/archivefs"
+)
+
+// FSStorage reads masters from an fs.FS, such as an embed.FS or an
+// archivefs.FS. It cannot be written: Lock fails with ErrReadOnly.
+type FSStorage struct {
+	fsys fs.FS
+}
+
+// FS returns a read-only FSStorage over fsys.
+func FS(fsys fs.FS) *FSStorage {
+	return &FSStorage{fsys: fsys}
+}
+
+func fsName(name string) string {
+	n := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if n == "" {
+		return "."
+	}
+	return n
+}
+
+// Open opens name, decompressing it when it is compressed.
+func (s *FSStorage) Open(name string) (io.ReadCloser, error) {
+	f, err := s.fsys.Open(fsName(name))
+	if err != nil {
+		return nil, err
+	}
+	return archivefs.Decompress(f)
+}
+
+// Stat describes the file or directory name.
+func (s *FSStorage) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(s.fsys, fsName(name))
+}
+
+// List returns the masters below dir in sorted order.
+func (s *FSStorage) List(dir string) ([]string, error) {
+	var masters []string
+	err := fs.WalkDir(s.fsys, fsName(dir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && IsMasterName(p) {
+			masters = append(masters, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(masters)
+	return masters, nil
+}
+
+// Lock always fails with ErrReadOnly.
+func (s *FSStorage) Lock(name string) (Lock, error) {
+	return nil, fmt.Errorf("%s: %w", name, ErrReadOnly)
+}
@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMasterChanged is returned by Undo when a master was modified after
+	// the change being undone.
+	ErrMasterChanged = errors.New("RCS file changed since")
+	// ErrNothingToUndo is returned by Undo when the journal holds no change
+	// that can be undone.
+	ErrNothingToUndo = errors.New("nothing to undo")
+)
+
+// JournalEntry records one rewrite of a master. The masters rewritten by
+// one command share a Run.
+type JournalEntry struct {
+	ID      string    `json:"id"`
+	Run     string    `json:"run"`
+	Time    time.Time `json:"time"`
+	Master  string    `json:"master"`
+	Command []string  `json:"command"`
+	User    string    `json:"user"`
+	// Created is set when the master did not exist before, so there is no
+	// backup.
+	Created bool `json:"created,omitempty"`
+	// Before and After are the SHA-256 of the master before and after.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after"`
+	Undone bool   `json:"undone,omitempty"`
+}
+
+// DefaultJournalRuns is the number of runs a Journal keeps when MaxRuns is
+// not set.
+const DefaultJournalRuns = 100
+
+// Journal keeps backups of masters in a directory: the contents before each
+// rewrite in <id>.bak and the JournalEntry describing it in <id>.json.
+type Journal struct {
+	Dir string
+	// MaxRuns is the number of runs kept. The oldest runs are pruned when a
+	// new run records its first change. Zero or less means
+	// DefaultJournalRuns.
+	MaxRuns int
+}
+
+func hashOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func (j *Journal) path(id, ext string) string {
+	return filepath.Join(j.Dir, id+ext)
+}
+
+// Entries returns the journal, oldest first.
+func (j *Journal) Entries() ([]JournalEntry, error) {
+	names, err := filepath.Glob(filepath.Join(j.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]JournalEntry, 0, len(names))
+	for _, name := range names {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].ID < entries[b].ID
+	})
+	return entries, nil
+}
+
+// Backup returns the contents of the master before the change e.
+func (j *Journal) Backup(e JournalEntry) ([]byte, error) {
+	if e.Created {
+		return nil, fmt.Errorf("%s: created by %s, no backup", e.Master, e.ID)
+	}
+	return os.ReadFile(j.path(e.ID, ".bak"))
+}
+
+// record writes the backup and then the entry.
+func (j *Journal) record(e JournalEntry, before []byte) error {
+	if err := os.MkdirAll(j.Dir, 0700); err != nil {
+		return err
+	}
+	if !e.Created {
+		if err := os.WriteFile(j.path(e.ID, ".bak"), before, 0600); err != nil {
+			return err
+		}
+	}
+	return j.save(e)
+}
+
+// save writes e through a temporary file, so a crash leaves the old entry.
+func (j *Journal) save(e JournalEntry) error {
+	b, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := j.path(e.ID, ".tmp")
+	if err := os.WriteFile(tmp, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path(e.ID, ".json"))
+}
+
+func (j *Journal) remove(e JournalEntry) {
+	_ = os.Remove(j.path(e.ID, ".json"))
+	_ = os.Remove(j.path(e.ID, ".bak"))
+}
+
+// Prune removes the oldest runs until at most MaxRuns are left.
+func (j *Journal) Prune() error {
+	keep := j.MaxRuns
+	if keep <= 0 {
+		keep = DefaultJournalRuns
+	}
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+	var runs []string
+	seen := map[string]bool{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if r := entries[i].Run; !seen[r] {
+			seen[r] = true
+			runs = append(runs, r)
+		}
+	}
+	if len(runs) <= keep {
+		return nil
+	}
+	old := map[string]bool{}
+	for _, r := range runs[keep:] {
+		old[r] = true
+	}
+	for _, e := range entries {
+		if old[e.Run] {
+			j.remove(e)
+		}
+	}
+	return nil
+}
+
+// MarkUndone records that the entries were undone.
+func (j *Journal) MarkUndone(entries []JournalEntry) error {
+	for _, e := range entries {
+		e.Undone = true
+		if err := j.save(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Select returns the entries undo restores for id: the entry with that ID,
+// or the entries of the run with that ID that are not undone yet. An empty
+// id selects the most recent run with a change that is not undone.
+func (j *Journal) Select(id string) ([]JournalEntry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if !entries[i].Undone {
+				id = entries[i].Run
+				break
+			}
+		}
+		if id == "" {
+			return nil, ErrNothingToUndo
+		}
+	}
+	var selected []JournalEntry
+	for _, e := range entries {
+		switch {
+		case e.ID == id && e.Undone:
+			return nil, fmt.Errorf("%s: already undone: %w", id, ErrNothingToUndo)
+		case e.ID == id:
+			return []JournalEntry{e}, nil
+		case e.Run == id && !e.Undone:
+			selected = append(selected, e)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%s: %w", id, ErrNothingToUndo)
+	}
+	return selected, nil
+}
+
+// Undo puts the masters of entries in s back as they were before the
+// earliest entry for each. All masters are locked first, and nothing is
+// restored unless every master is still as the latest entry left it. When
+// restoring one master fails, the masters already restored are put back as
+// they were, so the undo happens for all of them or none. Masters the
+// entries created cannot be removed through a Storage, so they are refused.
+func (j *Journal) Undo(s Storage, entries []JournalEntry) error {
+	first := map[string]JournalEntry{}
+	last := map[string]JournalEntry{}
+	var masters []string
+	for _, e := range entries {
+		if _, ok := first[e.Master]; !ok {
+			first[e.Master] = e
+			masters = append(masters, e.Master)
+		}
+		last[e.Master] = e
+	}
+	for _, m := range masters {
+		if first[m].Created {
+			return fmt.Errorf("%s: created by %s; remove it to undo", m, first[m].ID)
+		}
+	}
+
+	locks := make([]Lock, 0, len(masters))
+	defer func() {
+		for _, l := range locks {
+			_ = l.Release()
+		}
+	}()
+	backups := make([][]byte, 0, len(masters))
+	current := make([][]byte, 0, len(masters))
+	for _, m := range masters {
+		l, err := s.Lock(m)
+		if err != nil {
+			return err
+		}
+		locks = append(locks, l)
+		r, err := s.Open(m)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", m, err)
+		}
+		b, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", m, err)
+		}
+		if hashOf(b) != last[m].After {
+			return fmt.Errorf("%s: %w %s", m, ErrMasterChanged, last[m].ID)
+		}
+		backup, err := j.Backup(first[m])
+		if err != nil {
+			return err
+		}
+		if hashOf(backup) != first[m].Before {
+			return fmt.Errorf("%s: backup %s is damaged", m, first[m].ID)
+		}
+		backups = append(backups, backup)
+		current = append(current, b)
+	}
+	for i, l := range locks {
+		if err := l.Commit(backups[i], 0644); err != nil {
+			return rollback(s, masters[:i], current[:i], err)
+		}
+	}
+	return nil
+}
+
+// rollback puts masters back to contents after err stopped an undo part
+// way. It returns err along with any master left restored.
+func rollback(s Storage, masters []string, contents [][]byte, err error) error {
+	errs := []error{err}
+	for i, m := range masters {
+		l, lerr := s.Lock(m)
+		if lerr == nil {
+			lerr = l.Commit(contents[i], 0644)
+		}
+		if lerr != nil {
+			errs = append(errs, fmt.Errorf("%s left restored: %w", m, lerr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// JournaledStorage records a backup of every master in a Journal before a
+// lock on it is committed. Reads go straight to Base.
+type JournaledStorage struct {
+	Base    Storage
+	Journal *Journal
+	Command []string
+	User    string
+
+	mu  sync.Mutex
+	run string
+	seq int
+}
+
+// Journaled returns a JournaledStorage over base. The masters written
+// through it form one run, recorded as made by user running command.
+func Journaled(base Storage, j *Journal, command []string, user string) *JournaledStorage {
+	return &JournaledStorage{
+		Base:    base,
+		Journal: j,
+		Command: command,
+		User:    user,
+		run:     time.Now().UTC().Format("20060102T150405.000000000Z"),
+	}
+}
+
+// Open opens name in Base.
+func (s *JournaledStorage) Open(name string) (io.ReadCloser, error) {
+	return s.Base.Open(name)
+}
+
+// Stat describes name in Base.
+func (s *JournaledStorage) Stat(name string) (fs.FileInfo, error) {
+	return s.Base.Stat(name)
+}
+
+// List lists dir in Base.
+func (s *JournaledStorage) List(dir string) ([]string, error) {
+	return s.Base.List(dir)
+}
+
+// Close closes Base when it is an io.Closer.
+func (s *JournaledStorage) Close() error {
+	return closeStorage(s.Base)
+}
+
+// Lock locks name in Base. Committing the lock records the backup first.
+func (s *JournaledStorage) Lock(name string) (Lock, error) {
+	l, err := s.Base.Lock(name)
+	if err != nil {
+		return nil, err
+	}
+	return &journaledLock{Lock: l, s: s, name: name}, nil
+}
+
+// master returns the name recorded for name. Names on disk relative to the
+// working directory are made absolute, so undo works from anywhere.
+func (s *JournaledStorage) master(name string) string {
+	if d, ok := s.Base.(*DirStorage); ok && d.root == "" {
+		if abs, err := filepath.Abs(name); err == nil {
+			return abs
+		}
+	}
+	return name
+}
+
+// nextID returns the ID of the next change of the run and whether it is the
+// first.
+func (s *JournaledStorage) nextID() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return fmt.Sprintf("%s-%04d", s.run, s.seq), s.seq == 1
+}
+
+type journaledLock struct {
+	Lock
+	s    *JournaledStorage
+	name string
+}
+
+func (l *journaledLock) Commit(data []byte, perm fs.FileMode) error {
+	id, first := l.s.nextID()
+	e := JournalEntry{
+		ID:      id,
+		Run:     l.s.run,
+		Time:    time.Now(),
+		Master:  l.s.master(l.name),
+		Command: l.s.Command,
+		User:    l.s.User,
+		After:   hashOf(data),
+	}
+	var before []byte
+	r, err := l.s.Base.Open(l.name)
+	switch {
+	case err == nil:
+		before, err = io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			_ = l.Release()
+			return fmt.Errorf("read %s: %w", l.name, err)
+		}
+		e.Before = hashOf(before)
+	case errors.Is(err, fs.ErrNotExist):
+		e.Created = true
+	default:
+		_ = l.Release()
+		return fmt.Errorf("open %s: %w", l.name, err)
+	}
+	if err := l.s.Journal.record(e, before); err != nil {
+		_ = l.Release()
+		return fmt.Errorf("journal %s: %w", l.name, err)
+	}
+	if first {
+		// Pruning is housekeeping; the backup is already safe.
+		_ = l.s.Journal.Prune()
+	}
+	if err := l.Lock.Commit(data, perm); err != nil {
+		l.s.Journal.remove(e)
+		return err
+	}
+	return nil
+}
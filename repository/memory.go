@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemStorage keeps masters in memory. Names are slash separated and cleaned,
+// so "./RCS/foo.c,v" and "RCS/foo.c,v" are the same master; directories
+// exist implicitly when a master lies below them.
+type MemStorage struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+	locks map[string]bool
+}
+
+type memFile struct {
+	data    []byte
+	perm    fs.FileMode
+	modTime time.Time
+}
+
+// Memory returns an empty MemStorage.
+func Memory() *MemStorage {
+	return &MemStorage{files: map[string]*memFile{}, locks: map[string]bool{}}
+}
+
+func memName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
+
+// WriteFile stores data as the file name without taking its lock. It is
+// meant for seeding a storage before use.
+func (m *MemStorage) WriteFile(name string, data []byte, perm fs.FileMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[memName(name)] = &memFile{data: bytes.Clone(data), perm: perm, modTime: time.Now()}
+}
+
+// ReadFile returns a copy of the file name.
+func (m *MemStorage) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[memName(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(f.data), nil
+}
+
+// Open returns a reader over the current contents of name.
+func (m *MemStorage) Open(name string) (io.ReadCloser, error) {
+	b, err := m.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+// Stat describes the file or implicit directory name.
+func (m *MemStorage) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := memName(name)
+	if f, ok := m.files[n]; ok {
+		return memInfo{name: path.Base(n), size: int64(len(f.data)), mode: f.perm, modTime: f.modTime}, nil
+	}
+	if n == "" {
+		return memInfo{name: ".", mode: fs.ModeDir | 0755}, nil
+	}
+	for k := range m.files {
+		if strings.HasPrefix(k, n+"/") {
+			return memInfo{name: path.Base(n), mode: fs.ModeDir | 0755}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// List returns the masters below dir in sorted order.
+func (m *MemStorage) List(dir string) ([]string, error) {
+	info, err := m.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := memName(dir)
+	var masters []string
+	for k := range m.files {
+		if (n == "" || strings.HasPrefix(k, n+"/")) && IsMasterName(k) {
+			masters = append(masters, k)
+		}
+	}
+	sort.Strings(masters)
+	return masters, nil
+}
+
+// Lock takes the writer lock of name.
+func (m *MemStorage) Lock(name string) (Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := memName(name)
+	if m.locks[n] {
+		return nil, fmt.Errorf("%s: %w", name, ErrMasterInUse)
+	}
+	m.locks[n] = true
+	return &memLock{m: m, name: n}, nil
+}
+
+type memLock struct {
+	m    *MemStorage
+	name string
+	done bool
+}
+
+func (l *memLock) Commit(data []byte, perm fs.FileMode) error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+	if l.done {
+		return fmt.Errorf("lock for %s already released", l.name)
+	}
+	if f, ok := l.m.files[l.name]; ok {
+		perm = f.perm
+	}
+	l.m.files[l.name] = &memFile{data: bytes.Clone(data), perm: perm, modTime: time.Now()}
+	l.done = true
+	delete(l.m.locks, l.name)
+	return nil
+}
+
+func (l *memLock) Release() error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+	if !l.done {
+		l.done = true
+		delete(l.m.locks, l.name)
+	}
+	return nil
+}
+
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() fs.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
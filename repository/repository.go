@@ -0,0 +1,150 @@
+// Package repository reads, locks and writes RCS masters through a
+// pluggable Storage, so commands that work on many masters can run against
+// a directory on disk, an in-memory set of files or any fs.FS.
+//
+//	repo := repository.New(repository.Memory())
+//	err := repo.Write("foo.c,v", []byte(master), 0444)
+//	f, err := repo.Parse("foo.c,v")
+//
+// Master names are paths in the storage's own form: file paths for Dir,
+// slash separated paths for Memory and FS.
+package repository
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/arran4/golang-rcs/archivefs"
+)
+
+var (
+	// ErrMasterInUse is returned when another writer holds the lock of an
+	// RCS master.
+	ErrMasterInUse = errors.New("RCS file is in use")
+	// ErrReadOnly is returned when locking a master the storage cannot
+	// write, such as one in an fs.FS or a compressed or archived master.
+	ErrReadOnly = errors.New("RCS file is read-only")
+)
+
+// Storage holds RCS masters.
+type Storage interface {
+	// Open opens the master name for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Stat describes the master or directory name.
+	Stat(name string) (fs.FileInfo, error)
+	// Lock takes the writer lock of the master name, which need not exist
+	// yet. It fails with ErrMasterInUse while another lock is held. Readers
+	// keep seeing the old master until the lock is committed.
+	Lock(name string) (Lock, error)
+	// List returns the masters in and below the directory dir.
+	List(dir string) ([]string, error)
+}
+
+// Lock is a held writer lock on a master.
+type Lock interface {
+	// Commit atomically replaces the master with data and releases the
+	// lock. An existing master keeps its permissions; perm is used for a
+	// new one.
+	Commit(data []byte, perm fs.FileMode) error
+	// Release drops the lock without touching the master. It is a no-op
+	// after Commit.
+	Release() error
+}
+
+// Repository works on the RCS masters in a Storage.
+type Repository struct {
+	Storage Storage
+}
+
+// New returns a Repository over s.
+func New(s Storage) *Repository {
+	return &Repository{Storage: s}
+}
+
+// Close releases what the storage holds open, such as the archives a
+// DirStorage listed. Storages that hold nothing open ignore it.
+func (r *Repository) Close() error {
+	return closeStorage(r.Storage)
+}
+
+func closeStorage(s Storage) error {
+	if c, ok := s.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Read returns the contents of the master name.
+func (r *Repository) Read(name string) ([]byte, error) {
+	f, err := r.Storage.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	return b, nil
+}
+
+// Parse reads and parses the master name.
+func (r *Repository) Parse(name string) (*rcs.File, error) {
+	f, err := r.Storage.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	parsed, err := rcs.ParseFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// Write atomically replaces the master name with data under its lock.
+func (r *Repository) Write(name string, data []byte, perm fs.FileMode) error {
+	l, err := r.Storage.Lock(name)
+	if err != nil {
+		return err
+	}
+	return l.Commit(data, perm)
+}
+
+// Update locks the master name, parses it, applies fn and writes the
+// result back. Nothing is written when fn returns an error.
+func (r *Repository) Update(name string, fn func(f *rcs.File) error) error {
+	l, err := r.Storage.Lock(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = l.Release()
+	}()
+	b, err := r.Read(name)
+	if err != nil {
+		return err
+	}
+	parsed, err := rcs.ParseFile(bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", name, err)
+	}
+	if err := fn(parsed); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return l.Commit([]byte(parsed.String()), 0644)
+}
+
+// IsMasterName reports whether name is a ",v" file, possibly compressed.
+func IsMasterName(name string) bool {
+	return strings.HasSuffix(archivefs.TrimCompressionExt(name), ",v")
+}
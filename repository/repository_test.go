@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testMaster = `head	1.1;
+access;
+symbols;
+locks; strict;
+comment	@# @;
+
+
+1.1
+date	2021.03.03.12.00.00;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.1
+log
+@Initial
+@
+text
+@hello
+@
+`
+
+func TestLockFileName(t *testing.T) {
+	got := LockFileName(filepath.Join("RCS", "foo.c,v"))
+	want := filepath.Join("RCS", ",foo.c,")
+	if got != want {
+		t.Errorf("LockFileName() = %q, want %q", got, want)
+	}
+}
+
+func TestStorages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"src/a.c,v", "src/lib/b.h,v", "README"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(testMaster), 0444); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mem := Memory()
+	mapFS := fstest.MapFS{}
+	for _, name := range []string{"src/a.c,v", "src/lib/b.h,v", "README"} {
+		mem.WriteFile(name, []byte(testMaster), 0444)
+		mapFS[name] = &fstest.MapFile{Data: []byte(testMaster), Mode: 0444}
+	}
+
+	tests := []struct {
+		name     string
+		storage  Storage
+		readOnly bool
+	}{
+		{"dir", Dir(dir), false},
+		{"memory", mem, false},
+		{"fs", FS(mapFS), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.storage)
+			masters, err := tt.storage.List("src")
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			want := []string{"src/a.c,v", "src/lib/b.h,v"}
+			if diff := cmp.Diff(want, masters); diff != "" {
+				t.Errorf("List() mismatch (-want +got):\n%s", diff)
+			}
+			if st, err := tt.storage.Stat("src/lib"); err != nil || !st.IsDir() {
+				t.Errorf("Stat(src/lib) = %v, %v, want a directory", st, err)
+			}
+			if _, err := tt.storage.Stat("missing,v"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat(missing) error = %v, want fs.ErrNotExist", err)
+			}
+
+			f, err := r.Parse("src/a.c,v")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if f.Head != "1.1" {
+				t.Errorf("Head = %q, want 1.1", f.Head)
+			}
+
+			err = r.Update("src/a.c,v", func(f *rcs.File) error {
+				f.Description = "updated\n"
+				return nil
+			})
+			if tt.readOnly {
+				if !errors.Is(err, ErrReadOnly) {
+					t.Errorf("Update() error = %v, want ErrReadOnly", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if f, err := r.Parse("src/a.c,v"); err != nil || f.Description != "updated\n" {
+				t.Errorf("Parse() after Update = %v, %v", f, err)
+			}
+			if st, err := tt.storage.Stat("src/a.c,v"); err != nil || st.Mode().Perm() != 0444 {
+				t.Errorf("Stat() after Update = %v, %v, want mode 0444", st, err)
+			}
+
+			l, err := tt.storage.Lock("src/a.c,v")
+			if err != nil {
+				t.Fatalf("Lock() error = %v", err)
+			}
+			if err := r.Write("src/a.c,v", []byte("other"), 0644); !errors.Is(err, ErrMasterInUse) {
+				t.Errorf("Write() while locked error = %v, want ErrMasterInUse", err)
+			}
+			if err := l.Release(); err != nil {
+				t.Fatalf("Release() error = %v", err)
+			}
+			if err := r.Write("new,v", []byte(testMaster), 0640); err != nil {
+				t.Fatalf("Write() new master error = %v", err)
+			}
+			if st, err := tt.storage.Stat("new,v"); err != nil || st.Mode().Perm() != 0640 {
+				t.Errorf("Stat() new master = %v, %v, want mode 0640", st, err)
+			}
+		})
+	}
+}
+
+func TestDirStorageClose(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("src/a.c,v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(testMaster)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "backup.zip"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := Dir(dir)
+	r := New(DryRun(d, io.Discard))
+	if _, err := d.Stat("backup.zip//src/a.c,v"); err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if len(d.archives) != 0 {
+		t.Errorf("Stat() left %d archives open, want 0", len(d.archives))
+	}
+	masters, err := r.Storage.List("backup.zip")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"backup.zip//src/a.c,v"}, masters); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+	if len(d.archives) != 1 {
+		t.Errorf("List() opened %d archives, want 1", len(d.archives))
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(d.archives) != 0 {
+		t.Errorf("Close() left %d archives open, want 0", len(d.archives))
+	}
+	if f, err := r.Parse(masters[0]); err != nil || f.Head != "1.1" {
+		t.Errorf("Parse() after Close = %v, %v", f, err)
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	mem := Memory()
+	mem.WriteFile("a,v", []byte(testMaster), 0444)
+	r := New(mem)
+	want := errors.New("boom")
+	if err := r.Update("a,v", func(f *rcs.File) error { return want }); !errors.Is(err, want) {
+		t.Fatalf("Update() error = %v, want %v", err, want)
+	}
+	b, err := mem.ReadFile("a,v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != testMaster {
+		t.Errorf("master changed after failed Update:\n%s", b)
+	}
+	if _, err := mem.Lock("a,v"); err != nil {
+		t.Errorf("Lock() after failed Update error = %v", err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	mem := Memory()
+	mem.WriteFile("a,v", []byte(testMaster), 0444)
+	var out bytes.Buffer
+	d := DryRun(mem, &out)
+	r := New(d)
+
+	if err := r.Update("a,v", func(f *rcs.File) error {
+		f.Description = "updated\n"
+		return nil
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := r.Write("b,v", []byte(testMaster), 0444); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if b, _ := mem.ReadFile("a,v"); string(b) != testMaster {
+		t.Errorf("base master changed:\n%s", b)
+	}
+	if _, err := mem.Stat("b,v"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("base Stat(b,v) error = %v, want fs.ErrNotExist", err)
+	}
+	if f, err := r.Parse("a,v"); err != nil || f.Description != "updated\n" {
+		t.Errorf("Parse() after dry run Update = %v, %v", f, err)
+	}
+	masters, err := d.List(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a,v", "b,v"}, masters); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+	for _, want := range []string{
+		"--- a,v\n+++ a,v (dry run)\n",
+		"-@@\n+@updated\n",
+		"--- /dev/null\n+++ b,v (dry run)\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("preview missing %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestJournal(t *testing.T) {
+	mem := Memory()
+	mem.WriteFile("a,v", []byte(testMaster), 0444)
+	mem.WriteFile("b,v", []byte(testMaster), 0444)
+	j := &Journal{Dir: t.TempDir()}
+	r := New(Journaled(mem, j, []string{"gorcs", "normalize-revisions"}, "alice"))
+	for _, name := range []string{"a,v", "b,v"} {
+		if err := r.Write(name, []byte("renumbered "+name), 0444); err != nil {
+			t.Fatalf("Write(%s) error = %v", name, err)
+		}
+	}
+
+	entries, err := j.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Run != entries[1].Run || entries[0].Master != "a,v" || entries[0].User != "alice" {
+		t.Fatalf("Entries() = %+v", entries)
+	}
+
+	// A master changed after the run keeps the whole run from being undone.
+	mem.WriteFile("b,v", []byte("edited"), 0444)
+	selected, err := j.Select("")
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if err := j.Undo(mem, selected); !errors.Is(err, ErrMasterChanged) {
+		t.Fatalf("Undo() error = %v, want ErrMasterChanged", err)
+	}
+	if b, _ := mem.ReadFile("a,v"); string(b) != "renumbered a,v" {
+		t.Errorf("a,v restored despite the refusal: %q", b)
+	}
+
+	mem.WriteFile("b,v", []byte("renumbered b,v"), 0444)
+	if err := j.Undo(mem, selected); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	for _, name := range []string{"a,v", "b,v"} {
+		if b, _ := mem.ReadFile(name); string(b) != testMaster {
+			t.Errorf("%s after Undo = %q", name, b)
+		}
+	}
+	if err := j.MarkUndone(selected); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Select(""); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Select() after undo error = %v, want ErrNothingToUndo", err)
+	}
+	if _, err := j.Select(entries[0].ID); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Select(undone ID) error = %v, want ErrNothingToUndo", err)
+	}
+}
+
+// failingStorage fails to commit the master named fail.
+type failingStorage struct {
+	*MemStorage
+	fail string
+}
+
+func (s failingStorage) Lock(name string) (Lock, error) {
+	l, err := s.MemStorage.Lock(name)
+	if err != nil || name != s.fail {
+		return l, err
+	}
+	return failingLock{l}, nil
+}
+
+type failingLock struct {
+	Lock
+}
+
+func (l failingLock) Commit(data []byte, perm fs.FileMode) error {
+	_ = l.Release()
+	return errors.New("disk full")
+}
+
+func TestJournalUndoRollback(t *testing.T) {
+	mem := Memory()
+	mem.WriteFile("a,v", []byte(testMaster), 0444)
+	mem.WriteFile("b,v", []byte(testMaster), 0444)
+	j := &Journal{Dir: t.TempDir()}
+	r := New(Journaled(mem, j, []string{"gorcs", "normalize-revisions"}, "alice"))
+	for _, name := range []string{"a,v", "b,v"} {
+		if err := r.Write(name, []byte("renumbered "+name), 0444); err != nil {
+			t.Fatalf("Write(%s) error = %v", name, err)
+		}
+	}
+	selected, err := j.Select("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Undo(failingStorage{mem, "b,v"}, selected); err == nil {
+		t.Fatal("Undo() error = nil, want the failed commit")
+	}
+	for _, name := range []string{"a,v", "b,v"} {
+		if b, _ := mem.ReadFile(name); string(b) != "renumbered "+name {
+			t.Errorf("%s after failed Undo = %q, want it left as it was", name, b)
+		}
+	}
+	if _, err := mem.Lock("a,v"); err != nil {
+		t.Errorf("Lock(a,v) after failed Undo error = %v", err)
+	}
+}
+
+func TestJournalPrune(t *testing.T) {
+	mem := Memory()
+	j := &Journal{Dir: t.TempDir(), MaxRuns: 2}
+	var runs []string
+	for i := 0; i < 3; i++ {
+		s := Journaled(mem, j, []string{"gorcs", "init"}, "alice")
+		for _, name := range []string{"a,v", "b,v"} {
+			if err := New(s).Write(name, []byte(testMaster), 0444); err != nil {
+				t.Fatalf("Write(%s) error = %v", name, err)
+			}
+		}
+		runs = append(runs, s.run)
+	}
+	entries, err := j.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Run)
+	}
+	want := []string{runs[1], runs[1], runs[2], runs[2]}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("runs after pruning mismatch (-want +got):\n%s", diff)
+	}
+}
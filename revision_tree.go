@@ -0,0 +1,222 @@
+package rcs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IsTrunkRevision reports whether rev is a trunk revision such as "1.4".
+func IsTrunkRevision(rev string) bool {
+	return strings.Count(rev, ".") == 1
+}
+
+// BranchNumber returns the branch a revision lives on. Trunk revisions
+// return the empty string, "1.2.1.3" returns "1.2.1".
+func BranchNumber(rev string) string {
+	if IsTrunkRevision(rev) {
+		return ""
+	}
+	i := strings.LastIndex(rev, ".")
+	if i < 0 {
+		return ""
+	}
+	return rev[:i]
+}
+
+// BranchPoint returns the revision a branch or branch revision sprouts from.
+// "1.2.1" and "1.2.1.3" both return "1.2". Trunk revisions return "".
+func BranchPoint(rev string) string {
+	parts := strings.Split(rev, ".")
+	switch {
+	case len(parts) <= 2:
+		return ""
+	case len(parts)%2 == 1:
+		return strings.Join(parts[:len(parts)-1], ".")
+	default:
+		return strings.Join(parts[:len(parts)-2], ".")
+	}
+}
+
+// NormalizeBranchSymbol converts a CVS magic branch number ("1.2.0.4") into
+// the RCS branch number it stands for ("1.2.4"). Other values are returned
+// unchanged.
+func NormalizeBranchSymbol(rev string) string {
+	parts := strings.Split(rev, ".")
+	if len(parts) >= 4 && len(parts)%2 == 0 && parts[len(parts)-2] == "0" {
+		return strings.Join(append(parts[:len(parts)-2:len(parts)-2], parts[len(parts)-1]), ".")
+	}
+	return rev
+}
+
+// RevisionParents maps every revision in the file to the revision it was
+// derived from. The initial trunk revision maps to "". The first revision of
+// a branch maps to its branch point.
+func (f *File) RevisionParents() map[string]string {
+	parents := make(map[string]string, len(f.RevisionHeads))
+	for _, rh := range f.RevisionHeads {
+		rev := rh.Revision.String()
+		if _, ok := parents[rev]; !ok {
+			parents[rev] = ""
+			if !IsTrunkRevision(rev) {
+				parents[rev] = BranchPoint(rev)
+			}
+		}
+		next := rh.NextRevision.String()
+		if next == "" {
+			continue
+		}
+		if IsTrunkRevision(rev) {
+			// Trunk deltas run backwards: the next revision is older.
+			parents[rev] = next
+			if _, ok := parents[next]; !ok {
+				parents[next] = ""
+			}
+		} else {
+			// Branch deltas run forwards: the next revision is newer.
+			parents[next] = rev
+		}
+	}
+	return parents
+}
+
+// RevisionChildren is the inverse of RevisionParents. Children are listed in
+// the order their heads appear in the file.
+func (f *File) RevisionChildren() map[string][]string {
+	parents := f.RevisionParents()
+	children := make(map[string][]string, len(parents))
+	for _, rh := range f.RevisionHeads {
+		rev := rh.Revision.String()
+		if p := parents[rev]; p != "" {
+			children[p] = append(children[p], rev)
+		}
+	}
+	return children
+}
+
+// BranchSymbols maps branch numbers to the symbolic names attached to them.
+// Both RCS ("1.2.1") and CVS magic ("1.2.0.1") branch symbols are recognised.
+func (f *File) BranchSymbols() map[string]string {
+	m := map[string]string{}
+	for _, s := range f.Symbols {
+		rev := NormalizeBranchSymbol(s.Revision)
+		if strings.Count(rev, ".")%2 == 0 {
+			if _, ok := m[rev]; !ok {
+				m[rev] = s.Name
+			}
+		}
+	}
+	return m
+}
+
+// ResolveRevision turns a selector into a revision number. The selector may
+// be a revision number, a branch number, or a symbol naming either; branches
+// resolve to their latest revision, or to their branch point when they have
+// none yet. An empty selector means the default branch, or the head when
+// there is none.
+func (f *File) ResolveRevision(sel string) (string, error) {
+	if sel == "" {
+		if f.Branch == "" {
+			if f.Head == "" {
+				return "", fmt.Errorf("%w: file has no revisions", ErrRevisionNotFound)
+			}
+			return f.Head, nil
+		}
+		sel = f.Branch
+	}
+	rev := sel
+	if !isRevisionNumber(sel) {
+		found := false
+		for _, s := range f.Symbols {
+			if s.Name == sel {
+				rev, found = NormalizeBranchSymbol(s.Revision), true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("%w: %s", ErrSymbolNotFound, sel)
+		}
+	}
+	if strings.Count(rev, ".")%2 == 0 {
+		return f.branchTip(rev)
+	}
+	for _, rh := range f.RevisionHeads {
+		if rh.Revision.String() == rev {
+			return rev, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrRevisionNotFound, sel)
+}
+
+// ResolveRevisionAtDate returns the revision with the latest date not newer
+// than date on the line of development ending at ResolveRevision(sel). The
+// line includes the branch point and the trunk revisions before it. Of two
+// revisions with the same date the higher one wins. Revision dates are read
+// in zone, or UTC, as RCS writes them, when zone is nil.
+func (f *File) ResolveRevisionAtDate(sel string, date time.Time, zone *time.Location) (string, error) {
+	rev, err := f.ResolveRevision(sel)
+	if err != nil {
+		return "", err
+	}
+	dates := make(map[string]DateTime, len(f.RevisionHeads))
+	for _, rh := range f.RevisionHeads {
+		dates[rh.Revision.String()] = rh.Date
+	}
+	parents := f.RevisionParents()
+	seen := map[string]bool{}
+	var best string
+	var bestTime time.Time
+	for ; rev != "" && !seen[rev]; rev = parents[rev] {
+		seen[rev] = true
+		t, err := ParseDate(string(dates[rev]), time.Time{}, zone)
+		if err != nil {
+			return "", fmt.Errorf("invalid date in revision %q: %w", rev, err)
+		}
+		if t.After(date) {
+			continue
+		}
+		if best == "" || t.After(bestTime) || (t.Equal(bestTime) && compareRevisions(rev, best) > 0) {
+			best, bestTime = rev, t
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("%w: no revision of %q on or before %v", ErrRevisionNotFound, sel, date)
+	}
+	return best, nil
+}
+
+// branchTip returns the latest revision on branch.
+func (f *File) branchTip(branch string) (string, error) {
+	tip := ""
+	for _, rh := range f.RevisionHeads {
+		rev := rh.Revision.String()
+		var on bool
+		if strings.Contains(branch, ".") {
+			on = BranchNumber(rev) == branch
+		} else {
+			on = IsTrunkRevision(rev) && strings.HasPrefix(rev, branch+".")
+		}
+		if on && (tip == "" || compareRevisions(rev, tip) > 0) {
+			tip = rev
+		}
+	}
+	if tip != "" {
+		return tip, nil
+	}
+	if bp := BranchPoint(branch); bp != "" {
+		return f.ResolveRevision(bp)
+	}
+	return "", fmt.Errorf("%w: branch %s has no revisions", ErrRevisionNotFound, branch)
+}
+
+func isRevisionNumber(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' || strings.Contains(s, "..") {
+		return false
+	}
+	for _, r := range s {
+		if !isDigit(r) && r != '.' {
+			return false
+		}
+	}
+	return true
+}
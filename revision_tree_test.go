@@ -0,0 +1,93 @@
+package rcs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func revisionTreeTestFile() *File {
+	f := NewFile()
+	f.Head = "1.3"
+	f.Symbols = []*Symbol{
+		{Name: "REL", Revision: "1.2"},
+		{Name: "BR", Revision: "1.2.0.2"},
+		{Name: "EMPTY", Revision: "1.3.0.4"},
+	}
+	f.RevisionHeads = []*RevisionHead{
+		{Revision: "1.3", Date: "2020.01.03.00.00.00", NextRevision: "1.2"},
+		{Revision: "1.2", Date: "2020.01.02.00.00.00", NextRevision: "1.1", Branches: []Num{"1.2.2.1"}},
+		{Revision: "1.1", Date: "2020.01.01.00.00.00"},
+		{Revision: "1.2.2.1", Date: "2020.01.04.00.00.00", NextRevision: "1.2.2.2"},
+		{Revision: "1.2.2.2", Date: "2020.01.06.00.00.00"},
+	}
+	return f
+}
+
+func TestResolveRevision(t *testing.T) {
+	f := revisionTreeTestFile()
+	for _, tt := range []struct {
+		sel  string
+		want string
+	}{
+		{"", "1.3"},
+		{"1.2", "1.2"},
+		{"1", "1.3"},
+		{"REL", "1.2"},
+		{"BR", "1.2.2.2"},
+		{"1.2.2", "1.2.2.2"},
+		{"EMPTY", "1.3"},
+	} {
+		got, err := f.ResolveRevision(tt.sel)
+		if err != nil {
+			t.Errorf("ResolveRevision(%q) error = %v", tt.sel, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveRevision(%q) = %q, want %q", tt.sel, got, tt.want)
+		}
+	}
+	if _, err := f.ResolveRevision("NOPE"); !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("ResolveRevision(NOPE) error = %v, want ErrSymbolNotFound", err)
+	}
+	if _, err := f.ResolveRevision("1.9"); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("ResolveRevision(1.9) error = %v, want ErrRevisionNotFound", err)
+	}
+	f.Branch = "1.2.2"
+	if got, err := f.ResolveRevision(""); err != nil || got != "1.2.2.2" {
+		t.Errorf("ResolveRevision(\"\") with default branch = %q, %v; want 1.2.2.2", got, err)
+	}
+}
+
+func TestResolveRevisionAtDate(t *testing.T) {
+	f := revisionTreeTestFile()
+	for _, tt := range []struct {
+		sel  string
+		date time.Time
+		want string
+	}{
+		{"", time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC), "1.2"},
+		{"1.2", time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), "1.1"},
+		{"REL", time.Date(2020, 1, 3, 12, 0, 0, 0, time.UTC), "1.2"},
+		{"BR", time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC), "1.2.2.1"},
+		{"BR", time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), "1.2"},
+		{"BR", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), "1.1"},
+	} {
+		got, err := f.ResolveRevisionAtDate(tt.sel, tt.date, nil)
+		if err != nil {
+			t.Errorf("ResolveRevisionAtDate(%q, %v) error = %v", tt.sel, tt.date, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveRevisionAtDate(%q, %v) = %q, want %q", tt.sel, tt.date, got, tt.want)
+		}
+	}
+	if _, err := f.ResolveRevisionAtDate("", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), nil); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("ResolveRevisionAtDate() before first revision error = %v, want ErrRevisionNotFound", err)
+	}
+	// Read as +10:00, 1.3 was made at 14:00 UTC on 2 January.
+	zone := time.FixedZone("", 10*60*60)
+	if got, err := f.ResolveRevisionAtDate("", time.Date(2020, 1, 2, 15, 0, 0, 0, time.UTC), zone); err != nil || got != "1.3" {
+		t.Errorf("ResolveRevisionAtDate() in +10:00 = %q, %v; want 1.3", got, err)
+	}
+}
@@ -99,6 +99,17 @@ func PrintRLog(w io.Writer, f *File, filename string, workingFilename string, fi
 }
 
 func getLinesStats(f *File, rh *RevisionHead) (string, error) {
+	added, removed, ok := f.RevisionLineStats(rh)
+	if !ok {
+		return "", nil
+	}
+	return fmt.Sprintf("  lines: +%d -%d", added, removed), nil
+}
+
+// RevisionLineStats counts the lines a revision added and removed relative
+// to its predecessor, from the stored delta as rlog's "lines:" field does.
+// ok is false when there is no delta to count, as for the initial revision.
+func (f *File) RevisionLineStats(rh *RevisionHead) (added, removed int, ok bool) {
 	// Standard RCS logic: if revision has odd number of dots (even number of fields), it's trunk or branch tip.
 	parts := strings.Split(string(rh.Revision), ".")
 	isTrunk := len(parts) == 2
@@ -110,7 +121,7 @@ func getLinesStats(f *File, rh *RevisionHead) (string, error) {
 		// Reverse delta: stored in NextRevision
 		nextRev := string(rh.NextRevision)
 		if nextRev == "" {
-			return "", nil // No next revision (e.g. 1.1), so no delta to compare against
+			return 0, 0, false // No next revision (e.g. 1.1), so no delta to compare against
 		}
 		// Find next revision content
 		found := false
@@ -122,7 +133,7 @@ func getLinesStats(f *File, rh *RevisionHead) (string, error) {
 			}
 		}
 		if !found {
-			return "", nil
+			return 0, 0, false
 		}
 		isReverse = true
 	} else {
@@ -137,7 +148,7 @@ func getLinesStats(f *File, rh *RevisionHead) (string, error) {
 			}
 		}
 		if !found {
-			return "", nil
+			return 0, 0, false
 		}
 		isReverse = false
 	}
@@ -150,13 +161,12 @@ func getLinesStats(f *File, rh *RevisionHead) (string, error) {
 		// So they are "added" in current relative to next. -> +dCount
 		// a N M means add M lines at N. These lines are in next but not in current.
 		// So they are "removed" in current relative to next. -> -aCount
-		return fmt.Sprintf("  lines: +%d -%d", dCount, aCount), nil
-	} else {
-		// Forward delta:
-		// d N M means delete M lines. Removed from previous. -> -dCount
-		// a N M means add M lines. Added to previous. -> +aCount
-		return fmt.Sprintf("  lines: +%d -%d", aCount, dCount), nil
+		return dCount, aCount, true
 	}
+	// Forward delta:
+	// d N M means delete M lines. Removed from previous. -> -dCount
+	// a N M means add M lines. Added to previous. -> +aCount
+	return aCount, dCount, true
 }
 
 func parseDeltaStats(delta string) (dCount, aCount int) {
@@ -18,8 +18,24 @@ type Scanner struct {
 	matchSplitFunc bufio.SplitFunc
 	matchTarget    []string
 	matchError     error
+	offset         int64
+	line           []byte
+	rest           []byte
+	phrase         parsePhrase
 }
 
+// parsePhrase records what the parser is reading so a ParseError can say
+// which part of the file failed.
+type parsePhrase struct {
+	section  string
+	revision string
+	field    string
+}
+
+// maxSourceLine caps how much of the current line the scanner keeps for
+// ParseError.Source.
+const maxSourceLine = 512
+
 type scannerInterface interface {
 	Err() error
 	Bytes() []byte
@@ -39,9 +55,67 @@ func (s *Scanner) Split(split bufio.SplitFunc) {
 func (s *Scanner) scannerWrapper(data []byte, eof bool) (advance int, token []byte, err error) {
 	a, t, err := s.sf(data, eof)
 	scanFound(t, a, s.pos)
+	s.track(data, a)
 	return a, t, err
 }
 
+// track follows the byte offset and the text of the current line as input
+// is consumed, so parse errors can point into the source.
+func (s *Scanner) track(data []byte, advance int) {
+	if advance < 0 || advance > len(data) {
+		return
+	}
+	s.rest = data[advance:]
+	if advance == 0 {
+		return
+	}
+	consumed := data[:advance]
+	s.offset += int64(advance)
+	if nl := bytes.LastIndexByte(consumed, '\n'); nl >= 0 {
+		consumed = consumed[nl+1:]
+		s.line = s.line[:0]
+	}
+	if room := maxSourceLine - len(s.line); room > 0 {
+		s.line = append(s.line, consumed[:min(room, len(consumed))]...)
+	}
+}
+
+// sourceLine returns the line the scanner is on, as far as it has been
+// read, without its line ending.
+func (s *Scanner) sourceLine() string {
+	line := append([]byte(nil), s.line...)
+	rest := s.rest
+	if nl := bytes.IndexByte(rest, '\n'); nl >= 0 {
+		rest = rest[:nl]
+	}
+	if room := maxSourceLine - len(line); room > 0 {
+		line = append(line, rest[:min(room, len(rest))]...)
+	}
+	return strings.TrimSuffix(string(line), "\r")
+}
+
+// parseError wraps err with the scanner's position and the phrase being
+// parsed.
+func (s *Scanner) parseError(err error) *ParseError {
+	return &ParseError{
+		Line:     s.pos.Line,
+		Column:   s.pos.Offset + 1,
+		Offset:   s.offset,
+		Section:  s.phrase.section,
+		Revision: s.phrase.revision,
+		Field:    s.phrase.field,
+		Source:   s.sourceLine(),
+		Err:      err,
+	}
+}
+
+// setField records the keyword being parsed, ignoring whitespace tokens.
+func (s *Scanner) setField(token string) {
+	if strings.TrimSpace(token) != "" {
+		s.phrase.field = token
+	}
+}
+
 func (s *Scanner) Scan() bool {
 	s.lastScan = s.Scanner.Scan()
 	return s.lastScan
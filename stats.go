@@ -0,0 +1,114 @@
+package rcs
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultStatsTop is the number of most churned files BuildStats reports
+// when StatsOptions.Top is zero or negative.
+const DefaultStatsTop = 10
+
+// StatsOptions controls BuildStats.
+type StatsOptions struct {
+	// Top is how many files MostChurned lists. Zero or less uses
+	// DefaultStatsTop.
+	Top int
+}
+
+// Churn is the activity of one group of revisions. Added and Removed are
+// the rlog line counts; the initial revision of a file has none. First and
+// Last are the earliest and latest revision dates in the group.
+type Churn struct {
+	Name      string `json:",omitempty"`
+	Revisions int
+	Added     int
+	Removed   int
+	First     time.Time
+	Last      time.Time
+}
+
+// Stats is the activity across a set of files, in total and grouped by
+// author, by month (as "2006-01") and by file. MostChurned lists the files
+// with the most lines added plus removed.
+type Stats struct {
+	Total       Churn
+	Authors     []*Churn
+	Months      []*Churn
+	Files       []*Churn
+	MostChurned []*Churn
+}
+
+func (c *Churn) add(date time.Time, added, removed int) {
+	c.Revisions++
+	c.Added += added
+	c.Removed += removed
+	if date.IsZero() {
+		return
+	}
+	if c.First.IsZero() || date.Before(c.First) {
+		c.First = date
+	}
+	if date.After(c.Last) {
+		c.Last = date
+	}
+}
+
+// BuildStats counts revisions and changed lines across files.
+func BuildStats(files []NamedFile, opts StatsOptions) *Stats {
+	if opts.Top <= 0 {
+		opts.Top = DefaultStatsTop
+	}
+	st := &Stats{}
+	authors := map[string]*Churn{}
+	months := map[string]*Churn{}
+	group := func(m map[string]*Churn, name string) *Churn {
+		c, ok := m[name]
+		if !ok {
+			c = &Churn{Name: name}
+			m[name] = c
+		}
+		return c
+	}
+	for _, nf := range files {
+		fc := &Churn{Name: nf.Name}
+		for _, rh := range nf.File.RevisionHeads {
+			added, removed, _ := nf.File.RevisionLineStats(rh)
+			date, err := rh.Date.DateTime()
+			if err != nil {
+				date = time.Time{}
+			}
+			date = date.UTC()
+			month := "unknown"
+			if !date.IsZero() {
+				month = date.Format("2006-01")
+			}
+			for _, c := range []*Churn{&st.Total, fc, group(authors, rh.Author.String()), group(months, month)} {
+				c.add(date, added, removed)
+			}
+		}
+		st.Files = append(st.Files, fc)
+	}
+	st.Authors = sortedChurn(authors)
+	st.Months = sortedChurn(months)
+	sort.SliceStable(st.Files, func(i, j int) bool { return st.Files[i].Name < st.Files[j].Name })
+
+	st.MostChurned = append([]*Churn(nil), st.Files...)
+	sort.SliceStable(st.MostChurned, func(i, j int) bool {
+		a, b := st.MostChurned[i], st.MostChurned[j]
+		return a.Added+a.Removed > b.Added+b.Removed
+	})
+	if len(st.MostChurned) > opts.Top {
+		st.MostChurned = st.MostChurned[:opts.Top]
+	}
+	return st
+}
+
+func sortedChurn(m map[string]*Churn) []*Churn {
+	list := make([]*Churn, 0, len(m))
+	for _, c := range m {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
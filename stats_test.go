@@ -0,0 +1,51 @@
+package rcs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildStats(t *testing.T) {
+	a := jsonTestFile()
+	a.RevisionHeads[0].Author = "bob"
+	a.RevisionHeads[1].Author = "alice"
+	a.RevisionHeads[2].Author = "alice"
+	b, err := ParseFile(strings.NewReader(annotateTestMaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := BuildStats([]NamedFile{{Name: "b", File: b}, {Name: "a", File: a}}, StatsOptions{Top: 1})
+
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.DateTime, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	mar3, mar4, mar5 := date("2021-03-03 05:06:07"), date("2021-03-04 05:06:07"), date("2021-03-05 05:06:07")
+	fileA := &Churn{Name: "a", Revisions: 3, Added: 3, Removed: 1, First: mar3, Last: mar5}
+	fileB := &Churn{Name: "b", Revisions: 2, Added: 2, Removed: 1, First: mar3, Last: mar4}
+	want := &Stats{
+		Total: Churn{Revisions: 5, Added: 5, Removed: 2, First: mar3, Last: mar5},
+		Authors: []*Churn{
+			{Name: "alice", Revisions: 3, Added: 2, First: mar3, Last: mar5},
+			{Name: "bob", Revisions: 2, Added: 3, Removed: 2, First: mar4, Last: mar4},
+		},
+		Months:      []*Churn{{Name: "2021-03", Revisions: 5, Added: 5, Removed: 2, First: mar3, Last: mar5}},
+		Files:       []*Churn{fileA, fileB},
+		MostChurned: []*Churn{fileA},
+	}
+	if diff := cmp.Diff(want, st); diff != "" {
+		t.Errorf("BuildStats() mismatch (-want +got):\n%s", diff)
+	}
+
+	st = BuildStats([]NamedFile{{Name: "b", File: b}, {Name: "a", File: a}}, StatsOptions{Top: -1})
+	if diff := cmp.Diff([]*Churn{fileA, fileB}, st.MostChurned); diff != "" {
+		t.Errorf("BuildStats(Top: -1) MostChurned mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,243 @@
+package svndump
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	rcs "github.com/arran4/golang-rcs"
+)
+
+// Options controls Export.
+type Options struct {
+	Changesets rcs.ChangesetOptions
+}
+
+const (
+	trunkDir    = "trunk"
+	branchesDir = "branches"
+	tagsDir     = "tags"
+)
+
+type location struct {
+	path string
+	rev  int
+	date time.Time
+}
+
+type symbolRef struct {
+	file     string
+	revision string
+}
+
+type exporter struct {
+	dw        *Writer
+	files     map[string]*rcs.File
+	dirs      map[string]bool
+	paths     map[string]bool
+	locations map[string]location
+	branches  map[string][]symbolRef
+	tags      map[string][]symbolRef
+	created   map[string]bool
+	lastDate  time.Time
+}
+
+// Export converts the given RCS files into a dump stream.
+//
+// Revision 1 creates the standard trunk, branches and tags directories. Every
+// changeset reconstructed by rcs.BuildChangesets becomes one revision carrying
+// svn:author, svn:date and svn:log. Trunk revisions are written under trunk/,
+// branch revisions under branches/<symbol>/. A branch is created by copying
+// the branch point of every file carrying the symbol just before its first
+// changeset. Tags are created after all changesets as copies under
+// tags/<symbol>/.
+func Export(w io.Writer, files []rcs.NamedFile, opts Options) error {
+	sets, err := rcs.BuildChangesets(files, opts.Changesets)
+	if err != nil {
+		return err
+	}
+	dw, err := NewWriter(w)
+	if err != nil {
+		return err
+	}
+	e := &exporter{
+		dw:        dw,
+		files:     map[string]*rcs.File{},
+		dirs:      map[string]bool{},
+		paths:     map[string]bool{},
+		locations: map[string]location{},
+		branches:  map[string][]symbolRef{},
+		tags:      map[string][]symbolRef{},
+		created:   map[string]bool{},
+	}
+	for _, nf := range files {
+		e.files[nf.Name] = nf.File
+		e.collectSymbols(nf)
+	}
+
+	var start time.Time
+	if len(sets) > 0 {
+		start = sets[0].Date
+	}
+	rev := &Revision{Props: RevisionProps("", "Standard project directories initialized by gorcs.", start)}
+	for _, d := range []string{trunkDir, branchesDir, tagsDir} {
+		e.addDir(rev, d)
+	}
+	if _, err := dw.WriteRevision(rev); err != nil {
+		return err
+	}
+
+	for _, cs := range sets {
+		if err := e.writeChangeset(cs); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(e.branches) {
+		if !e.created[name] {
+			if err := e.createBranch(name, e.lastDate); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(e.tags) {
+		if err := e.createTag(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) collectSymbols(nf rcs.NamedFile) {
+	for _, s := range nf.File.Symbols {
+		rev := rcs.NormalizeBranchSymbol(s.Revision)
+		ref := symbolRef{file: nf.Name, revision: rev}
+		if strings.Count(rev, ".")%2 == 0 {
+			e.branches[s.Name] = append(e.branches[s.Name], ref)
+		} else {
+			e.tags[s.Name] = append(e.tags[s.Name], ref)
+		}
+	}
+}
+
+func (e *exporter) writeChangeset(cs *rcs.Changeset) error {
+	root := trunkDir
+	if cs.Branch != rcs.TrunkBranchName {
+		root = path.Join(branchesDir, cs.Branch)
+		if !e.created[cs.Branch] {
+			if err := e.createBranch(cs.Branch, cs.Date); err != nil {
+				return err
+			}
+		}
+	}
+
+	rev := &Revision{Props: RevisionProps(cs.Author, cs.Log, cs.Date)}
+	number := e.dw.Revision() + 1
+	for _, m := range cs.Members {
+		p := path.Join(root, filepath.ToSlash(m.File))
+		if m.Dead() {
+			if e.paths[p] {
+				rev.Nodes = append(rev.Nodes, &Node{Path: p, Action: "delete"})
+				delete(e.paths, p)
+			}
+			continue
+		}
+		f := e.files[m.File]
+		verdict, err := f.Checkout("", rcs.WithRevision(m.Revision))
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.File, err)
+		}
+		text := verdict.Content
+		node := &Node{Path: p, Kind: "file", Action: "change", Text: &text}
+		if !e.paths[p] {
+			node.Action = "add"
+			e.addParents(rev, p)
+			if parent, ok := e.locations[locationKey(m.File, m.Parent)]; ok && m.Parent != "" {
+				node.CopyFromPath = parent.path
+				node.CopyFromRev = parent.rev
+			}
+			e.paths[p] = true
+		}
+		rev.Nodes = append(rev.Nodes, node)
+		e.locations[locationKey(m.File, m.Revision)] = location{path: p, rev: number, date: cs.Date}
+	}
+	if _, err := e.dw.WriteRevision(rev); err != nil {
+		return err
+	}
+	e.lastDate = cs.EndDate
+	return nil
+}
+
+func (e *exporter) createBranch(name string, date time.Time) error {
+	e.created[name] = true
+	root := path.Join(branchesDir, name)
+	rev := &Revision{Props: RevisionProps("", fmt.Sprintf("Create branch '%s'.", name), date)}
+	e.addDir(rev, root)
+	for _, ref := range e.branches[name] {
+		loc, ok := e.locations[locationKey(ref.file, rcs.BranchPoint(ref.revision))]
+		if !ok {
+			continue
+		}
+		p := path.Join(root, filepath.ToSlash(ref.file))
+		e.addParents(rev, p)
+		rev.Nodes = append(rev.Nodes, &Node{Path: p, Kind: "file", Action: "add", CopyFromPath: loc.path, CopyFromRev: loc.rev})
+		e.paths[p] = true
+	}
+	_, err := e.dw.WriteRevision(rev)
+	return err
+}
+
+func (e *exporter) createTag(name string) error {
+	root := path.Join(tagsDir, name)
+	rev := &Revision{}
+	e.addDir(rev, root)
+	var date time.Time
+	for _, ref := range e.tags[name] {
+		loc, ok := e.locations[locationKey(ref.file, ref.revision)]
+		if !ok {
+			continue
+		}
+		p := path.Join(root, filepath.ToSlash(ref.file))
+		e.addParents(rev, p)
+		rev.Nodes = append(rev.Nodes, &Node{Path: p, Kind: "file", Action: "add", CopyFromPath: loc.path, CopyFromRev: loc.rev})
+		if loc.date.After(date) {
+			date = loc.date
+		}
+	}
+	rev.Props = RevisionProps("", fmt.Sprintf("Create tag '%s'.", name), date)
+	_, err := e.dw.WriteRevision(rev)
+	return err
+}
+
+func (e *exporter) addDir(rev *Revision, dir string) {
+	e.addParents(rev, dir)
+	if e.dirs[dir] {
+		return
+	}
+	e.dirs[dir] = true
+	rev.Nodes = append(rev.Nodes, &Node{Path: dir, Kind: "dir", Action: "add"})
+}
+
+func (e *exporter) addParents(rev *Revision, p string) {
+	dir := path.Dir(p)
+	if dir == "." || dir == "/" || e.dirs[dir] {
+		return
+	}
+	e.addDir(rev, dir)
+}
+
+func locationKey(file, revision string) string {
+	return file + "\x00" + revision
+}
+
+func sortedKeys(m map[string][]symbolRef) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package svndump
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	rcs "github.com/arran4/golang-rcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+const exportTestMaster = `head	1.2;
+access;
+symbols
+	REL_1:1.2
+	fix:1.1.0.2;
+locks; strict;
+comment	@# @;
+
+
+1.2
+date	2021.03.04.05.06.07;	author alice;	state Exp;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches
+	1.1.2.1;
+next	;
+
+1.1.2.1
+date	2021.03.05.05.06.07;	author bob;	state Exp;
+branches;
+next	;
+
+
+desc
+@@
+
+
+1.2
+log
+@second
+@
+text
+@one
+two
+@
+
+
+1.1
+log
+@first
+@
+text
+@d2 1
+@
+
+
+1.1.2.1
+log
+@on branch
+@
+text
+@a1 1
+fix
+@
+`
+
+func TestExport(t *testing.T) {
+	f, err := rcs.ParseFile(strings.NewReader(exportTestMaster))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	want, err := os.ReadFile("testdata/export.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := Export(&got, []rcs.NamedFile{{Name: "dir/a.txt", File: f}}, Options{}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if diff := cmp.Diff(string(want), got.String()); diff != "" {
+		t.Errorf("Export() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriterDelete(t *testing.T) {
+	var got bytes.Buffer
+	dw, err := NewWriter(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := dw.WriteRevision(&Revision{Nodes: []*Node{{Path: "trunk/a", Action: "delete"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("WriteRevision() = %d, want 1", n)
+	}
+	if !strings.HasSuffix(got.String(), "Node-path: trunk/a\nNode-action: delete\n\n\n") {
+		t.Errorf("unexpected delete node:\n%s", got.String())
+	}
+}
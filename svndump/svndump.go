@@ -0,0 +1,141 @@
+// Package svndump writes Subversion dump streams (format version 2) as read
+// by `svnadmin load`, and converts sets of RCS files into such streams.
+package svndump
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FormatVersion is the dump format version written by Writer.
+const FormatVersion = 2
+
+// DateFormat is the layout Subversion uses for svn:date.
+const DateFormat = "2006-01-02T15:04:05.000000Z"
+
+// Prop is a single Subversion property.
+type Prop struct {
+	Key   string
+	Value string
+}
+
+// Node is a single change within a revision.
+type Node struct {
+	Path         string
+	Kind         string // "file" or "dir"
+	Action       string // "add", "change", "delete" or "replace"
+	CopyFromPath string
+	CopyFromRev  int
+	Props        []Prop
+	// Text is the full file contents. Nil means the node carries no text.
+	Text *string
+}
+
+// Revision is a single revision in the dump stream.
+type Revision struct {
+	Props []Prop
+	Nodes []*Node
+}
+
+// RevisionProps builds the standard svn:log, svn:author and svn:date
+// properties. An empty author is omitted.
+func RevisionProps(author, log string, date time.Time) []Prop {
+	props := []Prop{{Key: "svn:log", Value: log}}
+	if author != "" {
+		props = append(props, Prop{Key: "svn:author", Value: author})
+	}
+	props = append(props, Prop{Key: "svn:date", Value: date.UTC().Format(DateFormat)})
+	return props
+}
+
+// Writer writes a dump stream. Revision 0 is written by NewWriter.
+type Writer struct {
+	w   io.Writer
+	rev int
+}
+
+// NewWriter writes the dump header and the empty revision 0.
+func NewWriter(w io.Writer) (*Writer, error) {
+	dw := &Writer{w: w}
+	if _, err := fmt.Fprintf(w, "SVN-fs-dump-format-version: %d\n\n", FormatVersion); err != nil {
+		return nil, err
+	}
+	if err := dw.writeRevision(0, nil); err != nil {
+		return nil, err
+	}
+	return dw, nil
+}
+
+// Revision returns the number of the last revision written.
+func (dw *Writer) Revision() int {
+	return dw.rev
+}
+
+// WriteRevision writes r as the next revision and returns its number.
+func (dw *Writer) WriteRevision(r *Revision) (int, error) {
+	dw.rev++
+	if err := dw.writeRevision(dw.rev, r.Props); err != nil {
+		return 0, err
+	}
+	for _, n := range r.Nodes {
+		if err := dw.writeNode(n); err != nil {
+			return 0, err
+		}
+	}
+	return dw.rev, nil
+}
+
+func (dw *Writer) writeRevision(rev int, props []Prop) error {
+	p := encodeProps(props)
+	_, err := fmt.Fprintf(dw.w, "Revision-number: %d\nProp-content-length: %d\nContent-length: %d\n\n%s\n", rev, len(p), len(p), p)
+	return err
+}
+
+func (dw *Writer) writeNode(n *Node) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Node-path: %s\n", n.Path)
+	if n.Kind != "" {
+		fmt.Fprintf(&b, "Node-kind: %s\n", n.Kind)
+	}
+	fmt.Fprintf(&b, "Node-action: %s\n", n.Action)
+	if n.CopyFromPath != "" {
+		fmt.Fprintf(&b, "Node-copyfrom-rev: %d\nNode-copyfrom-path: %s\n", n.CopyFromRev, n.CopyFromPath)
+	}
+	if n.Action == "delete" {
+		b.WriteString("\n\n")
+		_, err := dw.w.Write(b.Bytes())
+		return err
+	}
+	var props []byte
+	if n.Props != nil || n.Action == "add" || n.Action == "replace" {
+		props = encodeProps(n.Props)
+		fmt.Fprintf(&b, "Prop-content-length: %d\n", len(props))
+	}
+	length := len(props)
+	if n.Text != nil {
+		sum := md5.Sum([]byte(*n.Text))
+		fmt.Fprintf(&b, "Text-content-length: %d\nText-content-md5: %s\n", len(*n.Text), hex.EncodeToString(sum[:]))
+		length += len(*n.Text)
+	}
+	fmt.Fprintf(&b, "Content-length: %d\n\n", length)
+	b.Write(props)
+	if n.Text != nil {
+		b.WriteString(*n.Text)
+	}
+	b.WriteString("\n\n")
+	_, err := dw.w.Write(b.Bytes())
+	return err
+}
+
+func encodeProps(props []Prop) []byte {
+	var b bytes.Buffer
+	for _, p := range props {
+		fmt.Fprintf(&b, "K %d\n%s\nV %d\n%s\n", len(p.Key), p.Key, len(p.Value), p.Value)
+	}
+	b.WriteString("PROPS-END\n")
+	return b.Bytes()
+}
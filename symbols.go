@@ -0,0 +1,131 @@
+package rcs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateSymbolName checks name against the RCS sym grammar:
+//
+//	sym ::= {digit}* idchar {idchar | digit}*
+//
+// That is, a non-empty run of visible characters other than the specials
+// "$,.:;@" that is not made of digits alone.
+func ValidateSymbolName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidSymbolName)
+	}
+	allDigits := true
+	for _, r := range name {
+		if !isIdChar(r) {
+			return fmt.Errorf("%w: %q contains %q", ErrInvalidSymbolName, name, r)
+		}
+		if !isDigit(r) {
+			allDigits = false
+		}
+	}
+	if allDigits {
+		return fmt.Errorf("%w: %q is a number", ErrInvalidSymbolName, name)
+	}
+	return nil
+}
+
+// validateSymbolRevision checks that revision names an existing revision, or
+// a branch (RCS or CVS magic form) whose branch point exists.
+func (f *File) validateSymbolRevision(revision string) error {
+	if revision == "" {
+		return fmt.Errorf("%w: empty revision", ErrRevisionNotFound)
+	}
+	for _, r := range revision {
+		if !isDigit(r) && r != '.' {
+			return fmt.Errorf("%w: %q is not a revision number", ErrRevisionNotFound, revision)
+		}
+	}
+	rev := NormalizeBranchSymbol(revision)
+	if strings.Count(rev, ".")%2 == 0 {
+		rev = BranchPoint(rev)
+		if rev == "" {
+			// Trunk branch numbers such as "1" are always valid.
+			return nil
+		}
+	}
+	for _, rh := range f.RevisionHeads {
+		if rh.Revision.String() == rev {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrRevisionNotFound, revision)
+}
+
+// SetSymbol binds name to revision. New symbols are added to the front of
+// the list like GNU RCS does. If name is already bound to another revision
+// ErrSymbolExists is returned unless force is set, in which case the symbol
+// is moved.
+func (f *File) SetSymbol(name, revision string, force bool) error {
+	if err := ValidateSymbolName(name); err != nil {
+		return err
+	}
+	if err := f.validateSymbolRevision(revision); err != nil {
+		return err
+	}
+	for _, s := range f.Symbols {
+		if s.Name != name {
+			continue
+		}
+		if s.Revision != revision && !force {
+			return fmt.Errorf("%w: %s is bound to %s", ErrSymbolExists, name, s.Revision)
+		}
+		s.Revision = revision
+		return nil
+	}
+	f.Symbols = append([]*Symbol{{Name: name, Revision: revision}}, f.Symbols...)
+	return nil
+}
+
+// DeleteSymbol removes name from the symbol list.
+func (f *File) DeleteSymbol(name string) error {
+	for i, s := range f.Symbols {
+		if s.Name == name {
+			f.Symbols = append(f.Symbols[:i:i], f.Symbols[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSymbolNotFound, name)
+}
+
+// RenameSymbol renames oldName to newName keeping its revision and position.
+// If newName already exists ErrSymbolExists is returned unless force is set,
+// in which case the existing newName is dropped.
+func (f *File) RenameSymbol(oldName, newName string, force bool) error {
+	if err := ValidateSymbolName(newName); err != nil {
+		return err
+	}
+	idx := -1
+	for i, s := range f.Symbols {
+		if s.Name == oldName {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	for i, s := range f.Symbols {
+		if s.Name != newName {
+			continue
+		}
+		if !force {
+			return fmt.Errorf("%w: %s is bound to %s", ErrSymbolExists, newName, s.Revision)
+		}
+		f.Symbols = append(f.Symbols[:i:i], f.Symbols[i+1:]...)
+		if i < idx {
+			idx--
+		}
+		break
+	}
+	f.Symbols[idx].Name = newName
+	return nil
+}
@@ -0,0 +1,83 @@
+package rcs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func symbolsTestFile() *File {
+	f := NewFile()
+	f.Head = "1.2"
+	f.Symbols = []*Symbol{{Name: "B", Revision: "1.1"}, {Name: "C", Revision: "1.2"}}
+	f.RevisionHeads = []*RevisionHead{
+		{Revision: "1.2", Date: "2020.01.02.00.00.00", NextRevision: "1.1"},
+		{Revision: "1.1", Date: "2020.01.01.00.00.00"},
+	}
+	return f
+}
+
+func TestValidateSymbolName(t *testing.T) {
+	for _, name := range []string{"REL_1", "v2", "1a", "a-b"} {
+		if err := ValidateSymbolName(name); err != nil {
+			t.Errorf("ValidateSymbolName(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "123", "a.b", "a:b", "a b", "a$", "a;b", "a@b", "a,b"} {
+		if err := ValidateSymbolName(name); !errors.Is(err, ErrInvalidSymbolName) {
+			t.Errorf("ValidateSymbolName(%q) error = %v, want ErrInvalidSymbolName", name, err)
+		}
+	}
+}
+
+func TestSetSymbol(t *testing.T) {
+	f := symbolsTestFile()
+	if err := f.SetSymbol("A", "1.2", false); err != nil {
+		t.Fatalf("SetSymbol() error = %v", err)
+	}
+	if err := f.SetSymbol("B", "1.2", false); !errors.Is(err, ErrSymbolExists) {
+		t.Fatalf("SetSymbol() error = %v, want ErrSymbolExists", err)
+	}
+	if err := f.SetSymbol("B", "1.1", false); err != nil {
+		t.Fatalf("SetSymbol() same revision error = %v", err)
+	}
+	if err := f.SetSymbol("B", "1.2", true); err != nil {
+		t.Fatalf("SetSymbol() force error = %v", err)
+	}
+	if err := f.SetSymbol("BR", "1.1.0.2", false); err != nil {
+		t.Fatalf("SetSymbol() magic branch error = %v", err)
+	}
+	if err := f.SetSymbol("X", "1.9", false); !errors.Is(err, ErrRevisionNotFound) {
+		t.Fatalf("SetSymbol() error = %v, want ErrRevisionNotFound", err)
+	}
+	want := []*Symbol{{Name: "BR", Revision: "1.1.0.2"}, {Name: "A", Revision: "1.2"}, {Name: "B", Revision: "1.2"}, {Name: "C", Revision: "1.2"}}
+	if diff := cmp.Diff(want, f.Symbols); diff != "" {
+		t.Errorf("Symbols mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDeleteAndRenameSymbol(t *testing.T) {
+	f := symbolsTestFile()
+	if err := f.RenameSymbol("C", "B", false); !errors.Is(err, ErrSymbolExists) {
+		t.Fatalf("RenameSymbol() error = %v, want ErrSymbolExists", err)
+	}
+	if err := f.RenameSymbol("C", "B", true); err != nil {
+		t.Fatalf("RenameSymbol() force error = %v", err)
+	}
+	if diff := cmp.Diff([]*Symbol{{Name: "B", Revision: "1.2"}}, f.Symbols); diff != "" {
+		t.Errorf("Symbols mismatch (-want +got):\n%s", diff)
+	}
+	if err := f.RenameSymbol("missing", "D", false); !errors.Is(err, ErrSymbolNotFound) {
+		t.Fatalf("RenameSymbol() error = %v, want ErrSymbolNotFound", err)
+	}
+	if err := f.DeleteSymbol("B"); err != nil {
+		t.Fatalf("DeleteSymbol() error = %v", err)
+	}
+	if err := f.DeleteSymbol("B"); !errors.Is(err, ErrSymbolNotFound) {
+		t.Fatalf("DeleteSymbol() error = %v, want ErrSymbolNotFound", err)
+	}
+	if len(f.Symbols) != 0 {
+		t.Errorf("Symbols = %v, want empty", f.Symbols)
+	}
+}
@@ -0,0 +1,295 @@
+package rcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// TolerantOptions controls ParseFileTolerant.
+type TolerantOptions struct {
+	// MaxDiagnostics stops recovery once this many problems have been
+	// recorded. Zero means no limit.
+	MaxDiagnostics int
+}
+
+// ErrSkippedText is the error of a diagnostic for text that could not be
+// attributed to any part of the file.
+var ErrSkippedText = errors.New("unrecognised text skipped")
+
+var (
+	deltaStartRe     = regexp.MustCompile(`(?m)^\d+(?:\.\d+)+\r?$`)
+	descStartRe      = regexp.MustCompile(`(?m)^desc\r?\n`)
+	deltaTextStartRe = regexp.MustCompile(`(?m)^\d+(?:\.\d+)+\r?\nlog\r?\n`)
+)
+
+// ParseFileTolerant parses r like ParseFile but keeps going after a
+// problem. A phrase that fails to parse is dropped and parsing resumes at
+// the next phrase, delta or deltatext, so one corrupt delta does not hide
+// the rest of the file. It returns everything it could recover along with a
+// ParseError for each problem, in file order. A file ParseFile accepts is
+// returned exactly as ParseFile returns it, with no diagnostics. The error
+// is only set when r cannot be read.
+func ParseFileTolerant(r io.Reader, opts TolerantOptions) (*File, []*ParseError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f, err := ParseFile(bytes.NewReader(data)); err == nil {
+		return f, nil, nil
+	}
+	t := &tolerantParser{data: data, max: opts.MaxDiagnostics}
+	return t.parse(), t.diags, nil
+}
+
+type tolerantParser struct {
+	data  []byte
+	max   int
+	diags []*ParseError
+}
+
+func (t *tolerantParser) full() bool {
+	return t.max > 0 && len(t.diags) >= t.max
+}
+
+// lineAt returns the 1-based line number of byte offset off.
+func (t *tolerantParser) lineAt(off int) int {
+	return bytes.Count(t.data[:off], []byte("\n")) + 1
+}
+
+// lineStart returns the offset of the start of the line holding off.
+func (t *tolerantParser) lineStart(off int) int {
+	return bytes.LastIndexByte(t.data[:off], '\n') + 1
+}
+
+// scanner returns a scanner over r, which holds the data from offset from
+// on, with positions counted from the start of the file.
+func (t *tolerantParser) scanner(r io.Reader, from int, section string) *Scanner {
+	s := NewScanner(r)
+	s.pos.Line = t.lineAt(from)
+	s.pos.Offset = from - t.lineStart(from)
+	s.offset = int64(from)
+	s.phrase = parsePhrase{section: section}
+	return s
+}
+
+func (t *tolerantParser) report(pe *ParseError) {
+	if !t.full() {
+		t.diags = append(t.diags, pe)
+	}
+}
+
+// skipped records text at [from, to) that was not parsed, if it is more
+// than whitespace.
+func (t *tolerantParser) skipped(from, to int, section string) {
+	text := t.data[from:to]
+	trimmed := bytes.TrimLeft(text, " \t\r\n")
+	if len(trimmed) == 0 {
+		return
+	}
+	off := from + len(text) - len(trimmed)
+	line := trimmed
+	if nl := bytes.IndexByte(line, '\n'); nl >= 0 {
+		line = line[:nl]
+	}
+	t.report(&ParseError{
+		Line:    t.lineAt(off),
+		Column:  off - t.lineStart(off) + 1,
+		Offset:  int64(off),
+		Section: section,
+		Source:  string(bytes.TrimSuffix(line, []byte("\r"))),
+		Err:     ErrSkippedText,
+	})
+}
+
+// unparsed returns an error naming rest, the text a parse stopped at
+// without complaint, or nil if it is only whitespace.
+func unparsed(rest []byte) error {
+	rest = bytes.TrimLeft(rest, " \t\r\n")
+	if len(rest) == 0 {
+		return nil
+	}
+	if nl := bytes.IndexByte(rest, '\n'); nl >= 0 {
+		rest = rest[:nl]
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownToken, bytes.TrimSuffix(rest, []byte("\r")))
+}
+
+func (t *tolerantParser) parse() *File {
+	data := t.data
+	adminEnd := len(data)
+	for _, sep := range []string{"\n\n", "\r\n\r\n"} {
+		if i := bytes.Index(data, []byte(sep)); i >= 0 && i+len(sep) < adminEnd {
+			adminEnd = i + len(sep)
+		}
+	}
+	f := new(File)
+	t.recoverChunk(0, adminEnd, "admin", []byte("head\t;\n"), func(s *Scanner) error {
+		f = new(File)
+		return ParseHeader(s, f)
+	})
+
+	deltaEnd, contentsStart := len(data), len(data)
+	descStart := -1
+	if loc := descStartRe.FindIndex(data[adminEnd:]); loc != nil {
+		descStart = adminEnd + loc[0]
+		deltaEnd = descStart
+	} else if loc := deltaTextStartRe.FindIndex(data[adminEnd:]); loc != nil {
+		deltaEnd, contentsStart = adminEnd+loc[0], adminEnd+loc[0]
+	}
+
+	starts := deltaStartRe.FindAllIndex(data[adminEnd:deltaEnd], -1)
+	if len(starts) == 0 {
+		t.skipped(adminEnd, deltaEnd, "delta")
+	}
+	for i, loc := range starts {
+		if t.full() {
+			return f
+		}
+		from, to := adminEnd+loc[0], deltaEnd
+		if i == 0 {
+			t.skipped(adminEnd, from, "delta")
+		}
+		if i+1 < len(starts) {
+			to = adminEnd + starts[i+1][0]
+		}
+		var rh *RevisionHead
+		t.recoverChunk(from, to, "delta", nil, func(s *Scanner) error {
+			var err error
+			rh, _, _, _, _, err = parseRevisionHeaderWithOffset(s)
+			return err
+		})
+		if rh != nil {
+			f.RevisionHeads = append(f.RevisionHeads, rh)
+		}
+	}
+
+	if descStart >= 0 && !t.full() {
+		s := t.scanner(bytes.NewReader(data[descStart:]), descStart, "desc")
+		desc, err := ParseDescription(s, false)
+		if err != nil {
+			t.report(s.parseError(err))
+			contentsStart = len(data)
+			if loc := deltaTextStartRe.FindIndex(data[descStart:]); loc != nil {
+				contentsStart = descStart + loc[0]
+			}
+		} else {
+			f.Description = desc
+			contentsStart = int(s.offset)
+		}
+	}
+
+	for p := contentsStart; p < len(data) && !t.full(); {
+		if unparsed(data[p:]) == nil {
+			// Only trailing whitespace is left.
+			break
+		}
+		s := t.scanner(bytes.NewReader(data[p:]), p, "deltatext")
+		rc, _, err := ParseRevisionContent(s)
+		if err == nil && rc == nil {
+			t.skipped(p, len(data), "deltatext")
+			break
+		}
+		if err == nil {
+			if next := bytes.TrimLeft(data[s.offset:], "\r\n"); len(next) > 0 {
+				if loc := deltaStartRe.FindIndex(next); loc == nil || loc[0] != 0 {
+					err = unparsed(next)
+				}
+			}
+		}
+		if err == nil {
+			rc.PrecedingNewLinesOffset = 0
+			f.RevisionContents = append(f.RevisionContents, rc)
+			if int(s.offset) == p {
+				break
+			}
+			p = int(s.offset)
+			continue
+		}
+		t.report(s.parseError(err))
+		// Resume after the revision line of the deltatext that failed.
+		rev := p + len(data[p:]) - len(bytes.TrimLeft(data[p:], "\r\n"))
+		p = len(data)
+		if rev >= len(data) {
+			break
+		}
+		if loc := deltaTextStartRe.FindIndex(data[rev+1:]); loc != nil {
+			p = rev + 1 + loc[0]
+		}
+	}
+	clearParsedSpacing(f)
+	return f
+}
+
+// recoverChunk runs parse over data[from:to]. While it fails the error is
+// reported and the phrase it failed in, the failing line plus its indented
+// continuation lines, is cut out before trying again. A failure on the
+// first line replaces it with first, or gives up when first is nil. A
+// failure at the end of the chunk, such as a truncated file, keeps whatever
+// parse had read. Positions in the messages of errors after a cut are off by
+// the lines cut.
+func (t *tolerantParser) recoverChunk(from, to int, section string, first []byte, parse func(s *Scanner) error) {
+	type line struct {
+		text []byte
+		off  int
+	}
+	var lines []line
+	for off := from; off < to; {
+		end := to
+		if nl := bytes.IndexByte(t.data[off:to], '\n'); nl >= 0 {
+			end = off + nl + 1
+		}
+		lines = append(lines, line{t.data[off:end], off})
+		off = end
+	}
+	for attempts := len(lines) + 1; attempts > 0 && len(lines) > 0; attempts-- {
+		var buf bytes.Buffer
+		for _, l := range lines {
+			buf.Write(l.text)
+		}
+		text := bytes.Clone(buf.Bytes())
+		s := t.scanner(&buf, from, section)
+		err := parse(s)
+		if err == nil {
+			if err = unparsed(text[int(s.offset)-from:]); err == nil {
+				return
+			}
+		}
+		pe := s.parseError(err)
+		if int(s.offset)-from >= len(text) {
+			pe.Line, pe.Column, pe.Offset = t.lineAt(to), to-t.lineStart(to)+1, int64(to)
+			t.report(pe)
+			return
+		}
+		i := min(max(pe.Line-t.lineAt(from), 0), len(lines)-1)
+		pe.Line = t.lineAt(lines[i].off)
+		pe.Offset = int64(lines[i].off + pe.Column - 1)
+		t.report(pe)
+		if t.full() {
+			return
+		}
+
+		start, end := i, i+1
+		for start > 0 && isContinuationLine(lines[start].text) {
+			start--
+		}
+		for end < len(lines) && isContinuationLine(lines[end].text) {
+			end++
+		}
+		if start == 0 {
+			if first == nil || bytes.Equal(lines[0].text, first) {
+				return
+			}
+			lines = append([]line{{first, lines[0].off}}, lines[end:]...)
+			continue
+		}
+		lines = append(lines[:start], lines[end:]...)
+	}
+}
+
+// isContinuationLine reports whether a line carries on the phrase above it.
+func isContinuationLine(b []byte) bool {
+	return len(b) > 0 && (b[0] == ' ' || b[0] == '\t')
+}
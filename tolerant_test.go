@@ -0,0 +1,191 @@
+package rcs
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/txtar"
+)
+
+const tolerantTestMaster = `head	1.3;
+access;
+symbols
+	rel:1.2;
+locks; strict;
+comment	@# @;
+
+
+1.3
+date	2021.03.03.05.06.09;	author alice;	state Exp;
+branches;
+next	1.2;
+
+1.2
+date	2021.03.03.05.06.08;	author bob;	state;
+branches;
+next	1.1;
+
+1.1
+date	2021.03.03.05.06.07;	author alice;	state Exp;
+branches;
+next	;
+
+
+desc
+@about
+@
+
+
+1.3
+log
+@third
+@
+text
+@c
+@
+
+
+1.2
+lgo
+@second
+@
+text
+@d1 1
+a1 1
+b
+@
+
+
+1.1
+log
+@first
+@
+text
+@d1 1
+a1 1
+a
+@
+`
+
+func TestParseFileTolerant(t *testing.T) {
+	f, diags, err := ParseFileTolerant(strings.NewReader(tolerantTestMaster), TolerantOptions{})
+	if err != nil {
+		t.Fatalf("ParseFileTolerant() error = %v", err)
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Phrase())
+	}
+	want := []string{`delta 1.2 "date"`, `deltatext 1.2`}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+	}
+	if d := diags[0]; d.Line != 15 || d.Source != "date\t2021.03.03.05.06.08;\tauthor bob;\tstate;" {
+		t.Errorf("delta diagnostic at %d %q", d.Line, d.Source)
+	}
+	if d := diags[1]; d.Line != 40 || d.Offset != int64(strings.Index(tolerantTestMaster, "lgo")) {
+		t.Errorf("deltatext diagnostic at line %d offset %d", d.Line, d.Offset)
+	}
+
+	if f.Head != "1.3" || len(f.Symbols) != 1 || f.Description != "about\n" {
+		t.Errorf("admin not recovered: head %q symbols %v desc %q", f.Head, f.Symbols, f.Description)
+	}
+	var revs []string
+	for _, rh := range f.RevisionHeads {
+		revs = append(revs, rh.Revision.String()+" "+rh.NextRevision.String())
+	}
+	if diff := cmp.Diff([]string{"1.3 1.2", "1.2 1.1", "1.1 "}, revs); diff != "" {
+		t.Errorf("deltas mismatch (-want +got):\n%s", diff)
+	}
+	var texts []string
+	for _, rc := range f.RevisionContents {
+		texts = append(texts, rc.Revision+" "+rc.Log)
+	}
+	if diff := cmp.Diff([]string{"1.3 third\n", "1.1 first\n"}, texts); diff != "" {
+		t.Errorf("deltatexts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseFileTolerant_Valid(t *testing.T) {
+	f, diags, err := ParseFileTolerant(strings.NewReader(headerPhrasesTestMaster), TolerantOptions{})
+	if err != nil || len(diags) != 0 {
+		t.Fatalf("ParseFileTolerant() = %v, %v", diags, err)
+	}
+	if got := f.String(); got != headerPhrasesTestMaster {
+		t.Errorf("String() mismatch:\n%s", cmp.Diff(headerPhrasesTestMaster, got))
+	}
+}
+
+func TestParseFileTolerant_Truncated(t *testing.T) {
+	input := "head\t1.1;\naccess;\nsymbols\tfoo:1.1 bar;\nlocks; strict;\n"
+	f, diags, err := ParseFileTolerant(strings.NewReader(input), TolerantOptions{MaxDiagnostics: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Field != "symbols" {
+		t.Fatalf("diagnostics = %v, want the symbols error only", diags)
+	}
+	if f.Head != "1.1" || !f.Access {
+		t.Errorf("admin not recovered: %+v", f)
+	}
+
+	f, diags, _ = ParseFileTolerant(strings.NewReader(input+"\n\ngarbage\n"), TolerantOptions{})
+	if len(diags) != 2 || !errors.Is(diags[1], ErrSkippedText) || diags[1].Line != 7 {
+		t.Errorf("diagnostics = %v, want symbols error then skipped text at line 7", diags)
+	}
+	if !f.Strict || len(f.Locks) != 0 {
+		t.Errorf("locks not recovered: %+v", f)
+	}
+}
+
+func TestParseFileTolerant_TrailingNewlines(t *testing.T) {
+	input := headerPhrasesTestMaster + strings.Repeat("\n", 8)
+	f, diags, err := ParseFileTolerant(strings.NewReader(input), TolerantOptions{})
+	if err != nil {
+		t.Fatalf("ParseFileTolerant() error = %v", err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("diagnostics = %v, want none for trailing newlines", diags)
+	}
+	if f.Head != "1.1" || len(f.RevisionContents) != 1 {
+		t.Errorf("file not recovered: head %q, %d deltatexts", f.Head, len(f.RevisionContents))
+	}
+}
+
+func FuzzParseFileTolerant(f *testing.F) {
+	err := fs.WalkDir(txtarTests, "testdata/txtar", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".txtar") {
+			return err
+		}
+		b, err := fs.ReadFile(txtarTests, path)
+		if err != nil {
+			return err
+		}
+		for _, file := range txtar.Parse(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))).Files {
+			if strings.HasSuffix(file.Name, ",v") || strings.HasSuffix(file.Name, ".rcs") {
+				f.Add(file.Data)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add([]byte(tolerantTestMaster + strings.Repeat("\n", 8)))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file, diags, err := ParseFileTolerant(bytes.NewReader(data), TolerantOptions{})
+		if err != nil {
+			t.Fatalf("ParseFileTolerant() error = %v", err)
+		}
+		for _, d := range diags {
+			if d.Offset < 0 || d.Offset > int64(len(data)) {
+				t.Errorf("diagnostic offset %d outside the %d byte input: %v", d.Offset, len(data), d)
+			}
+		}
+		_ = file.String()
+	})
+}
@@ -652,7 +652,17 @@ func testRCS(t *testing.T, parts map[string]string, _ map[string]bool, args []st
 		}
 
 		branchName := ""
+		var symbolArgs []string
+		var accessArgs []string
 		for i := 0; i < len(args); i++ {
+			if strings.HasPrefix(args[i], "-n") || strings.HasPrefix(args[i], "-N") {
+				symbolArgs = append(symbolArgs, args[i])
+				continue
+			}
+			if strings.HasPrefix(args[i], "-a") || strings.HasPrefix(args[i], "-e") {
+				accessArgs = append(accessArgs, args[i])
+				continue
+			}
 			if strings.HasPrefix(args[i], "-b") {
 				branchName = strings.TrimPrefix(args[i], "-b")
 				break
@@ -662,7 +672,7 @@ func testRCS(t *testing.T, parts map[string]string, _ map[string]bool, args []st
 				break
 			}
 		}
-		if branchName == "" {
+		if branchName == "" && len(symbolArgs) == 0 && len(accessArgs) == 0 {
 			t.Skip("unsupported rcs operation fixture")
 		}
 
@@ -671,11 +681,43 @@ func testRCS(t *testing.T, parts map[string]string, _ map[string]bool, args []st
 			t.Fatalf("ParseFile error: %v", err)
 		}
 
-		parts := strings.Split(branchName, ".")
-		if len(parts)%2 == 0 && len(parts) > 0 {
-			branchName = strings.Join(parts[:len(parts)-1], ".")
+		for _, arg := range symbolArgs {
+			name, rev, hasRev := strings.Cut(arg[2:], ":")
+			if !hasRev {
+				err = parsed.DeleteSymbol(name)
+			} else {
+				if rev == "" {
+					rev = parsed.Head
+				}
+				err = parsed.SetSymbol(name, rev, arg[1] == 'N')
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", arg, err)
+			}
+		}
+
+		for _, arg := range accessArgs {
+			var users []string
+			if arg[2:] != "" {
+				users = strings.Split(arg[2:], ",")
+			}
+			switch {
+			case arg[1] == 'a':
+				parsed.AddAccessUsers(users...)
+			case len(users) == 0:
+				parsed.ClearAccessUsers()
+			default:
+				parsed.RemoveAccessUsers(users...)
+			}
+		}
+
+		if branchName != "" {
+			parts := strings.Split(branchName, ".")
+			if len(parts)%2 == 0 && len(parts) > 0 {
+				branchName = strings.Join(parts[:len(parts)-1], ".")
+			}
+			parsed.Branch = branchName
 		}
-		parsed.Branch = branchName
 
 		if diff := cmp.Diff(strings.TrimSpace(expectedRCS), strings.TrimSpace(parsed.String())); diff != "" {
 			t.Fatalf("RCS file mismatch (-want +got):\n%s", diff)